@@ -19,15 +19,22 @@ import (
 	"fmt"
 	"go/doc"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto"
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	v1beta1pb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/watch"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/writes"
 	"github.com/golang/protobuf/proto"
 	tspb "github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/golang/protobuf/ptypes/wrappers"
-	fspb "google.golang.org/genproto/googleapis/firestore/v1beta1"
+	"google.golang.org/genproto/googleapis/type/latlng"
+	"google.golang.org/grpc/codes"
 )
 
 const (
@@ -37,7 +44,10 @@ const (
 	watchTargetID = 1
 )
 
-var outputDir = flag.String("o", "", "directory to write test files")
+var (
+	outputDir = flag.String("o", "", "directory to write test files")
+	api       = flag.String("api", "v1", "Firestore API of the tests to write: v1 or v1beta1")
+)
 
 var (
 	updateTimePrecondition = &fspb.Precondition{
@@ -48,6 +58,16 @@ var (
 		ConditionType: &fspb.Precondition_Exists{true},
 	}
 
+	existsFalsePrecondition = &fspb.Precondition{
+		ConditionType: &fspb.Precondition_Exists{false},
+	}
+
+	// values and their encodings in test JSON
+	testTime         = time.Date(2016, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	testTimeJSON     = `{"$timestamp": "2016-01-02T03:04:05.123456789Z"}`
+	testGeoPoint     = &latlng.LatLng{Latitude: 37.5, Longitude: -122.25}
+	testGeoPointJSON = `{"$geopoint": {"latitude": 37.5, "longitude": -122.25}}`
+
 	nTests int
 )
 
@@ -63,11 +83,11 @@ type writeTest struct {
 	opt              *tpb.SetOption     // option for Set
 	precond          *fspb.Precondition // precondition for Update
 
-	outData       map[string]*fspb.Value // expected data in update write
-	mask          []string               // expected fields in update mask
-	maskForUpdate []string               // mask, but only for Update/UpdatePaths
-	transform     []string               // expected fields in transform
-	isErr         bool                   // arguments result in a client-side error
+	outData       map[string]*fspb.Value                   // expected data in update write
+	mask          []string                                 // expected fields in update mask
+	maskForUpdate []string                                 // mask, but only for Update/UpdatePaths
+	transform     []*fspb.DocumentTransform_FieldTransform // expected field transforms
+	isErr         bool                                     // arguments result in a client-side error
 }
 
 var (
@@ -97,6 +117,114 @@ var (
 		},
 	}
 
+	// tests of how each kind of value is encoded
+	valueTests = []writeTest{
+		{
+			suffix:        "value-null",
+			desc:          "null value",
+			comment:       `JSON null is a null value.`,
+			inData:        `{"a": null}`,
+			paths:         [][]string{{"a"}},
+			values:        []string{`null`},
+			maskForUpdate: []string{"a"},
+			outData:       mp("a", nil),
+		},
+		{
+			suffix:        "value-timestamp",
+			desc:          "timestamp value",
+			comment:       `A timestamp value keeps its full nanosecond precision.`,
+			inData:        `{"a": ` + testTimeJSON + `}`,
+			paths:         [][]string{{"a"}},
+			values:        []string{testTimeJSON},
+			maskForUpdate: []string{"a"},
+			outData:       mp("a", testTime),
+		},
+		{
+			suffix:        "value-bytes",
+			desc:          "bytes values",
+			comment:       `Bytes values, including an empty one.`,
+			inData:        `{"a": {"$bytes": "AQID"}, "b": {"$bytes": ""}}`,
+			paths:         [][]string{{"a"}, {"b"}},
+			values:        []string{`{"$bytes": "AQID"}`, `{"$bytes": ""}`},
+			maskForUpdate: []string{"a", "b"},
+			outData:       mp("a", []byte{1, 2, 3}, "b", []byte{}),
+		},
+		{
+			suffix:        "value-geopoint",
+			desc:          "geo point value",
+			comment:       `A geo point value.`,
+			inData:        `{"a": ` + testGeoPointJSON + `}`,
+			paths:         [][]string{{"a"}},
+			values:        []string{testGeoPointJSON},
+			maskForUpdate: []string{"a"},
+			outData:       mp("a", testGeoPoint),
+		},
+		{
+			suffix:        "value-reference",
+			desc:          "reference value",
+			comment:       `A reference to a document is encoded with the document's full path.`,
+			inData:        `{"a": {"$reference": "` + collPath + `/d2"}}`,
+			paths:         [][]string{{"a"}},
+			values:        []string{`{"$reference": "` + collPath + `/d2"}`},
+			maskForUpdate: []string{"a"},
+			outData:       mp("a", refval(collPath+"/d2")),
+		},
+		{
+			suffix: "value-int",
+			desc:   "integer values",
+			comment: `Integers are encoded as integer values over the whole 64-bit range, without loss
+of precision. 9007199254740993 is 2^53 + 1, which a double cannot represent.`,
+			inData:        `{"a": 9223372036854775807, "b": -9223372036854775808, "c": 9007199254740993, "d": -0}`,
+			paths:         [][]string{{"a"}, {"b"}, {"c"}, {"d"}},
+			values:        []string{`9223372036854775807`, `-9223372036854775808`, `9007199254740993`, `-0`},
+			maskForUpdate: []string{"a", "b", "c", "d"},
+			outData: mp(
+				"a", int64(math.MaxInt64),
+				"b", int64(math.MinInt64),
+				"c", int64(9007199254740993),
+				"d", 0,
+			),
+		},
+		{
+			suffix:        "value-double",
+			desc:          "double values",
+			comment:       `A number with a fraction or an exponent is a double, even if its value is integral.`,
+			inData:        `{"a": 1.0, "b": 1e3, "c": -0.5, "d": 1.7976931348623157e308}`,
+			paths:         [][]string{{"a"}, {"b"}, {"c"}, {"d"}},
+			values:        []string{`1.0`, `1e3`, `-0.5`, `1.7976931348623157e308`},
+			maskForUpdate: []string{"a", "b", "c", "d"},
+			outData:       mp("a", 1.0, "b", 1000.0, "c", -0.5, "d", math.MaxFloat64),
+		},
+		{
+			suffix: "value-unicode",
+			desc:   "Unicode strings",
+			comment: `Strings and map keys may hold any Unicode characters, including ones outside
+the Basic Multilingual Plane and the NUL character.`,
+			inData:        `{"é": "日本語", "b": "😀 é a\u0000b"}`,
+			paths:         [][]string{{"é"}, {"b"}},
+			values:        []string{`"日本語"`, `"😀 é a\u0000b"`},
+			maskForUpdate: []string{"`é`", "b"},
+			outData:       mp("é", "日本語", "b", "😀 é a\x00b"),
+		},
+		{
+			suffix:  "value-nested",
+			desc:    "values nested in maps and arrays",
+			comment: `Values of every kind may appear inside maps and arrays.`,
+			inData: `{"a": [` + testTimeJSON + `, {"$bytes": "AQID"}, null, 1.0],
+				"b": {"c": ` + testGeoPointJSON + `, "d": {"$reference": "` + collPath + `/d2"}}}`,
+			paths: [][]string{{"a"}, {"b"}},
+			values: []string{
+				`[` + testTimeJSON + `, {"$bytes": "AQID"}, null, 1.0]`,
+				`{"c": ` + testGeoPointJSON + `, "d": {"$reference": "` + collPath + `/d2"}}`,
+			},
+			maskForUpdate: []string{"a", "b"},
+			outData: mp(
+				"a", []interface{}{testTime, []byte{1, 2, 3}, nil, 1.0},
+				"b", mp("c", testGeoPoint, "d", refval(collPath+"/d2")),
+			),
+		},
+	}
+
 	// tests for Create and Set
 	createSetTests = []writeTest{
 		{
@@ -230,14 +358,14 @@ operation has no map, just an update mask.`,
 		{
 			suffix: "st-alone",
 			desc:   "ServerTimestamp alone",
-			comment: `If the only values in the input are ServerTimestamps, then no
-update operation should be produced.`,
+			comment: `If the only values in the input are ServerTimestamps, then the
+write only applies the transforms, and writes no fields.`,
 			inData:        `{"a": "ServerTimestamp"}`,
 			paths:         [][]string{{"a"}},
 			values:        []string{`"ServerTimestamp"`},
 			outData:       nil,
 			maskForUpdate: nil,
-			transform:     []string{"a"},
+			transform:     transforms(st("a")),
 		},
 	}
 
@@ -246,7 +374,7 @@ update operation should be produced.`,
 			suffix: "st",
 			desc:   "ServerTimestamp with data",
 			comment: `A key with the special ServerTimestamp sentinel is removed from
-the data in the update operation. Instead it appears in a separate Transform operation.
+the data in the update operation. Instead it becomes a field transform of the write.
 Note that in these tests, the string "ServerTimestamp" should be replaced with the
 special ServerTimestamp value.`,
 			inData:        `{"a": 1, "b": "ServerTimestamp"}`,
@@ -254,7 +382,7 @@ special ServerTimestamp value.`,
 			values:        []string{`1`, `"ServerTimestamp"`},
 			outData:       mp("a", 1),
 			maskForUpdate: []string{"a"},
-			transform:     []string{"b"},
+			transform:     transforms(st("b")),
 		},
 		{
 			suffix: "st-nested",
@@ -267,7 +395,7 @@ the transform applies to the field path "b.c". Since "c" is removed from the upd
 			values:        []string{`1`, `{"c": "ServerTimestamp"}`},
 			outData:       mp("a", 1),
 			maskForUpdate: []string{"a", "b"},
-			transform:     []string{"b.c"},
+			transform:     transforms(st("b.c")),
 		},
 		{
 			suffix: "st-multi",
@@ -282,7 +410,7 @@ timestamp, but the update will delete the rest of c.`,
 			values:        []string{`1`, `"ServerTimestamp"`, `{"d": "ServerTimestamp"}`},
 			outData:       mp("a", 1),
 			maskForUpdate: []string{"a", "c"},
-			transform:     []string{"b", "c.d"},
+			transform:     transforms(st("b"), st("c.d")),
 		},
 	}
 
@@ -333,21 +461,324 @@ array indexing.`,
 	}
 )
 
+// The array transforms, with the functions that build their expected field
+// transforms.
+var arrayTransforms = []struct {
+	name      string // the name of the sentinel in JSON
+	transform func(path string, elems ...interface{}) *fspb.DocumentTransform_FieldTransform
+}{
+	{"ArrayUnion", arrayUnion},
+	{"ArrayRemove", arrayRemove},
+}
+
+// arrayTransformTests returns the tests of the ArrayUnion and ArrayRemove
+// sentinels that apply to Create, Set, Update and UpdatePaths.
+func arrayTransformTests() []writeTest {
+	var tests []writeTest
+	for _, at := range arrayTransforms {
+		name, tf := at.name, at.transform
+		lname := strings.ToLower(name)
+		tests = append(tests, []writeTest{
+			{
+				suffix: lname,
+				desc:   name + " with data",
+				comment: fmt.Sprintf(`A key with %[1]s is removed from the data in the update
+operation. Instead it becomes a field transform of the write. In these tests, a JSON
+array whose first element is the string %[1]q should be replaced with the special %[1]s
+value, whose elements are the remaining elements of the array.`, name),
+				inData:        fmt.Sprintf(`{"a": 1, "b": ["%s", 1, 2, 3]}`, name),
+				paths:         [][]string{{"a"}, {"b"}},
+				values:        []string{`1`, fmt.Sprintf(`["%s", 1, 2, 3]`, name)},
+				outData:       mp("a", 1),
+				maskForUpdate: []string{"a"},
+				transform:     transforms(tf("b", 1, 2, 3)),
+			},
+			{
+				suffix: lname + "-nested",
+				desc:   "nested " + name + " field",
+				comment: fmt.Sprintf(`An %s value can occur at any depth. In this case,
+the transform applies to the field path "b.c". Since "c" is removed from the update,
+"b" becomes empty, so it is also removed from the update.`, name),
+				inData:        fmt.Sprintf(`{"a": 1, "b": {"c": ["%s", 1, 2, 3]}}`, name),
+				paths:         [][]string{{"a"}, {"b"}},
+				values:        []string{`1`, fmt.Sprintf(`{"c": ["%s", 1, 2, 3]}`, name)},
+				outData:       mp("a", 1),
+				maskForUpdate: []string{"a", "b"},
+				transform:     transforms(tf("b.c", 1, 2, 3)),
+			},
+			{
+				suffix: lname + "-multi",
+				desc:   "multiple " + name + " fields",
+				comment: fmt.Sprintf(`A document can have more than one %s field.
+Since all the %s fields are removed, the only field in the update is "a".`, name, name),
+				commentForUpdate: `b is not in the mask because it will be set in the transform.
+c must be in the mask: it should be replaced entirely. The transform will change c.d,
+but the update will delete the rest of c.`,
+				inData: fmt.Sprintf(`{"a": 1, "b": ["%[1]s", 1, 2, 3], "c": {"d": ["%[1]s", 4, 5, 6]}}`, name),
+				paths:  [][]string{{"a"}, {"b"}, {"c"}},
+				values: []string{
+					`1`,
+					fmt.Sprintf(`["%s", 1, 2, 3]`, name),
+					fmt.Sprintf(`{"d": ["%s", 4, 5, 6]}`, name),
+				},
+				outData:       mp("a", 1),
+				maskForUpdate: []string{"a", "c"},
+				transform:     transforms(tf("b", 1, 2, 3), tf("c.d", 4, 5, 6)),
+			},
+			{
+				suffix:        lname + "-with-st",
+				desc:          name + " and ServerTimestamp in the same call",
+				comment:       fmt.Sprintf(`%s and ServerTimestamp can be used together, on different fields.`, name),
+				inData:        fmt.Sprintf(`{"a": 1, "b": "ServerTimestamp", "c": ["%s", 1, 2]}`, name),
+				paths:         [][]string{{"a"}, {"b"}, {"c"}},
+				values:        []string{`1`, `"ServerTimestamp"`, fmt.Sprintf(`["%s", 1, 2]`, name)},
+				outData:       mp("a", 1),
+				maskForUpdate: []string{"a"},
+				transform:     transforms(st("b"), tf("c", 1, 2)),
+			},
+			// Errors
+			{
+				suffix: lname + "-noarray",
+				desc:   name + " cannot be in an array value",
+				comment: fmt.Sprintf(`%s must be the value of a field. Firestore
+transforms don't support array indexing.`, name),
+				inData: fmt.Sprintf(`{"a": [1, 2, ["%s", 1, 2, 3]]}`, name),
+				paths:  [][]string{{"a"}},
+				values: []string{fmt.Sprintf(`[1, 2, ["%s", 1, 2, 3]]`, name)},
+				isErr:  true,
+			},
+			{
+				suffix: lname + "-noarray-nested",
+				desc:   name + " cannot be anywhere inside an array value",
+				comment: fmt.Sprintf(`There cannot be an array value anywhere on the path from the document
+root to the %s. Firestore transforms don't support array indexing.`, name),
+				inData: fmt.Sprintf(`{"a": [1, {"b": ["%s", 1, 2, 3]}]}`, name),
+				paths:  [][]string{{"a"}},
+				values: []string{fmt.Sprintf(`[1, {"b": ["%s", 1, 2, 3]}]`, name)},
+				isErr:  true,
+			},
+			{
+				suffix:  lname + "-with-st-element",
+				desc:    "The elements of " + name + " cannot contain ServerTimestamp",
+				comment: fmt.Sprintf(`The ServerTimestamp sentinel must be the value of a field. It may not appear in an %s.`, name),
+				inData:  fmt.Sprintf(`{"a": ["%s", 1, "ServerTimestamp", 3]}`, name),
+				paths:   [][]string{{"a"}},
+				values:  []string{fmt.Sprintf(`["%s", 1, "ServerTimestamp", 3]`, name)},
+				isErr:   true,
+			},
+			{
+				suffix:  lname + "-with-del-element",
+				desc:    "The elements of " + name + " cannot contain Delete",
+				comment: fmt.Sprintf(`The Delete sentinel must be the value of a field. It may not appear in an %s.`, name),
+				inData:  fmt.Sprintf(`{"a": ["%s", 1, "Delete", 3]}`, name),
+				paths:   [][]string{{"a"}},
+				values:  []string{fmt.Sprintf(`["%s", 1, "Delete", 3]`, name)},
+				isErr:   true,
+			},
+		}...)
+	}
+	return tests
+}
+
+// arrayTransformAloneTests returns tests in which the only values are
+// ArrayUnion or ArrayRemove sentinels. The write has outData, or only applies
+// the transforms if outData is nil.
+func arrayTransformAloneTests(outData map[string]*fspb.Value) []writeTest {
+	var tests []writeTest
+	for _, at := range arrayTransforms {
+		comment := fmt.Sprintf(`If the only values in the input are %ss, then the
+write only applies the transforms, and writes no fields.`, at.name)
+		if outData != nil {
+			comment = fmt.Sprintf(`If the only values in the input are %ss, then
+an update operation with an empty map should be produced.`, at.name)
+		}
+		tests = append(tests, writeTest{
+			suffix:        strings.ToLower(at.name) + "-alone",
+			desc:          at.name + " alone",
+			comment:       comment,
+			inData:        fmt.Sprintf(`{"a": ["%s", 1, 2, 3]}`, at.name),
+			paths:         [][]string{{"a"}},
+			values:        []string{fmt.Sprintf(`["%s", 1, 2, 3]`, at.name)},
+			outData:       outData,
+			maskForUpdate: nil,
+			transform:     transforms(at.transform("a", 1, 2, 3)),
+		})
+	}
+	return tests
+}
+
+// incrementTests returns the tests of the Increment sentinel that apply to
+// Create, Set, Update and UpdatePaths.
+func incrementTests() []writeTest {
+	return []writeTest{
+		{
+			suffix: "increment",
+			desc:   "Increment with data",
+			comment: `A key with Increment is removed from the data in the update
+operation. Instead it becomes a field transform of the write. In these tests, a JSON
+array whose first element is the string "Increment" should be replaced with the special
+Increment value, whose operand is the second element of the array.`,
+			inData:        `{"a": 1, "b": ["Increment", 1]}`,
+			paths:         [][]string{{"a"}, {"b"}},
+			values:        []string{`1`, `["Increment", 1]`},
+			outData:       mp("a", 1),
+			maskForUpdate: []string{"a"},
+			transform:     transforms(increment("b", 1)),
+		},
+		{
+			suffix: "increment-double",
+			desc:   "Increment with a double operand",
+			comment: `The operand of Increment can be a double. It is sent to the
+service as a double, not converted to an integer.`,
+			inData:        `{"a": 1, "b": ["Increment", 2.5]}`,
+			paths:         [][]string{{"a"}, {"b"}},
+			values:        []string{`1`, `["Increment", 2.5]`},
+			outData:       mp("a", 1),
+			maskForUpdate: []string{"a"},
+			transform:     transforms(increment("b", 2.5)),
+		},
+		{
+			suffix: "increment-nested",
+			desc:   "nested Increment field",
+			comment: `An Increment value can occur at any depth. In this case,
+the transform applies to the field path "b.c". Since "c" is removed from the update,
+"b" becomes empty, so it is also removed from the update.`,
+			inData:        `{"a": 1, "b": {"c": ["Increment", 1]}}`,
+			paths:         [][]string{{"a"}, {"b"}},
+			values:        []string{`1`, `{"c": ["Increment", 1]}`},
+			outData:       mp("a", 1),
+			maskForUpdate: []string{"a", "b"},
+			transform:     transforms(increment("b.c", 1)),
+		},
+		{
+			suffix: "increment-multi",
+			desc:   "multiple Increment fields",
+			comment: `A document can have more than one Increment field, with
+operands of different types.`,
+			commentForUpdate: `b is not in the mask because it will be set in the transform.
+c must be in the mask: it should be replaced entirely. The transform will change c.d,
+but the update will delete the rest of c.`,
+			inData:        `{"a": 1, "b": ["Increment", 2], "c": {"d": ["Increment", 3.5]}}`,
+			paths:         [][]string{{"a"}, {"b"}, {"c"}},
+			values:        []string{`1`, `["Increment", 2]`, `{"d": ["Increment", 3.5]}`},
+			outData:       mp("a", 1),
+			maskForUpdate: []string{"a", "c"},
+			transform:     transforms(increment("b", 2), increment("c.d", 3.5)),
+		},
+		{
+			suffix:        "increment-with-st",
+			desc:          "Increment and ServerTimestamp in the same call",
+			comment:       `Increment and ServerTimestamp can be used together, on different fields.`,
+			inData:        `{"a": 1, "b": "ServerTimestamp", "c": ["Increment", 1]}`,
+			paths:         [][]string{{"a"}, {"b"}, {"c"}},
+			values:        []string{`1`, `"ServerTimestamp"`, `["Increment", 1]`},
+			outData:       mp("a", 1),
+			maskForUpdate: []string{"a"},
+			transform:     transforms(st("b"), increment("c", 1)),
+		},
+		// Errors
+		{
+			suffix: "increment-noarray",
+			desc:   "Increment cannot be in an array value",
+			comment: `Increment must be the value of a field. Firestore
+transforms don't support array indexing.`,
+			inData: `{"a": [1, 2, ["Increment", 1]]}`,
+			paths:  [][]string{{"a"}},
+			values: []string{`[1, 2, ["Increment", 1]]`},
+			isErr:  true,
+		},
+		{
+			suffix: "increment-noarray-nested",
+			desc:   "Increment cannot be anywhere inside an array value",
+			comment: `There cannot be an array value anywhere on the path from the document
+root to the Increment. Firestore transforms don't support array indexing.`,
+			inData: `{"a": [1, {"b": ["Increment", 1]}]}`,
+			paths:  [][]string{{"a"}},
+			values: []string{`[1, {"b": ["Increment", 1]}]`},
+			isErr:  true,
+		},
+		{
+			suffix:  "increment-not-number",
+			desc:    "Increment operand must be a number",
+			comment: `The operand of Increment must be an integer or a double.`,
+			inData:  `{"a": ["Increment", "1"]}`,
+			paths:   [][]string{{"a"}},
+			values:  []string{`["Increment", "1"]`},
+			isErr:   true,
+		},
+		{
+			suffix:  "increment-st-operand",
+			desc:    "Increment operand cannot be ServerTimestamp",
+			comment: `The ServerTimestamp sentinel must be the value of a field. It may not be the operand of Increment.`,
+			inData:  `{"a": ["Increment", "ServerTimestamp"]}`,
+			paths:   [][]string{{"a"}},
+			values:  []string{`["Increment", "ServerTimestamp"]`},
+			isErr:   true,
+		},
+	}
+}
+
+// incrementAloneTest returns a test in which the only value is an Increment
+// sentinel. The write has outData, or only applies the transform if outData is
+// nil.
+func incrementAloneTest(outData map[string]*fspb.Value) writeTest {
+	comment := `If the only values in the input are Increments, then the
+write only applies the transforms, and writes no fields.`
+	if outData != nil {
+		comment = `If the only values in the input are Increments, then
+an update operation with an empty map should be produced.`
+	}
+	return writeTest{
+		suffix:        "increment-alone",
+		desc:          "Increment alone",
+		comment:       comment,
+		inData:        `{"a": ["Increment", 1]}`,
+		paths:         [][]string{{"a"}},
+		values:        []string{`["Increment", 1]`},
+		outData:       outData,
+		maskForUpdate: nil,
+		transform:     transforms(increment("a", 1)),
+	}
+}
+
 func main() {
 	flag.Parse()
 	if *outputDir == "" {
 		log.Fatal("-o required")
 	}
+	if *api != "v1" && *api != "v1beta1" {
+		log.Fatalf("-api must be v1 or v1beta1, not %q", *api)
+	}
 	suite := &tpb.TestSuite{}
 	genGet(suite)
+	genGetAll(suite)
 	genCreate(suite)
 	genSet(suite)
 	genUpdate(suite)
 	genUpdatePaths(suite)
 	genDelete(suite)
+	genBatch(suite)
+	genTransaction(suite)
 	genQuery(suite)
+	genQueryResults(suite)
+	genAggregationQuery(suite)
+	genObject(suite)
+	genDecode(suite)
+	genFieldPath(suite)
+	genPath(suite)
+	genDatabases(suite)
 	genListen(suite)
-	if err := writeProtoToFile(filepath.Join(*outputDir, "test-suite.binproto"), suite); err != nil {
+	var out proto.Message = suite
+	if *api == "v1beta1" {
+		bsuite := &v1beta1pb.TestSuite{}
+		for _, t := range suite.Tests {
+			if bt, ok := forAPI(t); ok {
+				bsuite.Tests = append(bsuite.Tests, bt.(*v1beta1pb.Test))
+			}
+		}
+		out = bsuite
+	}
+	if err := writeProtoToFile(filepath.Join(*outputDir, "test-suite.binproto"), out); err != nil {
 		log.Fatal(err)
 	}
 	fmt.Printf("wrote %d tests to %s\n", nTests, *outputDir)
@@ -365,54 +796,289 @@ func genGet(suite *tpb.TestSuite) {
 	outputTestText("get-basic", "A call to DocumentRef.Get.", tp)
 }
 
+func genGetAll(suite *tpb.TestSuite) {
+	ts := func(secs int) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: int64(secs)}
+	}
+
+	path := func(id string) string { return collPath + "/" + id }
+
+	doc := func(id string, fields map[string]*fspb.Value) *fspb.Document {
+		return &fspb.Document{
+			Name:       path(id),
+			Fields:     fields,
+			CreateTime: ts(1),
+			UpdateTime: ts(1),
+		}
+	}
+
+	found := func(doc *fspb.Document, readTime *tspb.Timestamp) *fspb.BatchGetDocumentsResponse {
+		return &fspb.BatchGetDocumentsResponse{
+			Result:   &fspb.BatchGetDocumentsResponse_Found{doc},
+			ReadTime: readTime,
+		}
+	}
+
+	missing := func(id string, readTime *tspb.Timestamp) *fspb.BatchGetDocumentsResponse {
+		return &fspb.BatchGetDocumentsResponse{
+			Result:   &fspb.BatchGetDocumentsResponse_Missing{path(id)},
+			ReadTime: readTime,
+		}
+	}
+
+	snap := func(doc *fspb.Document, readTime *tspb.Timestamp) *tpb.DocumentSnapshot {
+		return &tpb.DocumentSnapshot{Doc: doc, ReadTime: readTime}
+	}
+
+	missingSnap := func(id string, readTime *tspb.Timestamp) *tpb.DocumentSnapshot {
+		return &tpb.DocumentSnapshot{Doc: &fspb.Document{Name: path(id)}, ReadTime: readTime, Missing: true}
+	}
+
+	doc1 := doc("d1", mp("a", 1))
+	doc2 := doc("d2", mp("a", 2))
+	txn := []byte("transaction-1")
+
+	for _, test := range []struct {
+		suffix    string
+		desc      string
+		comment   string
+		ids       []string                          // IDs of the documents passed to GetAll
+		mask      [][]string                        // field mask
+		txn       []byte                            // transaction ID
+		reqIDs    []string                          // IDs of the documents in the expected request
+		reqMask   []string                          // field paths of the expected request's mask
+		responses []*fspb.BatchGetDocumentsResponse // responses from the service
+		snapshots []*tpb.DocumentSnapshot           // expected snapshots
+		isErr     bool
+	}{
+		{
+			suffix:    "basic",
+			desc:      "two documents",
+			comment:   `GetAll reads several documents in a single request.`,
+			ids:       []string{"d1", "d2"},
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc1, ts(2)), found(doc2, ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), snap(doc2, ts(2))},
+		},
+		{
+			suffix: "missing",
+			desc:   "a document that does not exist",
+			comment: `A document that does not exist produces a snapshot that reports it is missing,
+with the read time of its response.`,
+			ids:       []string{"d1", "d2"},
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc1, ts(2)), missing("d2", ts(3))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), missingSnap("d2", ts(3))},
+		},
+		{
+			suffix: "out-of-order",
+			desc:   "responses in a different order",
+			comment: `The service may return the documents in any order. The snapshots are in the
+order of the arguments.`,
+			ids:       []string{"d1", "d2"},
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc2, ts(2)), found(doc1, ts(3))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(3)), snap(doc2, ts(2))},
+		},
+		{
+			suffix: "duplicates",
+			desc:   "the same document twice",
+			comment: `A document passed more than once is requested only once, but there is a snapshot
+for each time it was passed.`,
+			ids:       []string{"d1", "d2", "d1"},
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc1, ts(2)), found(doc2, ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), snap(doc2, ts(2)), snap(doc1, ts(2))},
+		},
+		{
+			suffix: "mask",
+			desc:   "a field mask",
+			comment: `A field mask limits the fields the service returns. Its field paths are encoded
+in the request as in an update mask.`,
+			ids:       []string{"d1"},
+			mask:      [][]string{{"a"}, {"b", "c"}, {"d~"}},
+			reqIDs:    []string{"d1"},
+			reqMask:   []string{"a", "b.c", "`d~`"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc("d1", mp("a", 1, "b", mp("c", 2))), ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc("d1", mp("a", 1, "b", mp("c", 2))), ts(2))},
+		},
+		{
+			suffix: "transaction",
+			desc:   "in a transaction",
+			comment: `GetAll in a transaction reads the documents with the ID of the
+transaction.`,
+			ids:       []string{"d1", "d2"},
+			txn:       txn,
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc1, ts(2)), missing("d2", ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), missingSnap("d2", ts(2))},
+		},
+		{
+			suffix: "error",
+			desc:   "the stream fails",
+			comment: `If the stream fails before all the documents are returned, GetAll signals an
+error.`,
+			ids:       []string{"d1", "d2"},
+			reqIDs:    []string{"d1", "d2"},
+			responses: []*fspb.BatchGetDocumentsResponse{found(doc1, ts(2))},
+			isErr:     true,
+		},
+	} {
+		var paths []string
+		for _, id := range test.ids {
+			paths = append(paths, path(id))
+		}
+		req := &fspb.BatchGetDocumentsRequest{Database: database}
+		for _, id := range test.reqIDs {
+			req.Documents = append(req.Documents, path(id))
+		}
+		if test.reqMask != nil {
+			req.Mask = &fspb.DocumentMask{FieldPaths: test.reqMask}
+		}
+		if test.txn != nil {
+			req.ConsistencySelector = &fspb.BatchGetDocumentsRequest_Transaction{test.txn}
+		}
+		tp := &tpb.Test{
+			Description: "get-all: " + test.desc,
+			Test: &tpb.Test_GetAll{&tpb.GetAllTest{
+				DocRefPaths: paths,
+				FieldMask:   toFieldPaths(test.mask),
+				Transaction: test.txn,
+				Request:     req,
+				Responses:   test.responses,
+				Snapshots:   test.snapshots,
+				IsError:     test.isErr,
+			}},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(fmt.Sprintf("get-all-%s", test.suffix), test.comment, tp)
+	}
+}
+
 func genCreate(suite *tpb.TestSuite) {
 	var tests []writeTest
 	tests = append(tests, basicTests...)
+	tests = append(tests, valueTests...)
 	tests = append(tests, createSetTests...)
 	tests = append(tests, serverTimestampTests...)
 	tests = append(tests, sentinelErrorTests...)
+	tests = append(tests, arrayTransformTests()...)
+	tests = append(tests, arrayTransformAloneTests(nil)...)
+	tests = append(tests, incrementTests()...)
+	tests = append(tests, incrementAloneTest(nil))
 	tests = append(tests, writeTest{
 		suffix: "st-alone",
 		desc:   "ServerTimestamp alone",
-		comment: `If the only values in the input are ServerTimestamps, then no
-update operation should be produced.`,
+		comment: `If the only values in the input are ServerTimestamps, then the
+write only applies the transforms, and writes no fields.`,
 		inData:        `{"a": "ServerTimestamp"}`,
 		paths:         [][]string{{"a"}},
 		values:        []string{`"ServerTimestamp"`},
 		outData:       nil,
 		maskForUpdate: nil,
-		transform:     []string{"a"},
+		transform:     transforms(st("a")),
 	})
 
-	precond := &fspb.Precondition{
-		ConditionType: &fspb.Precondition_Exists{false},
-	}
 	for _, test := range tests {
 		var req *fspb.CommitRequest
 		if !test.isErr {
-			req = newCommitRequest(test.outData, test.mask, precond, test.transform)
+			req = newCommitRequest(test.outData, test.mask, existsFalsePrecondition, test.transform)
+		}
+		ct := &tpb.CreateTest{
+			DocRefPath: docPath,
+			JsonData:   test.inData,
+			Request:    req,
+			IsError:    test.isErr,
 		}
+		filename := fmt.Sprintf("create-%s", test.suffix)
+		mreq, err := writes.Create(ct)
+		checkWriteTest(filename, ct.Request, ct.IsError, mreq, err)
 		tp := &tpb.Test{
 			Description: "create: " + test.desc,
-			Test: &tpb.Test_Create{&tpb.CreateTest{
-				DocRefPath: docPath,
-				JsonData:   test.inData,
-				Request:    req,
-				IsError:    test.isErr,
-			}},
+			Test:        &tpb.Test_Create{ct},
 		}
 		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("create-%s", test.suffix), test.comment, tp)
+		outputTestText(filename, test.comment, tp)
 	}
 
 }
 func genSet(suite *tpb.TestSuite) {
 	var tests []writeTest
 	tests = append(tests, basicTests...)
+	tests = append(tests, valueTests...)
 	tests = append(tests, createSetTests...)
 	tests = append(tests, serverTimestampTests...)
 	tests = append(tests, sentinelErrorTests...)
+	tests = append(tests, arrayTransformTests()...)
+	tests = append(tests, arrayTransformAloneTests(mp())...)
+	tests = append(tests, incrementTests()...)
+	tests = append(tests, incrementAloneTest(mp()))
+	for _, at := range arrayTransforms {
+		name, lname := at.name, strings.ToLower(at.name)
+		tests = append(tests, []writeTest{
+			{
+				suffix:    lname + "-mergeall",
+				desc:      name + " with MergeAll",
+				comment:   fmt.Sprintf(`With a MergeAll option, %s becomes a transform as usual.`, name),
+				inData:    fmt.Sprintf(`{"a": 1, "b": ["%s", 1, 2]}`, name),
+				opt:       mergeAllOption,
+				outData:   mp("a", 1),
+				mask:      []string{"a"},
+				transform: transforms(at.transform("b", 1, 2)),
+			},
+			{
+				suffix:    lname + "-merge",
+				desc:      name + " with Merge of both fields",
+				comment:   fmt.Sprintf(`With a merge option that mentions its field, %s becomes a transform as usual.`, name),
+				inData:    fmt.Sprintf(`{"a": 1, "b": ["%s", 1, 2]}`, name),
+				opt:       mergeOption([]string{"a"}, []string{"b"}),
+				outData:   mp("a", 1),
+				mask:      []string{"a"},
+				transform: transforms(at.transform("b", 1, 2)),
+			},
+			{
+				suffix: lname + "-nomerge",
+				desc:   "If " + name + " is not in Merge, no transform",
+				comment: fmt.Sprintf(`If the %s value is not mentioned in a merge option,
+then it is pruned from the data but does not result in a transform.`, name),
+				inData:  fmt.Sprintf(`{"a": 1, "b": ["%s", 1, 2]}`, name),
+				opt:     mergeOption([]string{"a"}),
+				outData: mp("a", 1),
+				mask:    []string{"a"},
+			},
+		}...)
+	}
 	tests = append(tests, []writeTest{
+		{
+			suffix:    "increment-mergeall",
+			desc:      "Increment with MergeAll",
+			comment:   `With a MergeAll option, Increment becomes a transform as usual.`,
+			inData:    `{"a": 1, "b": ["Increment", 1]}`,
+			opt:       mergeAllOption,
+			outData:   mp("a", 1),
+			mask:      []string{"a"},
+			transform: transforms(increment("b", 1)),
+		},
+		{
+			suffix:    "increment-merge",
+			desc:      "Increment with Merge of both fields",
+			comment:   `With a merge option that mentions its field, Increment becomes a transform as usual.`,
+			inData:    `{"a": 1, "b": ["Increment", 1]}`,
+			opt:       mergeOption([]string{"a"}, []string{"b"}),
+			outData:   mp("a", 1),
+			mask:      []string{"a"},
+			transform: transforms(increment("b", 1)),
+		},
+		{
+			suffix: "increment-nomerge",
+			desc:   "If Increment is not in Merge, no transform",
+			comment: `If the Increment value is not mentioned in a merge option,
+then it is pruned from the data but does not result in a transform.`,
+			inData:  `{"a": 1, "b": ["Increment", 1]}`,
+			opt:     mergeOption([]string{"a"}),
+			outData: mp("a", 1),
+			mask:    []string{"a"},
+		},
 		{
 			suffix: "st-alone",
 			desc:   "ServerTimestamp alone",
@@ -423,7 +1089,7 @@ an update operation with an empty map should be produced.`,
 			values:        []string{`"ServerTimestamp"`},
 			outData:       mp(),
 			maskForUpdate: nil,
-			transform:     []string{"a"},
+			transform:     transforms(st("a")),
 		},
 		{
 			suffix:  "mergeall",
@@ -492,20 +1158,20 @@ transforms.`,
 			opt:       mergeAllOption,
 			outData:   mp("a", 1),
 			mask:      []string{"a"},
-			transform: []string{"b"},
+			transform: transforms(st("b")),
 		},
 		{
 			suffix: "st-alone-mergeall",
 			desc:   "ServerTimestamp alone with MergeAll",
-			comment: `If the only values in the input are ServerTimestamps, then no
-update operation should be produced.`,
+			comment: `If the only values in the input are ServerTimestamps, then the
+write only applies the transforms, and writes no fields.`,
 			inData:        `{"a": "ServerTimestamp"}`,
 			opt:           mergeAllOption,
 			paths:         [][]string{{"a"}},
 			values:        []string{`"ServerTimestamp"`},
 			outData:       nil,
 			maskForUpdate: nil,
-			transform:     []string{"a"},
+			transform:     transforms(st("a")),
 		},
 		{
 			suffix: "st-merge-both",
@@ -517,7 +1183,7 @@ transforms.`,
 			opt:       mergeOption([]string{"a"}, []string{"b"}),
 			outData:   mp("a", 1),
 			mask:      []string{"a"},
-			transform: []string{"b"},
+			transform: transforms(st("b")),
 		},
 		{
 			suffix: "st-nomerge",
@@ -533,10 +1199,10 @@ then it is pruned from the data but does not result in a transform.`,
 			suffix: "st-merge-nowrite",
 			desc:   "If no ordinary values in Merge, no write",
 			comment: `If all the fields in the merge option have ServerTimestamp
-values, then no update operation is produced, only a transform.`,
+values, then the write only applies the transform, and writes no fields.`,
 			inData:    `{"a": 1, "b": "ServerTimestamp"}`,
 			opt:       mergeOption([]string{"b"}),
-			transform: []string{"b"},
+			transform: transforms(st("b")),
 		},
 		{
 			suffix: "st-merge-nonleaf",
@@ -548,7 +1214,7 @@ as usual.`,
 			opt:       mergeOption([]string{"h"}),
 			outData:   mp("h", mp("f", 5)),
 			mask:      []string{"h"},
-			transform: []string{"h.g"},
+			transform: transforms(st("h.g")),
 		},
 		{
 			suffix: "st-merge-nonleaf-alone",
@@ -559,7 +1225,7 @@ and we clear the value by including the field path in the update mask.`,
 			inData:    `{"h": {"g": "ServerTimestamp"}, "e": 7}`,
 			opt:       mergeOption([]string{"h"}),
 			mask:      []string{"h"},
-			transform: []string{"h.g"},
+			transform: transforms(st("h.g")),
 		},
 		{
 			suffix:  "del-mergeall",
@@ -657,27 +1323,36 @@ probably a programming error.`,
 		if test.opt != nil && !test.opt.All {
 			prefix = "set-merge"
 		}
+		st := &tpb.SetTest{
+			DocRefPath: docPath,
+			Option:     test.opt,
+			JsonData:   test.inData,
+			Request:    req,
+			IsError:    test.isErr,
+		}
+		filename := fmt.Sprintf("set-%s", test.suffix)
+		mreq, err := writes.Set(st)
+		checkWriteTest(filename, st.Request, st.IsError, mreq, err)
 		tp := &tpb.Test{
 			Description: prefix + ": " + test.desc,
-			Test: &tpb.Test_Set{&tpb.SetTest{
-				DocRefPath: docPath,
-				Option:     test.opt,
-				JsonData:   test.inData,
-				Request:    req,
-				IsError:    test.isErr,
-			}},
+			Test:        &tpb.Test_Set{st},
 		}
 		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("set-%s", test.suffix), test.comment, tp)
+		outputTestText(filename, test.comment, tp)
 	}
 }
 
 func genUpdate(suite *tpb.TestSuite) {
 	var tests []writeTest
 	tests = append(tests, basicTests...)
+	tests = append(tests, valueTests...)
 	tests = append(tests, updateTests...)
 	tests = append(tests, serverTimestampTests...)
 	tests = append(tests, sentinelErrorTests...)
+	tests = append(tests, arrayTransformTests()...)
+	tests = append(tests, arrayTransformAloneTests(nil)...)
+	tests = append(tests, incrementTests()...)
+	tests = append(tests, incrementAloneTest(nil))
 	tests = append(tests, []writeTest{
 		{
 			suffix:  "split",
@@ -719,9 +1394,9 @@ values are pruned from the output data, but appear in the update mask.`,
 			desc:   "ServerTimestamp with dotted field",
 			comment: `Like other uses of ServerTimestamp, the data is pruned and the
 field does not appear in the update mask, because it is in the transform. In this case
-An update operation is produced just to hold the precondition.`,
+the write only applies the transform, with the Update's precondition.`,
 			inData:    `{"a.b.c": "ServerTimestamp"}`,
-			transform: []string{"a.b.c"},
+			transform: transforms(st("a.b.c")),
 		},
 		// Errors
 		{
@@ -734,31 +1409,40 @@ An update operation is produced just to hold the precondition.`,
 	}...)
 
 	for _, test := range tests {
+		ut := &tpb.UpdateTest{
+			DocRefPath:   docPath,
+			Precondition: test.precond,
+			JsonData:     test.inData,
+			Request:      newUpdateCommitRequest(test),
+			IsError:      test.isErr,
+		}
+		filename := fmt.Sprintf("update-%s", test.suffix)
+		mreq, err := writes.Update(ut)
+		checkWriteTest(filename, ut.Request, ut.IsError, mreq, err)
 		tp := &tpb.Test{
 			Description: "update: " + test.desc,
-			Test: &tpb.Test_Update{&tpb.UpdateTest{
-				DocRefPath:   docPath,
-				Precondition: test.precond,
-				JsonData:     test.inData,
-				Request:      newUpdateCommitRequest(test),
-				IsError:      test.isErr,
-			}},
+			Test:        &tpb.Test_Update{ut},
 		}
 		comment := test.comment
 		if test.commentForUpdate != "" {
 			comment += "\n\n" + test.commentForUpdate
 		}
 		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("update-%s", test.suffix), comment, tp)
+		outputTestText(filename, comment, tp)
 	}
 }
 
 func genUpdatePaths(suite *tpb.TestSuite) {
 	var tests []writeTest
 	tests = append(tests, basicTests...)
+	tests = append(tests, valueTests...)
 	tests = append(tests, updateTests...)
 	tests = append(tests, serverTimestampTests...)
 	tests = append(tests, sentinelErrorTests...)
+	tests = append(tests, arrayTransformTests()...)
+	tests = append(tests, arrayTransformAloneTests(nil)...)
+	tests = append(tests, incrementTests()...)
+	tests = append(tests, incrementAloneTest(nil))
 	tests = append(tests, []writeTest{
 		{
 			suffix: "fp-multi",
@@ -820,23 +1504,27 @@ Each FieldPath is a sequence of uninterpreted path components.`,
 		if len(test.paths) != len(test.values) {
 			log.Fatalf("test %s has mismatched paths and values", test.desc)
 		}
+		ut := &tpb.UpdatePathsTest{
+			DocRefPath:   docPath,
+			Precondition: test.precond,
+			FieldPaths:   toFieldPaths(test.paths),
+			JsonValues:   test.values,
+			Request:      newUpdateCommitRequest(test),
+			IsError:      test.isErr,
+		}
+		filename := fmt.Sprintf("update-paths-%s", test.suffix)
+		mreq, err := writes.UpdatePaths(ut)
+		checkWriteTest(filename, ut.Request, ut.IsError, mreq, err)
 		tp := &tpb.Test{
 			Description: "update-paths: " + test.desc,
-			Test: &tpb.Test_UpdatePaths{&tpb.UpdatePathsTest{
-				DocRefPath:   docPath,
-				Precondition: test.precond,
-				FieldPaths:   toFieldPaths(test.paths),
-				JsonValues:   test.values,
-				Request:      newUpdateCommitRequest(test),
-				IsError:      test.isErr,
-			}},
+			Test:        &tpb.Test_UpdatePaths{ut},
 		}
 		comment := test.comment
 		if test.commentForUpdate != "" {
 			comment += "\n\n" + test.commentForUpdate
 		}
 		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("update-paths-%s", test.suffix), test.comment, tp)
+		outputTestText(filename, test.comment, tp)
 	}
 }
 
@@ -877,17 +1565,21 @@ func genDelete(suite *tpb.TestSuite) {
 				req.Writes[0].CurrentDocument = test.precond
 			}
 		}
+		dt := &tpb.DeleteTest{
+			DocRefPath:   docPath,
+			Precondition: test.precond,
+			Request:      req,
+			IsError:      test.isErr,
+		}
+		filename := fmt.Sprintf("delete-%s", test.suffix)
+		mreq, err := writes.Delete(dt)
+		checkWriteTest(filename, dt.Request, dt.IsError, mreq, err)
 		tp := &tpb.Test{
 			Description: "delete: " + test.desc,
-			Test: &tpb.Test_Delete{&tpb.DeleteTest{
-				DocRefPath:   docPath,
-				Precondition: test.precond,
-				Request:      req,
-				IsError:      test.isErr,
-			}},
+			Test:        &tpb.Test_Delete{dt},
 		}
 		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("delete-%s", test.suffix), test.comment, tp)
+		outputTestText(filename, test.comment, tp)
 	}
 }
 
@@ -908,70 +1600,340 @@ func newUpdateCommitRequest(test writeTest) *fspb.CommitRequest {
 	return newCommitRequest(test.outData, mask, precond, test.transform)
 }
 
-func newCommitRequest(writeFields map[string]*fspb.Value, mask []string, precond *fspb.Precondition, transform []string) *fspb.CommitRequest {
-	var writes []*fspb.Write
-	if writeFields != nil || mask != nil {
-		w := &fspb.Write{
-			Operation: &fspb.Write_Update{
-				Update: &fspb.Document{
-					Name:   docPath,
-					Fields: writeFields,
-				},
-			},
-			CurrentDocument: precond,
-		}
-		if mask != nil {
-			w.UpdateMask = &fspb.DocumentMask{FieldPaths: mask}
-		}
-		writes = append(writes, w)
-		precond = nil // don't need precond in transform if it is in write
-	}
-	if transform != nil {
-		var fts []*fspb.DocumentTransform_FieldTransform
-		for _, p := range transform {
-			fts = append(fts, &fspb.DocumentTransform_FieldTransform{
-				FieldPath: p,
-				TransformType: &fspb.DocumentTransform_FieldTransform_SetToServerValue{
-					fspb.DocumentTransform_FieldTransform_REQUEST_TIME,
-				},
-			})
-		}
-		writes = append(writes, &fspb.Write{
-			Operation: &fspb.Write_Transform{
-				&fspb.DocumentTransform{
-					Document:        docPath,
-					FieldTransforms: fts,
-				},
-			},
-			CurrentDocument: precond,
-		})
-	}
+func newCommitRequest(writeFields map[string]*fspb.Value, mask []string, precond *fspb.Precondition, transform []*fspb.DocumentTransform_FieldTransform) *fspb.CommitRequest {
 	return &fspb.CommitRequest{
 		Database: database,
-		Writes:   writes,
+		Writes:   newWrites(docPath, writeFields, mask, precond, transform),
 	}
 }
 
-var mergeAllOption = &tpb.SetOption{All: true}
-
-func mergeOption(paths ...[]string) *tpb.SetOption {
-	return &tpb.SetOption{Fields: toFieldPaths(paths)}
+// newWrites returns the writes for a single write call on the document at path.
+func newWrites(path string, writeFields map[string]*fspb.Value, mask []string, precond *fspb.Precondition, transform []*fspb.DocumentTransform_FieldTransform) []*fspb.Write {
+	w := &fspb.Write{
+		Operation: &fspb.Write_Update{
+			Update: &fspb.Document{
+				Name:   path,
+				Fields: writeFields,
+			},
+		},
+		CurrentDocument:  precond,
+		UpdateTransforms: transform,
+	}
+	switch {
+	case mask != nil:
+		w.UpdateMask = &fspb.DocumentMask{FieldPaths: mask}
+	case writeFields == nil:
+		// The write only applies the transforms; the empty mask keeps the
+		// document's fields.
+		w.UpdateMask = &fspb.DocumentMask{}
+	}
+	if writeFields == nil && mask == nil && transform == nil {
+		return nil
+	}
+	return []*fspb.Write{w}
 }
 
-// A queryTest describes a series of function calls to create a Query.
-type queryTest struct {
-	suffix  string                // textproto filename suffix
-	desc    string                // short description
-	comment string                // detailed explanation (comment in textproto file)
-	clauses []interface{}         // the query clauses (corresponding to function calls)
-	query   *fspb.StructuredQuery // the desired proto
-	isErr   bool                  // arguments result in a client-side error
+// checkWriteTest compares the request of a write test with the one computed by
+// the reference model in package writes.
+func checkWriteTest(filename string, want *fspb.CommitRequest, wantErr bool, got *fspb.CommitRequest, err error) {
+	switch {
+	case wantErr && err == nil:
+		log.Fatalf("%s: model produced\n%s\nbut the test expects an error", filename, proto.MarshalTextString(got))
+	case !wantErr && err != nil:
+		log.Fatalf("%s: model returned error %v", filename, err)
+	case !wantErr && !proto.Equal(got, want):
+		log.Fatalf("%s: model produced\n%s\ntest has\n%s", filename, proto.MarshalTextString(got), proto.MarshalTextString(want))
+	}
 }
 
-func genQuery(suite *tpb.TestSuite) {
-	docsnap := &tpb.Cursor{
-		DocSnapshot: &tpb.DocSnapshot{
-			Path:     collPath + "/D",
+func genBatch(suite *tpb.TestSuite) {
+	d, e := collPath+"/d", collPath+"/e"
+	for _, test := range []struct {
+		suffix  string
+		desc    string
+		comment string
+		ops     []*tpb.WriteOp
+		writes  []*fspb.Write // expected writes
+		isErr   bool
+	}{
+		{
+			suffix:  "basic",
+			desc:    "writes to two documents",
+			comment: `A batch commits the writes of its operations in a single request.`,
+			ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.CreateTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+				toWriteOp(&tpb.DeleteTest{DocRefPath: e}),
+			},
+			writes: concatWrites(
+				newWrites(d, mp("a", 1), nil, existsFalsePrecondition, nil),
+				deleteWrites(e, nil)),
+		},
+		{
+			suffix:  "all-ops",
+			desc:    "one of each write operation",
+			comment: `Every kind of write can appear in a batch. The writes are in the order of the operations.`,
+			ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.SetTest{DocRefPath: collPath + "/s", Option: mergeAllOption, JsonData: `{"a": 1}`}),
+				toWriteOp(&tpb.UpdateTest{DocRefPath: collPath + "/u", JsonData: `{"b": 2}`}),
+				toWriteOp(&tpb.UpdatePathsTest{
+					DocRefPath: collPath + "/p",
+					FieldPaths: []*tpb.FieldPath{fp("c")},
+					JsonValues: []string{`3`},
+				}),
+				toWriteOp(&tpb.DeleteTest{DocRefPath: collPath + "/x", Precondition: updateTimePrecondition}),
+				toWriteOp(&tpb.CreateTest{DocRefPath: collPath + "/c", JsonData: `{"d": 4}`}),
+			},
+			writes: concatWrites(
+				newWrites(collPath+"/s", mp("a", 1), []string{"a"}, nil, nil),
+				newWrites(collPath+"/u", mp("b", 2), []string{"b"}, existsTruePrecondition, nil),
+				newWrites(collPath+"/p", mp("c", 3), []string{"c"}, existsTruePrecondition, nil),
+				deleteWrites(collPath+"/x", updateTimePrecondition),
+				newWrites(collPath+"/c", mp("d", 4), nil, existsFalsePrecondition, nil)),
+		},
+		{
+			suffix:  "same-doc",
+			desc:    "several writes to one document",
+			comment: `A batch may write the same document more than once.`,
+			ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.SetTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+				toWriteOp(&tpb.UpdateTest{DocRefPath: d, JsonData: `{"b": 2}`}),
+				toWriteOp(&tpb.DeleteTest{DocRefPath: d}),
+			},
+			writes: concatWrites(
+				newWrites(d, mp("a", 1), nil, nil, nil),
+				newWrites(d, mp("b", 2), []string{"b"}, existsTruePrecondition, nil),
+				deleteWrites(d, nil)),
+		},
+		{
+			suffix: "transforms",
+			desc:   "operations with transforms",
+			comment: `The transforms of each operation are applied by that operation's write.
+The transforms of an Update with no other fields carry the Update's precondition.`,
+			ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.CreateTest{DocRefPath: d, JsonData: `{"a": 1, "b": "ServerTimestamp"}`}),
+				toWriteOp(&tpb.UpdateTest{DocRefPath: e, JsonData: `{"c": ["Increment", 1]}`}),
+			},
+			writes: concatWrites(
+				newWrites(d, mp("a", 1), nil, existsFalsePrecondition, transforms(st("b"))),
+				newWrites(e, nil, nil, existsTruePrecondition, transforms(increment("c", 1)))),
+		},
+		{
+			suffix: "error",
+			desc:   "an invalid operation",
+			comment: `If any operation in a batch is invalid, the batch signals an error
+and sends no request.`,
+			ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.CreateTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+				toWriteOp(&tpb.CreateTest{DocRefPath: e, JsonData: `{"a": "Delete"}`}),
+			},
+			isErr: true,
+		},
+	} {
+		var req *fspb.CommitRequest
+		if !test.isErr {
+			req = &fspb.CommitRequest{Database: database, Writes: test.writes}
+		}
+		bt := &tpb.BatchTest{
+			Ops:     test.ops,
+			Request: req,
+			IsError: test.isErr,
+		}
+		filename := fmt.Sprintf("batch-%s", test.suffix)
+		mreq, err := writes.Batch(bt)
+		checkWriteTest(filename, bt.Request, bt.IsError, mreq, err)
+		tp := &tpb.Test{
+			Description: "batch: " + test.desc,
+			Test:        &tpb.Test_Batch{bt},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(filename, test.comment, tp)
+	}
+}
+
+func genTransaction(suite *tpb.TestSuite) {
+	d, e := collPath+"/d", collPath+"/e"
+	txn := []byte("transaction-1")
+	begin := &tpb.TransactionRequest{
+		Request: &tpb.TransactionRequest_BeginTransaction{
+			&fspb.BeginTransactionRequest{Database: database},
+		},
+	}
+	get := func(path string) *tpb.TransactionRequest {
+		return &tpb.TransactionRequest{
+			Request: &tpb.TransactionRequest_BatchGetDocuments{&fspb.BatchGetDocumentsRequest{
+				Database:            database,
+				Documents:           []string{path},
+				ConsistencySelector: &fspb.BatchGetDocumentsRequest_Transaction{txn},
+			}},
+		}
+	}
+	commit := func(ws ...[]*fspb.Write) *tpb.TransactionRequest {
+		return &tpb.TransactionRequest{
+			Request: &tpb.TransactionRequest_Commit{
+				&fspb.CommitRequest{Database: database, Writes: concatWrites(ws...), Transaction: txn},
+			},
+		}
+	}
+	rollback := &tpb.TransactionRequest{
+		Request: &tpb.TransactionRequest_Rollback{
+			&fspb.RollbackRequest{Database: database, Transaction: txn},
+		},
+	}
+
+	for _, test := range []struct {
+		suffix  string
+		desc    string
+		comment string
+		ops     []*tpb.TransactionOp
+		fnErr   bool                      // transaction function returns an error
+		reqs    []*tpb.TransactionRequest // expected requests
+		isErr   bool
+	}{
+		{
+			suffix:  "write",
+			desc:    "a single write",
+			comment: `The writes of a transaction are committed with the ID of the transaction.`,
+			ops: []*tpb.TransactionOp{
+				txnWrite(&tpb.SetTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+			},
+			reqs: []*tpb.TransactionRequest{
+				begin,
+				commit(newWrites(d, mp("a", 1), nil, nil, nil)),
+			},
+		},
+		{
+			suffix:  "read-write",
+			desc:    "a read followed by a write",
+			comment: `A read in a transaction is made with the ID of the transaction.`,
+			ops: []*tpb.TransactionOp{
+				txnGet(d),
+				txnWrite(&tpb.UpdateTest{DocRefPath: d, JsonData: `{"a": 2}`}),
+			},
+			reqs: []*tpb.TransactionRequest{
+				begin,
+				get(d),
+				commit(newWrites(d, mp("a", 2), []string{"a"}, existsTruePrecondition, nil)),
+			},
+		},
+		{
+			suffix:  "read-only",
+			desc:    "reads with no writes",
+			comment: `A transaction with no writes is still committed.`,
+			ops:     []*tpb.TransactionOp{txnGet(d), txnGet(e)},
+			reqs:    []*tpb.TransactionRequest{begin, get(d), get(e), commit()},
+		},
+		{
+			suffix:  "multi-write",
+			desc:    "writes to several documents",
+			comment: `All the writes of a transaction are committed together, in order.`,
+			ops: []*tpb.TransactionOp{
+				txnGet(d),
+				txnWrite(&tpb.CreateTest{DocRefPath: e, JsonData: `{"a": 1, "b": "ServerTimestamp"}`}),
+				txnWrite(&tpb.DeleteTest{DocRefPath: d}),
+			},
+			reqs: []*tpb.TransactionRequest{
+				begin,
+				get(d),
+				commit(
+					newWrites(e, mp("a", 1), nil, existsFalsePrecondition, transforms(st("b"))),
+					deleteWrites(d, nil)),
+			},
+		},
+		{
+			suffix: "function-error",
+			desc:   "the transaction function fails",
+			comment: `If the transaction function returns an error, the transaction is rolled back
+instead of committed, and RunTransaction signals an error.`,
+			ops: []*tpb.TransactionOp{
+				txnGet(d),
+				txnWrite(&tpb.SetTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+			},
+			fnErr: true,
+			reqs:  []*tpb.TransactionRequest{begin, get(d), rollback},
+			isErr: true,
+		},
+		{
+			suffix: "read-after-write",
+			desc:   "a read after a write",
+			comment: `A transaction cannot read after it writes. The read signals an error, which the
+transaction function returns, so the transaction is rolled back.`,
+			ops: []*tpb.TransactionOp{
+				txnWrite(&tpb.SetTest{DocRefPath: d, JsonData: `{"a": 1}`}),
+				txnGet(e),
+			},
+			reqs:  []*tpb.TransactionRequest{begin, rollback},
+			isErr: true,
+		},
+		{
+			suffix: "write-error",
+			desc:   "an invalid write",
+			comment: `An invalid write signals an error, which the transaction function returns,
+so the transaction is rolled back.`,
+			ops: []*tpb.TransactionOp{
+				txnGet(d),
+				txnWrite(&tpb.CreateTest{DocRefPath: e, JsonData: `{"a": "Delete"}`}),
+			},
+			reqs:  []*tpb.TransactionRequest{begin, get(d), rollback},
+			isErr: true,
+		},
+	} {
+		tt := &tpb.TransactionTest{
+			Ops:           test.ops,
+			FunctionError: test.fnErr,
+			Transaction:   txn,
+			Requests:      test.reqs,
+			IsError:       test.isErr,
+		}
+		filename := fmt.Sprintf("transaction-%s", test.suffix)
+		checkTransactionTest(filename, tt)
+		tp := &tpb.Test{
+			Description: "transaction: " + test.desc,
+			Test:        &tpb.Test_Transaction{tt},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(filename, test.comment, tp)
+	}
+}
+
+// checkTransactionTest compares the requests of a transaction test with those
+// computed by the reference model in package writes.
+func checkTransactionTest(filename string, tt *tpb.TransactionTest) {
+	reqs, err := writes.Transaction(tt)
+	if (err != nil) != tt.IsError {
+		log.Fatalf("%s: model returned error %v, but isErr is %t", filename, err, tt.IsError)
+	}
+	if len(reqs) != len(tt.Requests) {
+		log.Fatalf("%s: model produced %d requests, test has %d", filename, len(reqs), len(tt.Requests))
+	}
+	for i, req := range reqs {
+		if !proto.Equal(req, tt.Requests[i]) {
+			log.Fatalf("%s: request #%d: model produced\n%s\ntest has\n%s", filename, i,
+				proto.MarshalTextString(req), proto.MarshalTextString(tt.Requests[i]))
+		}
+	}
+}
+
+var mergeAllOption = &tpb.SetOption{All: true}
+
+func mergeOption(paths ...[]string) *tpb.SetOption {
+	return &tpb.SetOption{Fields: toFieldPaths(paths)}
+}
+
+// A queryTest describes a series of function calls to create a Query.
+type queryTest struct {
+	suffix          string                // textproto filename suffix
+	desc            string                // short description
+	comment         string                // detailed explanation (comment in textproto file)
+	collectionGroup bool                  // query the collection group of collPath
+	clauses         []interface{}         // the query clauses (corresponding to function calls)
+	query           *fspb.StructuredQuery // the desired proto
+	isErr           bool                  // arguments result in a client-side error
+}
+
+func genQuery(suite *tpb.TestSuite) {
+	docsnap := &tpb.Cursor{
+		DocSnapshot: &tpb.DocSnapshot{
+			Path:     collPath + "/D",
 			JsonData: `{"a": 7, "b": 8}`,
 		},
 	}
@@ -1078,458 +2040,2727 @@ func genQuery(suite *tpb.TestSuite) {
 			},
 		},
 		{
-			suffix:  "offset-limit",
-			desc:    "Offset and Limit clauses",
-			comment: `Offset and Limit clauses.`,
-			clauses: []interface{}{&tpb.Clause_Offset{2}, &tpb.Clause_Limit{3}},
+			suffix:  "where-timestamp",
+			desc:    "a Where clause comparing to a timestamp",
+			comment: `Values in Where clauses use the same encoding as document data.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "<", JsonValue: testTimeJSON},
+			},
 			query: &fspb.StructuredQuery{
-				Offset: 2,
-				Limit:  &wrappers.Int32Value{Value: 3},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_LESS_THAN, testTime),
 			},
 		},
 		{
-			suffix:  "offset-limit-last-wins",
-			desc:    "multiple Offset and Limit clauses",
-			comment: `With multiple Offset or Limit clauses, the last one wins.`,
+			suffix:  "where-bytes",
+			desc:    "a Where clause comparing to bytes",
+			comment: `Values in Where clauses use the same encoding as document data.`,
 			clauses: []interface{}{
-				&tpb.Clause_Offset{2},
-				&tpb.Clause_Limit{3},
-				&tpb.Clause_Limit{4},
-				&tpb.Clause_Offset{5},
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `{"$bytes": "AQID"}`},
 			},
 			query: &fspb.StructuredQuery{
-				Offset: 5,
-				Limit:  &wrappers.Int32Value{Value: 4},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, []byte{1, 2, 3}),
 			},
 		},
 		{
-			suffix:  "order",
-			desc:    "basic OrderBy clauses",
-			comment: `Multiple OrderBy clauses combine.`,
+			suffix:  "where-geopoint",
+			desc:    "a Where clause comparing to a geo point",
+			comment: `Values in Where clauses use the same encoding as document data.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("b"), Direction: "asc"},
-				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+				&tpb.Where{Path: fp("a"), Op: ">=", JsonValue: testGeoPointJSON},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
-					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL, testGeoPoint),
 			},
 		},
 		{
-			suffix:  "cursor-vals-1a",
-			desc:    "StartAt/EndBefore with values",
-			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			suffix:  "where-reference",
+			desc:    "a Where clause comparing to a reference",
+			comment: `Values in Where clauses use the same encoding as document data.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`7`}}},
-				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`9`}}},
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `{"$reference": "` + collPath + `/d2"}`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7)}, Before: true},
-				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9)}, Before: true},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, refval(collPath+"/d2")),
 			},
 		},
 		{
-			suffix:  "cursor-vals-1b",
-			desc:    "StartAfter/EndAt with values",
-			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			suffix:  "where-double",
+			desc:    "a Where clause comparing to an integral double",
+			comment: `A number with a fraction is a double, even if its value is integral.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`7`}}},
-				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`9`}}},
+				&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `5.0`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7)}, Before: false},
-				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9)}, Before: false},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 5.0),
 			},
 		},
 		{
-			suffix:  "cursor-vals-2",
-			desc:    "Start/End with two values",
-			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			suffix:  "where-unicode",
+			desc:    "a Where clause comparing to a Unicode string",
+			comment: `Field paths and string values may hold any Unicode characters.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.OrderBy{Path: fp("b"), Direction: "desc"},
-				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`7`, `8`}}},
-				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`9`, `10`}}},
+				&tpb.Where{Path: fp("é"), Op: "==", JsonValue: `"日本語 😀"`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-					{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
-				},
-				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7), val(8)}, Before: true},
-				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9), val(10)}, Before: false},
+				Where: filter("`é`", fspb.StructuredQuery_FieldFilter_EQUAL, "日本語 😀"),
 			},
 		},
 		{
-			suffix: "cursor-vals-docid",
-			desc:   "cursor methods with __name__",
-			comment: `Cursor values corresponding to a __name__ field take the document path relative to the
-query's collection.`,
+			suffix:  "where-in-values",
+			desc:    "a Where clause with in and values of several kinds",
+			comment: `The list of an in filter may hold values of any kind.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
-				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`"D1"`}}},
-				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"D2"`}}},
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: `[` + testTimeJSON + `, {"$bytes": "AQID"}, null, 1.0]`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{refval(collPath + "/D1")},
-					Before: false,
-				},
-				EndAt: &fspb.Cursor{
-					Values: []*fspb.Value{refval(collPath + "/D2")},
-					Before: true,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_IN,
+					[]interface{}{testTime, []byte{1, 2, 3}, nil, 1.0}),
 			},
 		},
 		{
-			suffix:  "cursor-vals-last-wins",
-			desc:    "cursor methods, last one wins",
-			comment: `When multiple Start* or End* calls occur, the values of the last one are used.`,
+			suffix:  "where-not-equal",
+			desc:    "a Where clause with !=",
+			comment: `The != operator results in a NOT_EQUAL filter.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`1`}}},
-				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`2`}}},
-				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`3`}}},
-				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`4`}}},
+				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `5`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(2)},
-					Before: true,
-				},
-				EndAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(4)},
-					Before: true,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_NOT_EQUAL, 5),
 			},
 		},
 		{
-			suffix:  "cursor-docsnap",
-			desc:    "cursor methods with a document snapshot",
-			comment: `When a document snapshot is used, the client appends a __name__ order-by clause.`,
+			suffix:  "where-not-equal-null",
+			desc:    "a Where clause with != null",
+			comment: "A Where clause that tests for inequality with null results in a unary filter.",
 			clauses: []interface{}{
-				&tpb.Clause_StartAt{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `null`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{docsnapRef},
-					Before: true,
-				},
+				Where: unaryFilter("a", fspb.StructuredQuery_UnaryFilter_IS_NOT_NULL),
 			},
 		},
 		{
-			suffix: "cursor-docsnap-order",
-			desc:   "cursor methods with a document snapshot, existing orderBy",
-			comment: `When a document snapshot is used, the client appends a __name__ order-by clause
-with the direction of the last order-by clause.`,
+			suffix:  "where-not-equal-NaN",
+			desc:    "a Where clause with != NaN",
+			comment: "A Where clause that tests for inequality with NaN results in a unary filter.",
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.OrderBy{Path: fp("b"), Direction: "desc"},
-				&tpb.Clause_StartAfter{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `"NaN"`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-					{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(7), val(8), docsnapRef},
-					Before: false,
-				},
+				Where: unaryFilter("a", fspb.StructuredQuery_UnaryFilter_IS_NOT_NAN),
 			},
 		},
 		{
-			suffix:  "cursor-docsnap-where-eq",
-			desc:    "cursor methods with a document snapshot and an equality where clause",
-			comment: `A Where clause using equality doesn't change the implicit orderBy clauses.`,
+			suffix:  "where-array-contains",
+			desc:    "a Where clause with array-contains",
+			comment: `The array-contains operator takes a single value.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `3`},
-				&tpb.Clause_EndAt{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "array-contains", JsonValue: `5`},
 			},
 			query: &fspb.StructuredQuery{
-				Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 3),
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				EndAt: &fspb.Cursor{
-					Values: []*fspb.Value{docsnapRef},
-					Before: false,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_ARRAY_CONTAINS, 5),
 			},
 		},
 		{
-			suffix: "cursor-docsnap-where-neq",
-			desc:   "cursor method with a document snapshot and an inequality where clause",
-			comment: `A Where clause with an inequality results in an OrderBy clause
-on that clause's path, if there are no other OrderBy clauses.`,
+			suffix:  "where-array-contains-any",
+			desc:    "a Where clause with array-contains-any",
+			comment: `The array-contains-any operator takes a list of values.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "<=", JsonValue: `3`},
-				&tpb.Clause_EndBefore{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "array-contains-any", JsonValue: `[1, "x"]`},
 			},
 			query: &fspb.StructuredQuery{
-				Where: filter("a", fspb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL, 3),
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				EndAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(7), docsnapRef},
-					Before: true,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_ARRAY_CONTAINS_ANY, []interface{}{1, "x"}),
 			},
 		},
 		{
-			suffix: "cursor-docsnap-where-neq-orderby",
-			desc:   "cursor method, doc snapshot, inequality where clause, and existing orderBy clause",
-			comment: `If there is an OrderBy clause, the inequality Where clause does
-not result in a new OrderBy clause. We still add a __name__ OrderBy clause`,
+			suffix:  "where-in",
+			desc:    "a Where clause with in",
+			comment: `The in operator takes a list of values.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
-				&tpb.Where{Path: fp("a"), Op: "<", JsonValue: `4`},
-				&tpb.Clause_StartAt{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: `[1, 2]`},
 			},
 			query: &fspb.StructuredQuery{
-				Where: filter("a", fspb.StructuredQuery_FieldFilter_LESS_THAN, 4),
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(7), docsnapRef},
-					Before: true,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_IN, []interface{}{1, 2}),
 			},
 		},
 		{
-			suffix: "cursor-docsnap-orderby-name",
-			desc:   "cursor method, doc snapshot, existing orderBy __name__",
-			comment: `If there is an existing orderBy clause on __name__,
-no changes are made to the list of orderBy clauses.`,
+			suffix:  "where-not-in",
+			desc:    "a Where clause with not-in",
+			comment: `The not-in operator takes a list of values.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
-				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
-				&tpb.Clause_StartAt{docsnap},
-				&tpb.Clause_EndAt{docsnap},
+				&tpb.Where{Path: fp("a"), Op: "not-in", JsonValue: `[1, 2]`},
 			},
 			query: &fspb.StructuredQuery{
-				OrderBy: []*fspb.StructuredQuery_Order{
-					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
-					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
-				},
-				StartAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(7), docsnapRef},
-					Before: true,
-				},
-				EndAt: &fspb.Cursor{
-					Values: []*fspb.Value{val(7), docsnapRef},
-					Before: false,
-				},
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_NOT_IN, []interface{}{1, 2}),
 			},
 		},
-		// Errors
 		{
-			suffix:  "invalid-operator",
-			desc:    "invalid operator in Where clause",
-			comment: "The !=  operator is not supported.",
+			suffix: "where-in-max",
+			desc:   "a Where clause with in and the maximum number of values",
+			comment: `The list of values of an in, not-in or array-contains-any filter may hold
+up to 30 values.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `4`},
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: jsonInts(30)},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_IN, ints(30)),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "invalid-path-select",
-			desc:    "invalid path in Where clause",
-			comment: "The path has an empty component.",
+			suffix:  "filter-or",
+			desc:    "an or filter",
+			comment: `A filter built with Filter.or results in a composite OR filter.`,
 			clauses: []interface{}{
-				&tpb.Select{Fields: []*tpb.FieldPath{{Field: []string{"*", ""}}}},
+				compositeFilter("or", whereFilter("a", "==", `1`), whereFilter("b", ">", `2`)),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_OR,
+					filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+					filter("b", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 2)),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "invalid-path-where",
-			desc:    "invalid path in Where clause",
-			comment: "The path has an empty component.",
+			suffix:  "filter-and",
+			desc:    "an and filter",
+			comment: `A filter built with Filter.and results in a composite AND filter.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: &tpb.FieldPath{Field: []string{"*", ""}}, Op: "==", JsonValue: `4`},
+				compositeFilter("and", whereFilter("a", "==", `1`), whereFilter("b", "==", `2`)),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_AND,
+					filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+					filter("b", fspb.StructuredQuery_FieldFilter_EQUAL, 2)),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "invalid-path-order",
-			desc:    "invalid path in OrderBy clause",
-			comment: "The path has an empty component.",
+			suffix: "filter-nested",
+			desc:   "nested composite filters",
+			comment: `Composite filters may be nested. Comparisons with null and NaN inside them
+result in unary filters, as in a Where clause.`,
 			clauses: []interface{}{
-				&tpb.OrderBy{Path: &tpb.FieldPath{Field: []string{"*", ""}}, Direction: "asc"},
+				compositeFilter("or",
+					whereFilter("a", "==", `1`),
+					compositeFilter("and", whereFilter("b", "==", `2`), whereFilter("c", "==", `null`))),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_OR,
+					filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+					composite(fspb.StructuredQuery_CompositeFilter_AND,
+						filter("b", fspb.StructuredQuery_FieldFilter_EQUAL, 2),
+						unaryFilter("c", fspb.StructuredQuery_UnaryFilter_IS_NULL))),
 			},
-			isErr: true,
 		},
 		{
-			suffix: "cursor-no-order",
-			desc:   "cursor method without orderBy",
-			comment: `If a cursor method with a list of values is provided, there must be at least as many
-explicit orderBy clauses as values.`,
+			suffix: "filter-where",
+			desc:   "a composite filter and a Where clause",
+			comment: `A composite filter is combined with the other Where clauses of the query
+into a composite AND filter, in the order of the clauses.`,
 			clauses: []interface{}{
-				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`2`}}},
+				&tpb.Where{Path: fp("c"), Op: ">", JsonValue: `3`},
+				compositeFilter("or", whereFilter("a", "==", `1`), whereFilter("b", "==", `2`)),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_AND,
+					filter("c", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 3),
+					composite(fspb.StructuredQuery_CompositeFilter_OR,
+						filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+						filter("b", fspb.StructuredQuery_FieldFilter_EQUAL, 2))),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "st-where",
-			desc:    "ServerTimestamp in Where",
-			comment: `Sentinel values are not permitted in queries.`,
+			suffix:  "filter-single",
+			desc:    "a composite filter with one filter",
+			comment: `A composite filter with a single filter is replaced by that filter.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `"ServerTimestamp"`},
+				compositeFilter("or", whereFilter("a", "==", `1`)),
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "del-where",
-			desc:    "Delete in Where",
-			comment: `Sentinel values are not permitted in queries.`,
+			suffix: "filter-single-nested",
+			desc:   "a composite filter with one composite filter",
+			comment: `A composite filter with a single filter is replaced by that filter, even if it
+is a composite filter itself.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `"Delete"`},
+				compositeFilter("and",
+					compositeFilter("or", whereFilter("a", "==", `1`), whereFilter("b", "==", `2`))),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_OR,
+					filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+					filter("b", fspb.StructuredQuery_FieldFilter_EQUAL, 2)),
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "st-cursor",
-			desc:    "ServerTimestamp in cursor method",
-			comment: `Sentinel values are not permitted in queries.`,
+			suffix: "filter-single-where",
+			desc:   "a composite filter with one filter and a Where clause",
+			comment: `A composite filter with a single filter is replaced by that filter before it is
+combined with the other Where clauses of the query.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("c"), Op: ">", JsonValue: `3`},
+				compositeFilter("and", whereFilter("a", "==", `1`)),
+			},
+			query: &fspb.StructuredQuery{
+				Where: composite(fspb.StructuredQuery_CompositeFilter_AND,
+					filter("c", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 3),
+					filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1)),
+			},
+		},
+		{
+			suffix:  "offset-limit",
+			desc:    "Offset and Limit clauses",
+			comment: `Offset and Limit clauses.`,
+			clauses: []interface{}{&tpb.Clause_Offset{2}, &tpb.Clause_Limit{3}},
+			query: &fspb.StructuredQuery{
+				Offset: 2,
+				Limit:  &wrappers.Int32Value{Value: 3},
+			},
+		},
+		{
+			suffix:  "offset-limit-last-wins",
+			desc:    "multiple Offset and Limit clauses",
+			comment: `With multiple Offset or Limit clauses, the last one wins.`,
+			clauses: []interface{}{
+				&tpb.Clause_Offset{2},
+				&tpb.Clause_Limit{3},
+				&tpb.Clause_Limit{4},
+				&tpb.Clause_Offset{5},
+			},
+			query: &fspb.StructuredQuery{
+				Offset: 5,
+				Limit:  &wrappers.Int32Value{Value: 4},
+			},
+		},
+		{
+			suffix: "limit-to-last",
+			desc:   "LimitToLast clause",
+			comment: `A LimitToLast clause is sent as a Limit on a query whose OrderBy clauses have
+their directions flipped. The client reverses the results.`,
 			clauses: []interface{}{
 				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"ServerTimestamp"`}}},
+				&tpb.OrderBy{Path: fp("b"), Direction: "desc"},
+				&tpb.Clause_LimitToLast{3},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				Limit: &wrappers.Int32Value{Value: 3},
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "del-cursor",
-			desc:    "Delete in cursor method",
-			comment: `Sentinel values are not permitted in queries.`,
+			suffix: "limit-to-last-cursors",
+			desc:   "LimitToLast clause with cursors",
+			comment: `With a LimitToLast clause, the start and end cursors are swapped, and each
+one is flipped between including and excluding its position.`,
 			clauses: []interface{}{
 				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
-				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"Delete"`}}},
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`1`}}},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`9`}}},
+				&tpb.Clause_LimitToLast{3},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(9)},
+					Before: false,
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(1)},
+					Before: false,
+				},
+				Limit: &wrappers.Int32Value{Value: 3},
 			},
-			isErr: true,
 		},
 		{
-			suffix: "wrong-collection",
-			desc:   "doc snapshot with wrong collection in cursor method",
-			comment: `If a document snapshot is passed to a Start*/End* method, it must be in the
-same collection as the query.`,
+			suffix: "limit-to-last-docsnap",
+			desc:   "LimitToLast clause with a document snapshot cursor",
+			comment: `The __name__ OrderBy clause that a document snapshot adds is flipped along with
+the others.`,
 			clauses: []interface{}{
-				&tpb.Clause_EndBefore{badDocsnap},
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_StartAfter{docsnap},
+				&tpb.Clause_LimitToLast{3},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: true,
+				},
+				Limit: &wrappers.Int32Value{Value: 3},
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "bad-null",
-			desc:    "where clause with non-== comparison with Null",
-			comment: `You can only compare Null for equality.`,
+			suffix:  "limit-to-last-then-limit",
+			desc:    "LimitToLast clause followed by a Limit clause",
+			comment: `Limit and LimitToLast replace each other; the last one wins.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `null`},
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_LimitToLast{3},
+				&tpb.Clause_Limit{4},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				Limit: &wrappers.Int32Value{Value: 4},
 			},
-			isErr: true,
 		},
 		{
-			suffix:  "bad-NaN",
-			desc:    "where clause with non-== comparison with NaN",
-			comment: `You can only compare NaN for equality.`,
+			suffix:  "order",
+			desc:    "basic OrderBy clauses",
+			comment: `Multiple OrderBy clauses combine.`,
 			clauses: []interface{}{
-				&tpb.Where{Path: fp("a"), Op: "<", JsonValue: `"NaN"`},
+				&tpb.OrderBy{Path: fp("b"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
 			},
-			isErr: true,
 		},
-	} {
-		var tclauses []*tpb.Clause
-		for _, c := range test.clauses {
-			tclauses = append(tclauses, toClause(c))
-		}
-		query := test.query
-		if query != nil {
-			query.From = []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}}
-		}
-		tp := &tpb.Test{
-			Description: "query: " + test.desc,
-			Test: &tpb.Test_Query{&tpb.QueryTest{
-				CollPath: collPath,
-				Clauses:  tclauses,
-				Query:    query,
-				IsError:  test.isErr,
-			}},
-		}
-		suite.Tests = append(suite.Tests, tp)
-		outputTestText(fmt.Sprintf("query-%s", test.suffix), test.comment, tp)
-	}
-}
-
-// A listenTest describes a series of Listen RPC responses that result in one or more snapshots.
-type listenTest struct {
-	suffix    string                 // textproto filename suffix
-	desc      string                 // short description
-	comment   string                 // detailed explanation (comment in textproto file)
-	responses []*fspb.ListenResponse // a sequence of responses sent over a Listen stream
-	snapshots []*tpb.Snapshot
-	isErr     bool // arguments result in a client-side error
-}
-
-func genListen(suite *tpb.TestSuite) {
-	current := &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-		TargetChangeType: fspb.TargetChange_CURRENT,
-	}}}
-	reset := &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-		TargetChangeType: fspb.TargetChange_RESET,
-	}}}
-
-	noChange := func(readTime *tspb.Timestamp) *fspb.ListenResponse {
-		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-			TargetChangeType: fspb.TargetChange_NO_CHANGE,
-			ReadTime:         readTime,
-		}}}
-	}
-
-	change := func(doc *fspb.Document) *fspb.ListenResponse {
-		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{&fspb.DocumentChange{
-			Document:  doc,
-			TargetIds: []int32{watchTargetID},
-		}}}
-	}
-
-	del := func(path string) *fspb.ListenResponse {
-		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentDelete{&fspb.DocumentDelete{
-			Document: collPath + "/" + path,
-		}}}
-	}
-
-	filter := func(count int) *fspb.ListenResponse {
-		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_Filter{&fspb.ExistenceFilter{
-			Count: int32(count),
-		}}}
-	}
-
-	ts := func(secs int) *tspb.Timestamp {
-		return &tspb.Timestamp{Seconds: int64(secs)}
-	}
-
+		{
+			suffix:  "cursor-vals-1a",
+			desc:    "StartAt/EndBefore with values",
+			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`7`}}},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`9`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7)}, Before: true},
+				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9)}, Before: true},
+			},
+		},
+		{
+			suffix:  "cursor-vals-1b",
+			desc:    "StartAfter/EndAt with values",
+			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`7`}}},
+				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`9`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7)}, Before: false},
+				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9)}, Before: false},
+			},
+		},
+		{
+			suffix:  "cursor-vals-2",
+			desc:    "Start/End with two values",
+			comment: `Cursor methods take the same number of values as there are OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("b"), Direction: "desc"},
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`7`, `8`}}},
+				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`9`, `10`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+				StartAt: &fspb.Cursor{Values: []*fspb.Value{val(7), val(8)}, Before: true},
+				EndAt:   &fspb.Cursor{Values: []*fspb.Value{val(9), val(10)}, Before: false},
+			},
+		},
+		{
+			suffix: "cursor-vals-docid",
+			desc:   "cursor methods with __name__",
+			comment: `Cursor values corresponding to a __name__ field take the document path relative to the
+query's collection.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`"D1"`}}},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"D2"`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{refval(collPath + "/D1")},
+					Before: false,
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{refval(collPath + "/D2")},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix:  "cursor-vals-last-wins",
+			desc:    "cursor methods, last one wins",
+			comment: `When multiple Start* or End* calls occur, the values of the last one are used.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`1`}}},
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`2`}}},
+				&tpb.Clause_EndAt{&tpb.Cursor{JsonValues: []string{`3`}}},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`4`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(2)},
+					Before: true,
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(4)},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix:  "cursor-vals-values",
+			desc:    "cursor methods with values of several kinds",
+			comment: `Cursor values use the same encoding as document data.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("b"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("c"), Direction: "asc"},
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{
+					testTimeJSON, testGeoPointJSON, `{"$bytes": "AQID"}`,
+				}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("c"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(testTime), val(testGeoPoint), val([]byte{1, 2, 3})},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix:  "cursor-docsnap-values",
+			desc:    "cursor methods with a document snapshot holding values of several kinds",
+			comment: `The data of a document snapshot uses the same encoding as other document data.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("b"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{DocSnapshot: &tpb.DocSnapshot{
+					Path:     collPath + "/D",
+					JsonData: `{"a": ` + testTimeJSON + `, "b": {"$reference": "` + collPath + `/d2"}}`,
+				}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(testTime), refval(collPath + "/d2"), docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix:  "cursor-docsnap",
+			desc:    "cursor methods with a document snapshot",
+			comment: `When a document snapshot is used, the client appends a __name__ order-by clause.`,
+			clauses: []interface{}{
+				&tpb.Clause_StartAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{docsnapRef},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-order",
+			desc:   "cursor methods with a document snapshot, existing orderBy",
+			comment: `When a document snapshot is used, the client appends a __name__ order-by clause
+with the direction of the last order-by clause.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("b"), Direction: "desc"},
+				&tpb.Clause_StartAfter{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), val(8), docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix:  "cursor-docsnap-where-eq",
+			desc:    "cursor methods with a document snapshot and an equality where clause",
+			comment: `A Where clause using equality doesn't change the implicit orderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `3`},
+				&tpb.Clause_EndAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 3),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-where-neq",
+			desc:   "cursor method with a document snapshot and an inequality where clause",
+			comment: `A Where clause with an inequality results in an OrderBy clause
+on that clause's path, if there are no other OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "<=", JsonValue: `3`},
+				&tpb.Clause_EndBefore{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL, 3),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-where-neq-orderby",
+			desc:   "cursor method, doc snapshot, inequality where clause, and existing orderBy clause",
+			comment: `If there is an OrderBy clause, the inequality Where clause does
+not result in a new OrderBy clause. We still add a __name__ OrderBy clause`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+				&tpb.Where{Path: fp("a"), Op: "<", JsonValue: `4`},
+				&tpb.Clause_StartAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_LESS_THAN, 4),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-where-not-equal",
+			desc:   "cursor method with a document snapshot and a != where clause",
+			comment: `A != Where clause is an inequality, so it results in an OrderBy clause
+on that clause's path, if there are no other OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `3`},
+				&tpb.Clause_StartAfter{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_NOT_EQUAL, 3),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-where-not-in",
+			desc:   "cursor method with a document snapshot and a not-in where clause",
+			comment: `A not-in Where clause is an inequality, so it results in an OrderBy clause
+on that clause's path, if there are no other OrderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "not-in", JsonValue: `[1, 2]`},
+				&tpb.Clause_EndAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_NOT_IN, []interface{}{1, 2}),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-where-in",
+			desc:   "cursor method with a document snapshot and an in where clause",
+			comment: `In, array-contains and array-contains-any Where clauses are not inequalities,
+so they don't change the implicit orderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: `[1, 2]`},
+				&tpb.Where{Path: fp("b"), Op: "array-contains", JsonValue: `3`},
+				&tpb.Clause_StartAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				Where: &fspb.StructuredQuery_Filter{
+					FilterType: &fspb.StructuredQuery_Filter_CompositeFilter{
+						CompositeFilter: &fspb.StructuredQuery_CompositeFilter{
+							Op: fspb.StructuredQuery_CompositeFilter_AND,
+							Filters: []*fspb.StructuredQuery_Filter{
+								filter("a", fspb.StructuredQuery_FieldFilter_IN, []interface{}{1, 2}),
+								filter("b", fspb.StructuredQuery_FieldFilter_ARRAY_CONTAINS, 3),
+							},
+						},
+					},
+				},
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{docsnapRef},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix: "cursor-docsnap-orderby-name",
+			desc:   "cursor method, doc snapshot, existing orderBy __name__",
+			comment: `If there is an existing orderBy clause on __name__,
+no changes are made to the list of orderBy clauses.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
+				&tpb.Clause_StartAt{docsnap},
+				&tpb.Clause_EndAt{docsnap},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: true,
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{val(7), docsnapRef},
+					Before: false,
+				},
+			},
+		},
+		// Errors
+		{
+			suffix:  "invalid-path-select",
+			desc:    "invalid path in Where clause",
+			comment: "The path has an empty component.",
+			clauses: []interface{}{
+				&tpb.Select{Fields: []*tpb.FieldPath{{Field: []string{"*", ""}}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "invalid-path-where",
+			desc:    "invalid path in Where clause",
+			comment: "The path has an empty component.",
+			clauses: []interface{}{
+				&tpb.Where{Path: &tpb.FieldPath{Field: []string{"*", ""}}, Op: "==", JsonValue: `4`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "invalid-path-order",
+			desc:    "invalid path in OrderBy clause",
+			comment: "The path has an empty component.",
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: &tpb.FieldPath{Field: []string{"*", ""}}, Direction: "asc"},
+			},
+			isErr: true,
+		},
+		{
+			suffix: "cursor-no-order",
+			desc:   "cursor method without orderBy",
+			comment: `If a cursor method with a list of values is provided, there must be at least as many
+explicit orderBy clauses as values.`,
+			clauses: []interface{}{
+				&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{`2`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "st-where",
+			desc:    "ServerTimestamp in Where",
+			comment: `Sentinel values are not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `"ServerTimestamp"`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "del-where",
+			desc:    "Delete in Where",
+			comment: `Sentinel values are not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `"Delete"`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "st-cursor",
+			desc:    "ServerTimestamp in cursor method",
+			comment: `Sentinel values are not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"ServerTimestamp"`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "del-cursor",
+			desc:    "Delete in cursor method",
+			comment: `Sentinel values are not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`"Delete"`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "arrayunion-where",
+			desc:    "ArrayUnion in Where",
+			comment: `ArrayUnion is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `["ArrayUnion", 1, 2, 3]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "arrayremove-where",
+			desc:    "ArrayRemove in Where",
+			comment: `ArrayRemove is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `["ArrayRemove", 1, 2, 3]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "arrayunion-cursor",
+			desc:    "ArrayUnion in cursor method",
+			comment: `ArrayUnion is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`["ArrayUnion", 1, 2, 3]`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "arrayremove-cursor",
+			desc:    "ArrayRemove in cursor method",
+			comment: `ArrayRemove is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`["ArrayRemove", 1, 2, 3]`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "increment-where",
+			desc:    "Increment in Where",
+			comment: `Increment is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `["Increment", 1]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "increment-cursor",
+			desc:    "Increment in cursor method",
+			comment: `Increment is not permitted in queries.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_EndBefore{&tpb.Cursor{JsonValues: []string{`["Increment", 1]`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix: "wrong-collection",
+			desc:   "doc snapshot with wrong collection in cursor method",
+			comment: `If a document snapshot is passed to a Start*/End* method, it must be in the
+same collection as the query.`,
+			clauses: []interface{}{
+				&tpb.Clause_EndBefore{badDocsnap},
+			},
+			isErr: true,
+		},
+		{
+			suffix:          "collection-group",
+			desc:            "collection group query",
+			comment:         `A collection group query is over every collection with the given ID.`,
+			collectionGroup: true,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `5`},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 5),
+			},
+		},
+		{
+			suffix: "collection-group-docsnap",
+			desc:   "collection group query with document snapshots in different collections",
+			comment: `The document snapshots passed to the Start*/End* methods of a collection group
+query may be in any collection with the group's ID, including subcollections.`,
+			collectionGroup: true,
+			clauses: []interface{}{
+				&tpb.Clause_StartAt{&tpb.Cursor{DocSnapshot: &tpb.DocSnapshot{
+					Path:     database + "/documents/A/a/C/D1",
+					JsonData: `{"a": 7}`,
+				}}},
+				&tpb.Clause_EndBefore{&tpb.Cursor{DocSnapshot: &tpb.DocSnapshot{
+					Path:     database + "/documents/B/b/C/D2",
+					JsonData: `{"a": 8}`,
+				}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{refval(database + "/documents/A/a/C/D1")},
+					Before: true,
+				},
+				EndAt: &fspb.Cursor{
+					Values: []*fspb.Value{refval(database + "/documents/B/b/C/D2")},
+					Before: true,
+				},
+			},
+		},
+		{
+			suffix: "collection-group-wrong-collection",
+			desc:   "collection group query with doc snapshot in a collection with another ID",
+			comment: `If a document snapshot is passed to a Start*/End* method of a collection group
+query, its collection must have the group's ID.`,
+			collectionGroup: true,
+			clauses: []interface{}{
+				&tpb.Clause_EndBefore{badDocsnap},
+			},
+			isErr: true,
+		},
+		{
+			suffix: "collection-group-vals-docid",
+			desc:   "collection group query with __name__ cursor values",
+			comment: `In a collection group query, cursor values corresponding to a __name__ field
+take the document path relative to the root of the database.`,
+			collectionGroup: true,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`"A/a/C/D1"`}}},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				StartAt: &fspb.Cursor{
+					Values: []*fspb.Value{refval(database + "/documents/A/a/C/D1")},
+					Before: false,
+				},
+			},
+		},
+		{
+			suffix: "collection-group-vals-docid-bad",
+			desc:   "collection group query with a document ID as a __name__ cursor value",
+			comment: `In a collection group query, a cursor value corresponding to a __name__ field
+must be a document path, not just a document ID.`,
+			collectionGroup: true,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("__name__"), Direction: "asc"},
+				&tpb.Clause_StartAfter{&tpb.Cursor{JsonValues: []string{`"D1"`}}},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "in-not-list",
+			desc:    "in with a value that is not a list",
+			comment: `The value of an in Where clause must be a list.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: `5`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "not-in-not-list",
+			desc:    "not-in with a value that is not a list",
+			comment: `The value of a not-in Where clause must be a list.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "not-in", JsonValue: `"x"`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "array-contains-any-not-list",
+			desc:    "array-contains-any with a value that is not a list",
+			comment: `The value of an array-contains-any Where clause must be a list.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "array-contains-any", JsonValue: `{"b": 1}`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "in-empty",
+			desc:    "in with an empty list",
+			comment: `The list of values of an in Where clause must not be empty.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: `[]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "in-too-many",
+			desc:    "in with too many values",
+			comment: `The list of values of an in Where clause may hold at most 30 values.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "in", JsonValue: jsonInts(31)},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "array-contains-any-too-many",
+			desc:    "array-contains-any with too many values",
+			comment: `The list of values of an array-contains-any Where clause may hold at most 30 values.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "array-contains-any", JsonValue: jsonInts(31)},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "not-in-not-equal",
+			desc:    "not-in and != in the same query",
+			comment: `A query cannot have both a not-in and a != Where clause.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "!=", JsonValue: `1`},
+				&tpb.Where{Path: fp("b"), Op: "not-in", JsonValue: `[2, 3]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "not-in-twice",
+			desc:    "two not-in Where clauses",
+			comment: `A query can have at most one not-in Where clause.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "not-in", JsonValue: `[1]`},
+				&tpb.Where{Path: fp("b"), Op: "not-in", JsonValue: `[2]`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "filter-empty",
+			desc:    "an empty composite filter",
+			comment: `A composite filter must have at least one filter.`,
+			clauses: []interface{}{compositeFilter("or")},
+			isErr:   true,
+		},
+		{
+			suffix:  "filter-empty-nested",
+			desc:    "an empty composite filter inside another",
+			comment: `A composite filter must have at least one filter, even when it is nested.`,
+			clauses: []interface{}{
+				compositeFilter("and", whereFilter("a", "==", `1`), compositeFilter("or")),
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "filter-invalid-op",
+			desc:    "a composite filter with an invalid operator",
+			comment: `The operator of a composite filter must be "and" or "or".`,
+			clauses: []interface{}{
+				compositeFilter("xor", whereFilter("a", "==", `1`), whereFilter("b", "==", `2`)),
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "filter-invalid-where",
+			desc:    "a composite filter with an invalid condition",
+			comment: `The conditions in a composite filter are checked as in a Where clause.`,
+			clauses: []interface{}{
+				compositeFilter("or", whereFilter("a", "==", `1`), whereFilter("b", ">", `null`)),
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "limit-to-last-no-order",
+			desc:    "LimitToLast clause without an OrderBy clause",
+			comment: `A query with a LimitToLast clause must have at least one OrderBy clause.`,
+			clauses: []interface{}{&tpb.Clause_LimitToLast{3}},
+			isErr:   true,
+		},
+		{
+			suffix:  "bad-null",
+			desc:    "where clause with non-== comparison with Null",
+			comment: `You can only compare Null for equality.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `null`},
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "bad-NaN",
+			desc:    "where clause with non-== comparison with NaN",
+			comment: `You can only compare NaN for equality.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: "<", JsonValue: `"NaN"`},
+			},
+			isErr: true,
+		},
+	} {
+		var tclauses []*tpb.Clause
+		for _, c := range test.clauses {
+			tclauses = append(tclauses, toClause(c))
+		}
+		query := test.query
+		if query != nil {
+			query.From = []*fspb.StructuredQuery_CollectionSelector{{
+				CollectionId:   "C",
+				AllDescendants: test.collectionGroup,
+			}}
+		}
+		tp := &tpb.Test{
+			Description: "query: " + test.desc,
+			Test: &tpb.Test_Query{&tpb.QueryTest{
+				CollPath:        collPath,
+				Clauses:         tclauses,
+				Query:           query,
+				IsError:         test.isErr,
+				CollectionGroup: test.collectionGroup,
+			}},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(fmt.Sprintf("query-%s", test.suffix), test.comment, tp)
+	}
+
+	// Operators are case-sensitive, and only those listed in Where.op are valid.
+	for _, test := range []struct {
+		suffix string
+		op     string
+	}{
+		{"ne", "<>"},
+		{"eq", "="},
+		{"contains", "contains"},
+		{"uppercase", "IN"},
+		{"underscore", "array_contains"},
+	} {
+		tp := &tpb.Test{
+			Description: fmt.Sprintf("query: invalid operator %q in Where clause", test.op),
+			Test: &tpb.Test_Query{&tpb.QueryTest{
+				CollPath: collPath,
+				Clauses:  []*tpb.Clause{toClause(&tpb.Where{Path: fp("a"), Op: test.op, JsonValue: `4`})},
+				IsError:  true,
+			}},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText("query-invalid-operator-"+test.suffix,
+			fmt.Sprintf("The %q operator is not supported.", test.op), tp)
+	}
+}
+
+// jsonInts returns a JSON list of the integers 1 through n.
+func jsonInts(n int) string {
+	var ss []string
+	for i := 1; i <= n; i++ {
+		ss = append(ss, fmt.Sprint(i))
+	}
+	return "[" + strings.Join(ss, ", ") + "]"
+}
+
+// ints returns a list of the integers 1 through n, for val.
+func ints(n int) []interface{} {
+	var is []interface{}
+	for i := 1; i <= n; i++ {
+		is = append(is, i)
+	}
+	return is
+}
+
+// A listenTest describes a series of Listen RPC responses that result in one or more snapshots.
+func genQueryResults(suite *tpb.TestSuite) {
+	ts := func(secs int) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: int64(secs)}
+	}
+
+	doc := func(path string, aval int) *fspb.Document {
+		return &fspb.Document{
+			Name:       collPath + "/" + path,
+			Fields:     mp("a", aval),
+			CreateTime: ts(1),
+			UpdateTime: ts(1),
+		}
+	}
+
+	result := func(doc *fspb.Document, readTime *tspb.Timestamp) *fspb.RunQueryResponse {
+		return &fspb.RunQueryResponse{Document: doc, ReadTime: readTime}
+	}
+
+	snap := func(doc *fspb.Document, readTime *tspb.Timestamp) *tpb.DocumentSnapshot {
+		return &tpb.DocumentSnapshot{Doc: doc, ReadTime: readTime}
+	}
+
+	doc1 := doc("d1", 1)
+	doc2 := doc("d2", 2)
+	doc3 := doc("d3", 3)
+
+	for _, test := range []struct {
+		suffix    string
+		desc      string
+		comment   string
+		clauses   []interface{}
+		responses []*fspb.RunQueryResponse
+		snapshots []*tpb.DocumentSnapshot
+		isErr     bool
+	}{
+		{
+			suffix:    "basic",
+			desc:      "two documents",
+			comment:   `Each response holding a document produces a snapshot of it.`,
+			responses: []*fspb.RunQueryResponse{result(doc1, ts(2)), result(doc2, ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), snap(doc2, ts(2))},
+		},
+		{
+			suffix: "empty",
+			desc:   "no documents",
+			comment: `A query that matches no documents receives a single response with only a read time.
+It produces no snapshots.`,
+			responses: []*fspb.RunQueryResponse{result(nil, ts(2))},
+			snapshots: nil,
+		},
+		{
+			suffix: "read-time",
+			desc:   "read times differ between responses",
+			comment: `Each snapshot has the read time of the response that holds its document. A response
+with only a read time produces no snapshot.`,
+			responses: []*fspb.RunQueryResponse{result(nil, ts(1)), result(doc1, ts(2)), result(doc2, ts(3))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2)), snap(doc2, ts(3))},
+		},
+		{
+			suffix: "skipped",
+			desc:   "skipped results",
+			comment: `With an offset, the service may report the number of results it skipped in a
+response without a document. That response produces no snapshot.`,
+			clauses: []interface{}{&tpb.Clause_Offset{2}},
+			responses: []*fspb.RunQueryResponse{
+				{SkippedResults: 2, ReadTime: ts(2)},
+				result(doc3, ts(2)),
+			},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc3, ts(2))},
+		},
+		{
+			suffix: "limit-to-last",
+			desc:   "LimitToLast clause",
+			comment: `The service returns the results of a query with a LimitToLast clause in the
+flipped order, so the client reverses them.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_LimitToLast{2},
+			},
+			responses: []*fspb.RunQueryResponse{result(doc3, ts(2)), result(doc2, ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc2, ts(2)), snap(doc3, ts(2))},
+		},
+		{
+			suffix: "transaction",
+			desc:   "response with a transaction ID",
+			comment: `A response may carry a transaction ID without a document. It produces no
+snapshot, and the documents that follow are unaffected.`,
+			responses: []*fspb.RunQueryResponse{
+				{Transaction: []byte("transaction-1"), ReadTime: ts(2)},
+				result(doc1, ts(2)),
+			},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2))},
+		},
+		{
+			suffix: "error",
+			desc:   "error after a document",
+			comment: `If the stream fails after some documents, the query produces their snapshots
+and then signals an error.`,
+			responses: []*fspb.RunQueryResponse{result(doc1, ts(2))},
+			snapshots: []*tpb.DocumentSnapshot{snap(doc1, ts(2))},
+			isErr:     true,
+		},
+		{
+			suffix:    "error-first",
+			desc:      "error before any responses",
+			comment:   `If the stream fails before any responses, the query signals an error.`,
+			responses: nil,
+			snapshots: nil,
+			isErr:     true,
+		},
+	} {
+		var tclauses []*tpb.Clause
+		for _, c := range test.clauses {
+			tclauses = append(tclauses, toClause(c))
+		}
+		tp := &tpb.Test{
+			Description: "query results: " + test.desc,
+			Test: &tpb.Test_QueryResults{&tpb.QueryResultsTest{
+				CollPath:  collPath,
+				Clauses:   tclauses,
+				Responses: test.responses,
+				Snapshots: test.snapshots,
+				IsError:   test.isErr,
+			}},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(fmt.Sprintf("query-results-%s", test.suffix), test.comment, tp)
+	}
+}
+
+func genAggregationQuery(suite *tpb.TestSuite) {
+	ts := func(secs int) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: int64(secs)}
+	}
+
+	countAgg := func(alias string, upTo int64) *tpb.Aggregation {
+		return &tpb.Aggregation{Alias: alias, Aggregation: &tpb.Aggregation_Count{&tpb.Count{UpTo: upTo}}}
+	}
+	sumAgg := func(alias string, field *tpb.FieldPath) *tpb.Aggregation {
+		return &tpb.Aggregation{Alias: alias, Aggregation: &tpb.Aggregation_Sum{field}}
+	}
+	avgAgg := func(alias string, field *tpb.FieldPath) *tpb.Aggregation {
+		return &tpb.Aggregation{Alias: alias, Aggregation: &tpb.Aggregation_Avg{field}}
+	}
+
+	count := func(alias string, upTo int64) *fspb.StructuredAggregationQuery_Aggregation {
+		c := &fspb.StructuredAggregationQuery_Aggregation_Count{}
+		if upTo > 0 {
+			c.UpTo = &wrappers.Int64Value{Value: upTo}
+		}
+		return &fspb.StructuredAggregationQuery_Aggregation{
+			Alias:    alias,
+			Operator: &fspb.StructuredAggregationQuery_Aggregation_Count_{c},
+		}
+	}
+	sum := func(alias, field string) *fspb.StructuredAggregationQuery_Aggregation {
+		return &fspb.StructuredAggregationQuery_Aggregation{
+			Alias:    alias,
+			Operator: &fspb.StructuredAggregationQuery_Aggregation_Sum_{&fspb.StructuredAggregationQuery_Aggregation_Sum{Field: fref(field)}},
+		}
+	}
+	avg := func(alias, field string) *fspb.StructuredAggregationQuery_Aggregation {
+		return &fspb.StructuredAggregationQuery_Aggregation{
+			Alias:    alias,
+			Operator: &fspb.StructuredAggregationQuery_Aggregation_Avg_{&fspb.StructuredAggregationQuery_Aggregation_Avg{Field: fref(field)}},
+		}
+	}
+
+	result := func(fields map[string]*fspb.Value, readTime *tspb.Timestamp) *fspb.RunAggregationQueryResponse {
+		return &fspb.RunAggregationQueryResponse{
+			Result:   &fspb.AggregationResult{AggregateFields: fields},
+			ReadTime: readTime,
+		}
+	}
+
+	null := &fspb.Value{ValueType: &fspb.Value_NullValue{}}
+
+	for _, test := range []struct {
+		suffix    string
+		desc      string
+		comment   string
+		clauses   []interface{}
+		aggs      []*tpb.Aggregation
+		query     *fspb.StructuredQuery // expected base query, without From
+		wantAggs  []*fspb.StructuredAggregationQuery_Aggregation
+		responses []*fspb.RunAggregationQueryResponse
+		result    map[string]*fspb.Value
+		isErr     bool
+	}{
+		{
+			suffix:    "count",
+			desc:      "count",
+			comment:   `A count of the documents that match a query.`,
+			aggs:      []*tpb.Aggregation{countAgg("total", 0)},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{count("total", 0)},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("total", 3), ts(2))},
+			result:    mp("total", 3),
+		},
+		{
+			suffix:    "count-up-to",
+			desc:      "count with a maximum",
+			comment:   `A count may stop at a maximum number of documents.`,
+			aggs:      []*tpb.Aggregation{countAgg("total", 10)},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{count("total", 10)},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("total", 10), ts(2))},
+			result:    mp("total", 10),
+		},
+		{
+			suffix:    "sum",
+			desc:      "sum",
+			comment:   `The sum of a field. A sum of integers is an integer.`,
+			aggs:      []*tpb.Aggregation{sumAgg("s", fp("a"))},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{sum("s", "a")},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("s", 6), ts(2))},
+			result:    mp("s", 6),
+		},
+		{
+			suffix:    "sum-double",
+			desc:      "sum of doubles",
+			comment:   `A sum that involves a double is a double, even if it has no fractional part.`,
+			aggs:      []*tpb.Aggregation{sumAgg("s", fp("a"))},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{sum("s", "a")},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("s", 6.0), ts(2))},
+			result:    mp("s", 6.0),
+		},
+		{
+			suffix:    "avg",
+			desc:      "average",
+			comment:   `The average of a field, which is always a double.`,
+			aggs:      []*tpb.Aggregation{avgAgg("m", fp("a"))},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{avg("m", "a")},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("m", 2.5), ts(2))},
+			result:    mp("m", 2.5),
+		},
+		{
+			suffix:    "avg-null",
+			desc:      "average of no values",
+			comment:   `The average of a field that no document has as a number is null.`,
+			aggs:      []*tpb.Aggregation{avgAgg("m", fp("a"))},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{avg("m", "a")},
+			responses: []*fspb.RunAggregationQueryResponse{result(map[string]*fspb.Value{"m": null}, ts(2))},
+			result:    map[string]*fspb.Value{"m": null},
+		},
+		{
+			suffix: "multi",
+			desc:   "several aggregations of a query with clauses",
+			comment: `Several aggregations may be computed together over a query with any clauses.
+Field paths are encoded as in the query.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `1`},
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.Clause_Limit{100},
+			},
+			aggs: []*tpb.Aggregation{
+				countAgg("n", 0),
+				sumAgg("s", &tpb.FieldPath{Field: []string{"b", "c"}}),
+				avgAgg("m", fp("x.y")),
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 1),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+				Limit: &wrappers.Int32Value{Value: 100},
+			},
+			wantAggs: []*fspb.StructuredAggregationQuery_Aggregation{
+				count("n", 0),
+				sum("s", "b.c"),
+				avg("m", "`x.y`"),
+			},
+			responses: []*fspb.RunAggregationQueryResponse{result(mp("n", 4, "s", 10, "m", 2.5), ts(2))},
+			result:    mp("n", 4, "s", 10, "m", 2.5),
+		},
+		{
+			suffix:    "read-time",
+			desc:      "a response without a result",
+			comment:   `A response that only reports a read time holds no result, and is ignored.`,
+			aggs:      []*tpb.Aggregation{countAgg("total", 0)},
+			query:     &fspb.StructuredQuery{},
+			wantAggs:  []*fspb.StructuredAggregationQuery_Aggregation{count("total", 0)},
+			responses: []*fspb.RunAggregationQueryResponse{{ReadTime: ts(1)}, result(mp("total", 0), ts(2))},
+			result:    mp("total", 0),
+		},
+		{
+			suffix:  "duplicate-alias",
+			desc:    "two aggregations with the same alias",
+			comment: `The aliases of the aggregations in a query must be distinct.`,
+			aggs:    []*tpb.Aggregation{countAgg("x", 0), sumAgg("x", fp("a"))},
+			isErr:   true,
+		},
+		{
+			suffix:  "too-many",
+			desc:    "too many aggregations",
+			comment: `A query may have at most five aggregations.`,
+			aggs: []*tpb.Aggregation{
+				countAgg("a1", 0), countAgg("a2", 0), countAgg("a3", 0),
+				sumAgg("a4", fp("a")), sumAgg("a5", fp("b")), avgAgg("a6", fp("c")),
+			},
+			isErr: true,
+		},
+	} {
+		var tclauses []*tpb.Clause
+		for _, c := range test.clauses {
+			tclauses = append(tclauses, toClause(c))
+		}
+		var query *fspb.StructuredAggregationQuery
+		if !test.isErr {
+			test.query.From = []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}}
+			query = &fspb.StructuredAggregationQuery{
+				QueryType:    &fspb.StructuredAggregationQuery_StructuredQuery{test.query},
+				Aggregations: test.wantAggs,
+			}
+		}
+		tp := &tpb.Test{
+			Description: "aggregation query: " + test.desc,
+			Test: &tpb.Test_AggregationQuery{&tpb.AggregationQueryTest{
+				CollPath:     collPath,
+				Clauses:      tclauses,
+				Aggregations: test.aggs,
+				Query:        query,
+				Responses:    test.responses,
+				Result:       test.result,
+				IsError:      test.isErr,
+			}},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(fmt.Sprintf("aggregation-query-%s", test.suffix), test.comment, tp)
+	}
+}
+
+func genObject(suite *tpb.TestSuite) {
+	scalar := func(name string) *tpb.ObjectType {
+		return &tpb.ObjectType{Type: &tpb.ObjectType_Scalar{name}}
+	}
+	nullable := func(t *tpb.ObjectType) *tpb.ObjectType {
+		return &tpb.ObjectType{Type: &tpb.ObjectType_Nullable{t}}
+	}
+	arrayOf := func(t *tpb.ObjectType) *tpb.ObjectType {
+		return &tpb.ObjectType{Type: &tpb.ObjectType_Array{t}}
+	}
+	mapOf := func(t *tpb.ObjectType) *tpb.ObjectType {
+		return &tpb.ObjectType{Type: &tpb.ObjectType_Map{t}}
+	}
+	object := func(fields ...*tpb.ObjectField) *tpb.ObjectType {
+		return &tpb.ObjectType{Type: &tpb.ObjectType_Object{&tpb.ObjectSchema{Fields: fields}}}
+	}
+	field := func(name string, t *tpb.ObjectType) *tpb.ObjectField {
+		return &tpb.ObjectField{Name: name, Type: t}
+	}
+	schema := func(fields ...*tpb.ObjectField) *tpb.ObjectSchema {
+		return &tpb.ObjectSchema{Fields: fields}
+	}
+
+	omitEmptySchema := schema(
+		&tpb.ObjectField{Name: "a", Type: scalar("int"), OmitEmpty: true},
+		&tpb.ObjectField{Name: "b", Type: scalar("string"), OmitEmpty: true},
+		&tpb.ObjectField{Name: "c", Type: arrayOf(scalar("int")), OmitEmpty: true},
+		&tpb.ObjectField{Name: "d", Type: nullable(scalar("string")), OmitEmpty: true},
+		field("e", scalar("int")),
+	)
+	serverTimestamp := &tpb.ObjectField{Name: "t", Type: nullable(scalar("timestamp")), ServerTimestamp: true}
+
+	for _, test := range []struct {
+		suffix           string
+		desc             string
+		comment          string
+		schema           *tpb.ObjectSchema
+		object           map[string]*fspb.Value
+		data             map[string]*fspb.Value
+		serverTimestamps []*tpb.FieldPath
+		encodeOnly       bool // test only encoding
+		decodeOnly       bool // test only decoding
+		isErr            bool
+	}{
+		{
+			suffix:  "basic",
+			desc:    "basic",
+			comment: `Each field of an object is stored in the document field of the same name.`,
+			schema: schema(
+				field("a", scalar("bool")),
+				field("b", scalar("int")),
+				field("c", scalar("double")),
+				field("d", scalar("string")),
+			),
+			object: mp("a", true, "b", 1, "c", 2.5, "d", "x"),
+			data:   mp("a", true, "b", 1, "c", 2.5, "d", "x"),
+		},
+		{
+			suffix:  "types",
+			desc:    "fields of every scalar type",
+			comment: `Fields of the scalar types without a JSON equivalent.`,
+			schema: schema(
+				field("a", scalar("bytes")),
+				field("b", scalar("timestamp")),
+				field("c", scalar("geopoint")),
+				field("d", scalar("reference")),
+			),
+			object: mp("a", []byte{1, 2, 3}, "b", testTime, "c", testGeoPoint, "d", refval(collPath+"/d2")),
+			data:   mp("a", []byte{1, 2, 3}, "b", testTime, "c", testGeoPoint, "d", refval(collPath+"/d2")),
+		},
+		{
+			suffix:  "rename",
+			desc:    "a field stored under another name",
+			comment: `A field with a Firestore name is stored in the document field of that name.`,
+			schema: schema(
+				&tpb.ObjectField{Name: "A", Type: scalar("int"), FirestoreName: "a"},
+				field("b", scalar("int")),
+			),
+			object: mp("A", 1, "b", 2),
+			data:   mp("a", 1, "b", 2),
+		},
+		{
+			suffix: "omit-empty",
+			desc:   "omit-empty fields with zero values",
+			comment: `An omit-empty field that holds its zero value is not stored. Decoding a
+document without the field leaves it with its zero value.`,
+			schema: omitEmptySchema,
+			object: mp("a", 0, "b", "", "c", []interface{}{}, "d", nil, "e", 0),
+			data:   mp("e", 0),
+		},
+		{
+			suffix:  "omit-empty-set",
+			desc:    "omit-empty fields with values",
+			comment: `An omit-empty field that holds a value other than its zero value is stored as usual.`,
+			schema:  omitEmptySchema,
+			object:  mp("a", 1, "b", "x", "c", []interface{}{2}, "d", "y", "e", 3),
+			data:    mp("a", 1, "b", "x", "c", []interface{}{2}, "d", "y", "e", 3),
+		},
+		{
+			suffix:  "nullable",
+			desc:    "nullable fields",
+			comment: `A nullable field holding null is stored as a null value.`,
+			schema: schema(
+				field("a", nullable(scalar("int"))),
+				field("b", nullable(scalar("int"))),
+				field("c", nullable(object(field("x", scalar("int"))))),
+			),
+			object: mp("a", nil, "b", 5, "c", mp("x", 1)),
+			data:   mp("a", nil, "b", 5, "c", mp("x", 1)),
+		},
+		{
+			suffix:  "nested",
+			desc:    "nested objects",
+			comment: `A nested object is stored as a map, with its own field annotations.`,
+			schema: schema(
+				field("a", object(
+					&tpb.ObjectField{Name: "B", Type: scalar("int"), FirestoreName: "b"},
+					field("c", object(field("d", scalar("string")))),
+				)),
+			),
+			object: mp("a", mp("B", 1, "c", mp("d", "x"))),
+			data:   mp("a", mp("b", 1, "c", mp("d", "x"))),
+		},
+		{
+			suffix:  "array-map",
+			desc:    "arrays and maps",
+			comment: `Arrays are stored as arrays and maps as maps. An object in an array is stored as a map.`,
+			schema: schema(
+				field("a", arrayOf(scalar("int"))),
+				field("b", mapOf(scalar("string"))),
+				field("c", arrayOf(object(field("x", scalar("int"))))),
+			),
+			object: mp("a", []interface{}{1, 2}, "b", mp("k", "v"), "c", []interface{}{mp("x", 1)}),
+			data:   mp("a", []interface{}{1, 2}, "b", mp("k", "v"), "c", []interface{}{mp("x", 1)}),
+		},
+		{
+			suffix: "document-id",
+			desc:   "a document ID field",
+			comment: `A document ID field is not stored. Decoding sets it to the ID of the
+document.`,
+			schema: schema(
+				&tpb.ObjectField{Name: "id", Type: scalar("string"), DocumentId: true},
+				field("a", scalar("int")),
+			),
+			object: mp("id", "d", "a", 1),
+			data:   mp("a", 1),
+		},
+		{
+			suffix: "server-timestamp",
+			desc:   "a null server timestamp field",
+			comment: `A server timestamp field that holds null is not stored, and is set by the
+service to the time of the write.`,
+			schema:           schema(serverTimestamp, field("a", scalar("int"))),
+			object:           mp("t", nil, "a", 1),
+			data:             mp("a", 1),
+			serverTimestamps: []*tpb.FieldPath{fp("t")},
+			encodeOnly:       true,
+		},
+		{
+			suffix:  "server-timestamp-set",
+			desc:    "a server timestamp field with a value",
+			comment: `A server timestamp field that holds a timestamp is stored as usual.`,
+			schema:  schema(serverTimestamp, field("a", scalar("int"))),
+			object:  mp("t", testTime, "a", 1),
+			data:    mp("t", testTime, "a", 1),
+		},
+		{
+			suffix: "server-timestamp-nested",
+			desc:   "a null server timestamp field in a nested object",
+			comment: `A nested object left empty by removing its server timestamp fields is not
+stored.`,
+			schema: schema(
+				field("a", object(serverTimestamp)),
+				field("b", scalar("int")),
+			),
+			object:           mp("a", mp("t", nil), "b", 1),
+			data:             mp("b", 1),
+			serverTimestamps: []*tpb.FieldPath{{Field: []string{"a", "t"}}},
+			encodeOnly:       true,
+		},
+		{
+			suffix:  "missing",
+			desc:    "fields missing from the document",
+			comment: `Decoding a document without a field leaves it with its zero value.`,
+			schema: schema(
+				field("a", scalar("int")),
+				field("b", scalar("string")),
+				field("c", nullable(scalar("double"))),
+				field("d", mapOf(scalar("int"))),
+			),
+			object:     mp("a", 0, "b", "", "c", nil, "d", mp()),
+			data:       mp(),
+			decodeOnly: true,
+		},
+		{
+			suffix:     "extra-field",
+			desc:       "a document field not in the object",
+			comment:    `Decoding ignores document fields that no field of the object is stored in.`,
+			schema:     schema(field("a", scalar("int"))),
+			object:     mp("a", 1),
+			data:       mp("a", 1, "z", "extra"),
+			decodeOnly: true,
+		},
+		{
+			suffix:     "int-to-double",
+			desc:       "an integer decoded into a double field",
+			comment:    `An integer value may be decoded into a double field.`,
+			schema:     schema(field("a", scalar("double"))),
+			object:     mp("a", 3.0),
+			data:       mp("a", 3),
+			decodeOnly: true,
+		},
+		{
+			suffix:     "type-mismatch",
+			desc:       "a value of the wrong type",
+			comment:    `Decoding a value into a field of a different type is an error.`,
+			schema:     schema(field("a", scalar("int"))),
+			data:       mp("a", "x"),
+			decodeOnly: true,
+			isErr:      true,
+		},
+		{
+			suffix:  "duplicate-name",
+			desc:    "two fields stored under the same name",
+			comment: `It is an error for two fields to be stored in the same document field.`,
+			schema: schema(
+				&tpb.ObjectField{Name: "A", Type: scalar("int"), FirestoreName: "a"},
+				field("a", scalar("int")),
+			),
+			object:     mp("A", 1, "a", 2),
+			encodeOnly: true,
+			isErr:      true,
+		},
+		{
+			suffix:  "document-id-bad-type",
+			desc:    "a document ID field that is not a string",
+			comment: `A document ID field must be a string.`,
+			schema: schema(
+				&tpb.ObjectField{Name: "id", Type: scalar("int"), DocumentId: true},
+				field("a", scalar("int")),
+			),
+			object: mp("id", 1, "a", 1),
+			data:   mp("a", 1),
+			isErr:  true,
+		},
+		{
+			suffix:     "server-timestamp-bad-type",
+			desc:       "a server timestamp field that is not a nullable timestamp",
+			comment:    `A server timestamp field must be a nullable timestamp.`,
+			schema:     schema(&tpb.ObjectField{Name: "t", Type: scalar("int"), ServerTimestamp: true}),
+			object:     mp("t", 0),
+			encodeOnly: true,
+			isErr:      true,
+		},
+	} {
+		for _, decode := range []bool{false, true} {
+			if (decode && test.encodeOnly) || (!decode && test.decodeOnly) {
+				continue
+			}
+			ot := &tpb.ObjectTest{
+				Schema:     test.schema,
+				DocRefPath: docPath,
+				Decode:     decode,
+				IsError:    test.isErr,
+			}
+			dir := "encode"
+			if decode {
+				dir = "decode"
+				ot.Data = &fspb.MapValue{Fields: test.data}
+				if !test.isErr {
+					ot.Object = &fspb.MapValue{Fields: test.object}
+				}
+			} else {
+				ot.Object = &fspb.MapValue{Fields: test.object}
+				if !test.isErr {
+					ot.Data = &fspb.MapValue{Fields: test.data}
+					ot.ServerTimestamps = test.serverTimestamps
+				}
+			}
+			tp := &tpb.Test{
+				Description: fmt.Sprintf("object %s: %s", dir, test.desc),
+				Test:        &tpb.Test_Object{ot},
+			}
+			suite.Tests = append(suite.Tests, tp)
+			outputTestText(fmt.Sprintf("object-%s-%s", dir, test.suffix), test.comment, tp)
+		}
+	}
+}
+
+func genDecode(suite *tpb.TestSuite) {
+	// nested returns a map nested depth levels deep under the key "a", holding
+	// "b": 1 at the bottom, and its JSON.
+	nested := func(depth int) (map[string]*fspb.Value, string) {
+		m, js := mp("b", 1), `{"b": 1}`
+		for i := 0; i < depth; i++ {
+			m, js = mp("a", m), `{"a": `+js+`}`
+		}
+		return m, js
+	}
+	deep, deepJSON := nested(20)
+
+	for _, test := range []struct {
+		suffix   string
+		desc     string
+		comment  string
+		fields   map[string]*fspb.Value
+		jsonData string
+	}{
+		{
+			suffix:   "basic",
+			desc:     "basic",
+			comment:  `A document with values that have JSON equivalents.`,
+			fields:   mp("a", 1, "b", "x", "c", true, "d", nil),
+			jsonData: `{"a": 1, "b": "x", "c": true, "d": null}`,
+		},
+		{
+			suffix:   "empty",
+			desc:     "a document without fields",
+			fields:   nil,
+			jsonData: `{}`,
+		},
+		{
+			suffix:   "empty-map-array",
+			desc:     "empty maps and arrays",
+			comment:  `Empty maps and arrays are decoded as empty, not as null.`,
+			fields:   mp("a", mp(), "b", []interface{}{}),
+			jsonData: `{"a": {}, "b": []}`,
+		},
+		{
+			suffix: "int",
+			desc:   "large integers",
+			comment: `Integers are decoded over the whole 64-bit range, without loss of precision.
+9007199254740993 is 2^53 + 1, which a double cannot represent.`,
+			fields: mp(
+				"a", int64(math.MaxInt64),
+				"b", int64(math.MinInt64),
+				"c", int64(9007199254740993),
+			),
+			jsonData: `{"a": 9223372036854775807, "b": -9223372036854775808, "c": 9007199254740993}`,
+		},
+		{
+			suffix:   "double",
+			desc:     "doubles",
+			comment:  `Doubles are decoded as doubles, even if their value is integral.`,
+			fields:   mp("a", 3.0, "b", -0.5, "c", math.MaxFloat64, "d", math.SmallestNonzeroFloat64),
+			jsonData: `{"a": 3.0, "b": -0.5, "c": 1.7976931348623157e308, "d": 5e-324}`,
+		},
+		{
+			suffix:   "double-special",
+			desc:     "NaN and infinite doubles",
+			comment:  `NaN and the infinities are decoded as doubles.`,
+			fields:   mp("a", math.NaN(), "b", math.Inf(1), "c", math.Inf(-1)),
+			jsonData: `{"a": {"$double": "NaN"}, "b": {"$double": "Infinity"}, "c": {"$double": "-Infinity"}}`,
+		},
+		{
+			suffix: "timestamp",
+			desc:   "timestamps",
+			comment: `Timestamps keep their full nanosecond precision, including those before 1970
+and the earliest one that Firestore stores.`,
+			fields: mp(
+				"a", testTime,
+				"b", time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+				"c", time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+			),
+			jsonData: `{"a": ` + testTimeJSON + `,
+				"b": {"$timestamp": "1969-12-31T23:59:59.999999999Z"},
+				"c": {"$timestamp": "0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			suffix:   "bytes",
+			desc:     "bytes",
+			comment:  `Bytes, including empty ones and ones that are not valid UTF-8.`,
+			fields:   mp("a", []byte{0, 0xff, 0xfe}, "b", []byte{}),
+			jsonData: `{"a": {"$bytes": "AP/+"}, "b": {"$bytes": ""}}`,
+		},
+		{
+			suffix:   "geopoint",
+			desc:     "geo points",
+			fields:   mp("a", testGeoPoint, "b", &latlng.LatLng{Latitude: -90, Longitude: 180}),
+			jsonData: `{"a": ` + testGeoPointJSON + `, "b": {"$geopoint": {"latitude": -90, "longitude": 180}}}`,
+		},
+		{
+			suffix: "reference",
+			desc:   "references",
+			comment: `A reference is decoded with its full path, even if it refers to a document in
+another database or project.`,
+			fields: mp(
+				"a", refval(collPath+"/d2"),
+				"b", refval("projects/projectID/databases/other-db/documents/C/d"),
+				"c", refval("projects/other-project/databases/(default)/documents/C/d"),
+			),
+			jsonData: `{"a": {"$reference": "` + collPath + `/d2"},
+				"b": {"$reference": "projects/projectID/databases/other-db/documents/C/d"},
+				"c": {"$reference": "projects/other-project/databases/(default)/documents/C/d"}}`,
+		},
+		{
+			suffix:   "nested",
+			desc:     "deeply nested maps",
+			comment:  `Maps may be nested 20 levels deep, the most that Firestore allows.`,
+			fields:   deep,
+			jsonData: deepJSON,
+		},
+		{
+			suffix:  "nested-array",
+			desc:    "maps and arrays nested in each other",
+			comment: `Values of every kind may appear inside maps and arrays.`,
+			fields: mp(
+				"a", []interface{}{mp("b", []interface{}{1, 2.5}), testTime, nil},
+				"c", mp("d", mp("e", []byte{1}), "f", []interface{}{}),
+			),
+			jsonData: `{"a": [{"b": [1, 2.5]}, ` + testTimeJSON + `, null],
+				"c": {"d": {"e": {"$bytes": "AQ=="}}, "f": []}}`,
+		},
+		{
+			suffix:   "unicode",
+			desc:     "Unicode keys and strings",
+			comment:  `Keys and strings may hold any Unicode characters.`,
+			fields:   mp("é", "日本語", "😀", "a\x00b"),
+			jsonData: `{"é": "日本語", "😀": "a\u0000b"}`,
+		},
+	} {
+		dt := &tpb.DecodeTest{
+			Doc: &fspb.Document{
+				Name:       docPath,
+				Fields:     test.fields,
+				CreateTime: &tspb.Timestamp{Seconds: 1},
+				UpdateTime: &tspb.Timestamp{Seconds: 2},
+			},
+			JsonData: test.jsonData,
+		}
+		filename := "decode-" + test.suffix
+		checkDecodeTest(filename, dt)
+		tp := &tpb.Test{
+			Description: "decode: " + test.desc,
+			Test:        &tpb.Test_Decode{dt},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(filename, test.comment, tp)
+	}
+}
+
+// checkDecodeTest checks that the JSON of a decode test denotes the fields of
+// its document.
+func checkDecodeTest(filename string, dt *tpb.DecodeTest) {
+	v, err := writes.ParseValue(dt.JsonData)
+	if err != nil {
+		log.Fatalf("%s: %v", filename, err)
+	}
+	want := &fspb.MapValue{Fields: dt.Doc.Fields}
+	if !proto.Equal(v.GetMapValue(), want) {
+		log.Fatalf("%s: JSON denotes\n%s\ndocument has\n%s", filename, proto.MarshalTextString(v), proto.MarshalTextString(want))
+	}
+}
+
+func genFieldPath(suite *tpb.TestSuite) {
+	type fieldPathTest struct {
+		suffix    string
+		desc      string
+		comment   string
+		path      string
+		fieldPath []string
+		encoded   string
+		isErr     bool
+	}
+	tests := []fieldPathTest{
+		{
+			suffix:    "simple",
+			desc:      "a single component",
+			path:      "a",
+			fieldPath: []string{"a"},
+			encoded:   "a",
+		},
+		{
+			suffix:    "dots",
+			desc:      "several components",
+			comment:   `Dots separate the components of a field path.`,
+			path:      "a.b.c",
+			fieldPath: []string{"a", "b", "c"},
+			encoded:   "a.b.c",
+		},
+		{
+			suffix:    "name",
+			desc:      "the document name field",
+			comment:   `__name__, which denotes the name of the document, is an ordinary simple name.`,
+			path:      "__name__",
+			fieldPath: []string{"__name__"},
+			encoded:   "__name__",
+		},
+		{
+			suffix:    "digits",
+			desc:      "components with digits",
+			comment:   `A component that begins with a digit is quoted in requests.`,
+			path:      "a1.1a._1",
+			fieldPath: []string{"a1", "1a", "_1"},
+			encoded:   "a1.`1a`._1",
+		},
+		{
+			suffix:    "special-chars",
+			desc:      "unquoted components with special characters",
+			comment:   `Characters other than "~*/[]` + "`" + `" may appear in unquoted components. They are quoted in requests.`,
+			path:      "a-b.c d.$e",
+			fieldPath: []string{"a-b", "c d", "$e"},
+			encoded:   "`a-b`.`c d`.`$e`",
+		},
+		{
+			suffix:    "quoted",
+			desc:      "a quoted component with a dot",
+			comment:   `A quoted component may hold dots.`,
+			path:      "`a.b`.c",
+			fieldPath: []string{"a.b", "c"},
+			encoded:   "`a.b`.c",
+		},
+		{
+			suffix:    "quoted-simple",
+			desc:      "a quoted simple name",
+			comment:   `A simple name is not quoted in requests, even if the user quoted it.`,
+			path:      "`a`.`b`",
+			fieldPath: []string{"a", "b"},
+			encoded:   "a.b",
+		},
+		{
+			suffix:    "quoted-special-chars",
+			desc:      "a quoted component with special characters",
+			comment:   `A quoted component may hold the characters that cannot appear unquoted.`,
+			path:      "`a~*/[]b`",
+			fieldPath: []string{"a~*/[]b"},
+			encoded:   "`a~*/[]b`",
+		},
+		{
+			suffix:    "escape-backquote",
+			desc:      "an escaped backquote",
+			comment:   `A backslash in a quoted component escapes a backquote, which is escaped again in requests.`,
+			path:      "`a\\`b`",
+			fieldPath: []string{"a`b"},
+			encoded:   "`a\\`b`",
+		},
+		{
+			suffix:    "escape-backslash",
+			desc:      "an escaped backslash",
+			comment:   `A backslash in a quoted component escapes a backslash, which is escaped again in requests.`,
+			path:      "`a\\\\b`",
+			fieldPath: []string{"a\\b"},
+			encoded:   "`a\\\\b`",
+		},
+		{
+			suffix:    "unicode",
+			desc:      "Unicode components",
+			comment:   `Components may hold any Unicode characters. Non-ASCII letters are quoted in requests.`,
+			path:      "é.日本語.`😀.x`",
+			fieldPath: []string{"é", "日本語", "😀.x"},
+			encoded:   "`é`.`日本語`.`😀.x`",
+		},
+		{
+			suffix:  "empty",
+			desc:    "the empty string",
+			comment: `A field path has at least one component.`,
+			path:    "",
+			isErr:   true,
+		},
+		{
+			suffix:  "empty-quoted",
+			desc:    "an empty quoted component",
+			comment: `A quoted component cannot be empty.`,
+			path:    "a.``",
+			isErr:   true,
+		},
+		{
+			suffix:  "unterminated",
+			desc:    "an unterminated quote",
+			comment: `A quoted component must end with a backquote.`,
+			path:    "a.`b",
+			isErr:   true,
+		},
+		{
+			suffix:  "unterminated-escape",
+			desc:    "an escaped final backquote",
+			comment: `A backquote escaped by a backslash does not end a quoted component.`,
+			path:    "`a\\`",
+			isErr:   true,
+		},
+		{
+			suffix:  "quote-inside",
+			desc:    "a backquote inside an unquoted component",
+			comment: `A quote may only begin a component.`,
+			path:    "a`b`",
+			isErr:   true,
+		},
+		{
+			suffix:  "quote-then-text",
+			desc:    "text after a quoted component",
+			comment: `A quoted component must be followed by a dot or the end of the path.`,
+			path:    "`a`b",
+			isErr:   true,
+		},
+	}
+	for _, c := range []struct{ suffix, path string }{
+		{"leading-dot", ".a"},
+		{"trailing-dot", "a."},
+		{"double-dot", "a..b"},
+	} {
+		tests = append(tests, fieldPathTest{
+			suffix:  "empty-component-" + c.suffix,
+			desc:    "an empty component: " + c.path,
+			comment: `No component of a field path may be empty.`,
+			path:    c.path,
+			isErr:   true,
+		})
+	}
+	for _, c := range "~*/[]" {
+		tests = append(tests, fieldPathTest{
+			suffix:  fmt.Sprintf("bad-char-%x", c),
+			desc:    fmt.Sprintf("the invalid character %c", c),
+			comment: `An unquoted component cannot hold any of the characters "~*/[]` + "`" + `".`,
+			path:    fmt.Sprintf("a%cb", c),
+			isErr:   true,
+		})
+	}
+
+	for _, test := range tests {
+		ft := &tpb.FieldPathTest{
+			Path:    test.path,
+			IsError: test.isErr,
+		}
+		if !test.isErr {
+			ft.FieldPath = &tpb.FieldPath{Field: test.fieldPath}
+			ft.Encoded = test.encoded
+		}
+		filename := "field-path-" + test.suffix
+		checkFieldPathTest(filename, ft)
+		tp := &tpb.Test{
+			Description: "field path: " + test.desc,
+			Test:        &tpb.Test_FieldPath{ft},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText(filename, test.comment, tp)
+	}
+}
+
+// checkFieldPathTest compares the outcome of a field path test with the one
+// computed by the model.
+func checkFieldPathTest(filename string, ft *tpb.FieldPathTest) {
+	p, enc, err := writes.FieldPath(ft)
+	switch {
+	case ft.IsError && err == nil:
+		log.Fatalf("%s: model parsed %q, but the test expects an error", filename, p)
+	case !ft.IsError && err != nil:
+		log.Fatalf("%s: model returned error %v", filename, err)
+	case !ft.IsError && (!proto.Equal(&tpb.FieldPath{Field: p}, ft.FieldPath) || enc != ft.Encoded):
+		log.Fatalf("%s: model produced %q, %s; test has %q, %s", filename, p, enc, ft.FieldPath.Field, ft.Encoded)
+	}
+}
+
+func genPath(suite *tpb.TestSuite) {
+	docs := database + "/documents"
+	longID := strings.Repeat("a", 1500)
+	type pathTest struct {
+		suffix     string
+		desc       string
+		comment    string
+		path       string
+		collection bool
+		name       string // expected resource name; empty for an error
+	}
+	tests := []pathTest{
+		{
+			suffix: "doc",
+			desc:   "a document",
+			path:   "C/d",
+			name:   docs + "/C/d",
+		},
+		{
+			suffix:  "doc-nested",
+			desc:    "a document in a subcollection",
+			comment: `A path may name a document in a subcollection of another document.`,
+			path:    "C/d/E/e",
+			name:    docs + "/C/d/E/e",
+		},
+		{
+			suffix:  "doc-slashes",
+			desc:    "a document path with leading and trailing slashes",
+			comment: `Leading and trailing slashes are ignored.`,
+			path:    "/C/d/",
+			name:    docs + "/C/d",
+		},
+		{
+			suffix:  "doc-qualified",
+			desc:    "the resource name of a document",
+			comment: `A path may be the fully qualified resource name of a document in the client's database.`,
+			path:    docs + "/C/d",
+			name:    docs + "/C/d",
+		},
+		{
+			suffix:  "doc-special-chars",
+			desc:    "IDs with special characters",
+			comment: `IDs may hold any characters other than a slash, including Unicode ones.`,
+			path:    "C 1/a b.c-d~é😀",
+			name:    docs + "/C 1/a b.c-d~é😀",
+		},
+		{
+			suffix:  "doc-max-length",
+			desc:    "an ID of the maximum length",
+			comment: `An ID may be up to 1500 bytes long.`,
+			path:    "C/" + longID,
+			name:    docs + "/C/" + longID,
+		},
+		{
+			suffix:  "doc-underscores",
+			desc:    "IDs that begin or end with two underscores",
+			comment: `Only IDs that both begin and end with two underscores are reserved.`,
+			path:    "__C/d__",
+			name:    docs + "/__C/d__",
+		},
+		{
+			suffix:     "coll",
+			desc:       "a collection",
+			path:       "C",
+			collection: true,
+			name:       docs + "/C",
+		},
+		{
+			suffix:     "coll-nested",
+			desc:       "a subcollection",
+			path:       "C/d/E",
+			collection: true,
+			name:       docs + "/C/d/E",
+		},
+		{
+			suffix:     "coll-slashes",
+			desc:       "a collection path with leading and trailing slashes",
+			comment:    `Leading and trailing slashes are ignored.`,
+			path:       "/C/d/E/",
+			collection: true,
+			name:       docs + "/C/d/E",
+		},
+		{
+			suffix:     "coll-qualified",
+			desc:       "the resource name of a collection",
+			comment:    `A path may be the fully qualified resource name of a collection in the client's database.`,
+			path:       docs + "/C",
+			collection: true,
+			name:       docs + "/C",
+		},
+		{
+			suffix:  "doc-odd",
+			desc:    "a document path with an odd number of IDs",
+			comment: `A document path has an even number of IDs.`,
+			path:    "C/d/E",
+		},
+		{
+			suffix:     "coll-even",
+			desc:       "a collection path with an even number of IDs",
+			comment:    `A collection path has an odd number of IDs.`,
+			path:       "C/d",
+			collection: true,
+		},
+		{
+			suffix:  "doc-empty",
+			desc:    "an empty document path",
+			comment: `A document path has at least two IDs.`,
+			path:    "",
+		},
+		{
+			suffix:     "coll-empty",
+			desc:       "an empty collection path",
+			comment:    `A collection path has at least one ID.`,
+			path:       "/",
+			collection: true,
+		},
+		{
+			suffix:  "doc-empty-id",
+			desc:    "an empty ID",
+			comment: `An ID cannot be empty.`,
+			path:    "C//d/E/e",
+		},
+		{
+			suffix:  "doc-too-long",
+			desc:    "an ID over the maximum length",
+			comment: `An ID cannot be longer than 1500 bytes.`,
+			path:    "C/" + longID + "a",
+		},
+		{
+			suffix:  "doc-too-long-unicode",
+			desc:    "an ID over the maximum length in bytes",
+			comment: `The length of an ID is measured in bytes of UTF-8, not in characters.`,
+			path:    "C/" + strings.Repeat("é", 751),
+		},
+		{
+			suffix:  "doc-reserved",
+			desc:    "a reserved document ID",
+			comment: `IDs of the form __.*__ are reserved.`,
+			path:    "C/__d__",
+		},
+		{
+			suffix:     "coll-reserved",
+			desc:       "a reserved collection ID",
+			comment:    `IDs of the form __.*__ are reserved.`,
+			path:       "__C__",
+			collection: true,
+		},
+		{
+			suffix:  "doc-dot",
+			desc:    "a document ID of a single dot",
+			comment: `An ID cannot be "." or "..".`,
+			path:    "C/.",
+		},
+		{
+			suffix:     "coll-dot-dot",
+			desc:       "a collection ID of two dots",
+			comment:    `An ID cannot be "." or "..".`,
+			path:       "C/d/..",
+			collection: true,
+		},
+		{
+			suffix:  "doc-qualified-root",
+			desc:    "the resource name of the database's root",
+			comment: `The root of a database is neither a document nor a collection.`,
+			path:    docs,
+		},
+		{
+			suffix:  "doc-other-database",
+			desc:    "the resource name of a document in another database",
+			comment: `A fully qualified resource name must be in the client's database.`,
+			path:    "projects/projectID/databases/other-db/documents/C/d",
+		},
+		{
+			suffix:     "coll-other-project",
+			desc:       "the resource name of a collection in another project",
+			comment:    `A fully qualified resource name must be in the client's database.`,
+			path:       "projects/other-project/databases/(default)/documents/C",
+			collection: true,
+		},
+	}
+
+	for _, test := range tests {
+		pt := &tpb.PathTest{
+			Database:   database,
+			Path:       test.path,
+			Collection: test.collection,
+			Name:       test.name,
+			IsError:    test.name == "",
+		}
+		tp := &tpb.Test{
+			Description: "path: " + test.desc,
+			Test:        &tpb.Test_Path{pt},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText("path-"+test.suffix, test.comment, tp)
+	}
+}
+
+type listenTest struct {
+	suffix    string                 // textproto filename suffix
+	desc      string                 // short description
+	comment   string                 // detailed explanation (comment in textproto file)
+	responses []*fspb.ListenResponse // a sequence of responses sent over a Listen stream
+	streams   []*tpb.ListenStream    // instead of responses, for a client that reopens its stream
+	clauses   []interface{}          // the query's clauses, if not the default OrderBy("a")
+	query     *fspb.StructuredQuery  // the query the clauses describe, without From
+	snapshots []*tpb.Snapshot
+	isErr     bool // arguments result in a client-side error
+}
+
+// genDatabases generates a test of each kind of call for a named database
+// and for a database in another project. The other tests all use the default
+// database of the project "projectID", so these check that a client puts the
+// database it is connected to in requests, document names and references.
+func genDatabases(suite *tpb.TestSuite) {
+	ts := func(secs int) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: int64(secs)}
+	}
+	refJSON := func(path string) string {
+		return `{"$reference": "` + path + `"}`
+	}
+	txn := []byte("transaction-1")
+
+	for _, d := range []struct {
+		suffix   string
+		desc     string
+		database string
+	}{
+		{"named-db", "named database", "projects/projectID/databases/my-db"},
+		{"other-project", "another project", "projects/other-project/databases/(default)"},
+	} {
+		db := d.database
+		coll := db + "/documents/C"
+		doc := coll + "/d"
+		ref := coll + "/d2"            // a document in the same database
+		defaultRef := collPath + "/d2" // a document in the default database
+		fsdoc := &fspb.Document{Name: doc, Fields: mp("a", 1), CreateTime: ts(1), UpdateTime: ts(1)}
+
+		output := func(filename, desc, comment string, t *tpb.Test) {
+			t.Description = fmt.Sprintf("%s (%s)", desc, d.desc)
+			suite.Tests = append(suite.Tests, t)
+			outputTestText(filename+"-"+d.suffix, comment, t)
+		}
+
+		output("get", "get: get a document", `A document is named within the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Get{&tpb.GetTest{
+				DocRefPath: doc,
+				Request:    &fspb.GetDocumentRequest{Name: doc},
+			}}})
+
+		output("get-all", "get-all: get documents", `BatchGetDocuments is sent to the client's database.`,
+			&tpb.Test{Test: &tpb.Test_GetAll{&tpb.GetAllTest{
+				DocRefPaths: []string{doc, ref},
+				Request:     &fspb.BatchGetDocumentsRequest{Database: db, Documents: []string{doc, ref}},
+				Responses: []*fspb.BatchGetDocumentsResponse{
+					{Result: &fspb.BatchGetDocumentsResponse_Found{fsdoc}, ReadTime: ts(2)},
+					{Result: &fspb.BatchGetDocumentsResponse_Missing{ref}, ReadTime: ts(2)},
+				},
+				Snapshots: []*tpb.DocumentSnapshot{
+					{Doc: fsdoc, ReadTime: ts(2)},
+					{Doc: &fspb.Document{Name: ref}, ReadTime: ts(2), Missing: true},
+				},
+			}}})
+
+		ct := &tpb.CreateTest{
+			DocRefPath: doc,
+			JsonData:   `{"a": ` + refJSON(ref) + `, "b": ` + refJSON(defaultRef) + `}`,
+			Request: &fspb.CommitRequest{
+				Database: db,
+				Writes:   newWrites(doc, mp("a", refval(ref), "b", refval(defaultRef)), nil, existsFalsePrecondition, nil),
+			},
+		}
+		mreq, err := writes.Create(ct)
+		checkWriteTest("create-"+d.suffix, ct.Request, false, mreq, err)
+		output("create", "create: references", `A commit is sent to the client's database. References are sent
+unchanged, including one to a document in another database.`,
+			&tpb.Test{Test: &tpb.Test_Create{ct}})
+
+		ut := &tpb.UpdateTest{
+			DocRefPath: doc,
+			JsonData:   `{"a.b": 1}`,
+			Request: &fspb.CommitRequest{
+				Database: db,
+				Writes:   newWrites(doc, mp("a", mp("b", 1)), []string{"a.b"}, existsTruePrecondition, nil),
+			},
+		}
+		mreq, err = writes.Update(ut)
+		checkWriteTest("update-"+d.suffix, ut.Request, false, mreq, err)
+		output("update", "update: update a document", `A commit is sent to the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Update{ut}})
+
+		dt := &tpb.DeleteTest{
+			DocRefPath: doc,
+			Request:    &fspb.CommitRequest{Database: db, Writes: deleteWrites(doc, nil)},
+		}
+		mreq, err = writes.Delete(dt)
+		checkWriteTest("delete-"+d.suffix, dt.Request, false, mreq, err)
+		output("delete", "delete: delete a document", `A commit is sent to the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Delete{dt}})
+
+		bt := &tpb.BatchTest{
+			Ops: []*tpb.WriteOp{
+				toWriteOp(&tpb.SetTest{DocRefPath: doc, JsonData: `{"a": ` + refJSON(ref) + `}`}),
+				toWriteOp(&tpb.DeleteTest{DocRefPath: ref}),
+			},
+			Request: &fspb.CommitRequest{
+				Database: db,
+				Writes: concatWrites(
+					newWrites(doc, mp("a", refval(ref)), nil, nil, nil),
+					deleteWrites(ref, nil),
+				),
+			},
+		}
+		mreq, err = writes.Batch(bt)
+		checkWriteTest("batch-"+d.suffix, bt.Request, false, mreq, err)
+		output("batch", "batch: a batch", `A batch is committed to the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Batch{bt}})
+
+		tt := &tpb.TransactionTest{
+			Ops: []*tpb.TransactionOp{
+				txnGet(doc),
+				txnWrite(&tpb.SetTest{DocRefPath: ref, JsonData: `{"a": 1}`}),
+			},
+			Transaction: txn,
+			Requests: []*tpb.TransactionRequest{
+				{Request: &tpb.TransactionRequest_BeginTransaction{&fspb.BeginTransactionRequest{Database: db}}},
+				{Request: &tpb.TransactionRequest_BatchGetDocuments{&fspb.BatchGetDocumentsRequest{
+					Database:            db,
+					Documents:           []string{doc},
+					ConsistencySelector: &fspb.BatchGetDocumentsRequest_Transaction{txn},
+				}}},
+				{Request: &tpb.TransactionRequest_Commit{&fspb.CommitRequest{
+					Database:    db,
+					Writes:      newWrites(ref, mp("a", 1), nil, nil, nil),
+					Transaction: txn,
+				}}},
+			},
+		}
+		checkTransactionTest("transaction-"+d.suffix, tt)
+		output("transaction", "transaction: a read-write transaction", `Every request of a transaction is sent to the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Transaction{tt}})
+
+		output("query-cursor-refs", "query: cursors with references", `A query's documents are named within the client's database. Cursor values that
+are references are sent unchanged, including one to a document in another database.`,
+			&tpb.Test{Test: &tpb.Test_Query{&tpb.QueryTest{
+				CollPath: coll,
+				Clauses: []*tpb.Clause{
+					toClause(&tpb.OrderBy{Path: fp("r"), Direction: "asc"}),
+					toClause(&tpb.Clause_StartAt{&tpb.Cursor{JsonValues: []string{refJSON(defaultRef)}}}),
+					toClause(&tpb.Clause_EndBefore{&tpb.Cursor{DocSnapshot: &tpb.DocSnapshot{
+						Path:     ref,
+						JsonData: `{"r": ` + refJSON(ref) + `}`,
+					}}}),
+				},
+				Query: &fspb.StructuredQuery{
+					From: []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}},
+					OrderBy: []*fspb.StructuredQuery_Order{
+						{Field: fref("r"), Direction: fspb.StructuredQuery_ASCENDING},
+						{Field: fref("__name__"), Direction: fspb.StructuredQuery_ASCENDING},
+					},
+					StartAt: &fspb.Cursor{Values: []*fspb.Value{refval(defaultRef)}, Before: true},
+					EndAt:   &fspb.Cursor{Values: []*fspb.Value{refval(ref), refval(ref)}, Before: true},
+				},
+			}}})
+
+		output("query-collection-group", "query: collection group", `A collection group query is run on the root of the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Query{&tpb.QueryTest{
+				CollPath:        coll,
+				CollectionGroup: true,
+				Query: &fspb.StructuredQuery{
+					From: []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C", AllDescendants: true}},
+				},
+			}}})
+
+		output("query-results", "query results: a document", `The documents of a query are named within the client's database.`,
+			&tpb.Test{Test: &tpb.Test_QueryResults{&tpb.QueryResultsTest{
+				CollPath:  coll,
+				Responses: []*fspb.RunQueryResponse{{Document: fsdoc, ReadTime: ts(2)}},
+				Snapshots: []*tpb.DocumentSnapshot{{Doc: fsdoc, ReadTime: ts(2)}},
+			}}})
+
+		output("aggregation-query", "aggregation query: count", `An aggregation query is run on the client's database.`,
+			&tpb.Test{Test: &tpb.Test_AggregationQuery{&tpb.AggregationQueryTest{
+				CollPath: coll,
+				Aggregations: []*tpb.Aggregation{
+					{Alias: "total", Aggregation: &tpb.Aggregation_Count{&tpb.Count{}}},
+				},
+				Query: &fspb.StructuredAggregationQuery{
+					QueryType: &fspb.StructuredAggregationQuery_StructuredQuery{&fspb.StructuredQuery{
+						From: []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}},
+					}},
+					Aggregations: []*fspb.StructuredAggregationQuery_Aggregation{{
+						Alias:    "total",
+						Operator: &fspb.StructuredAggregationQuery_Aggregation_Count_{&fspb.StructuredAggregationQuery_Aggregation_Count{}},
+					}},
+				},
+				Responses: []*fspb.RunAggregationQueryResponse{{
+					Result:   &fspb.AggregationResult{AggregateFields: mp("total", 1)},
+					ReadTime: ts(2),
+				}},
+				Result: mp("total", 1),
+			}}})
+
+		output("query-where", "query: a filtered, ordered and limited query", `A query's clauses are the same in every database.`,
+			&tpb.Test{Test: &tpb.Test_Query{&tpb.QueryTest{
+				CollPath: coll,
+				Clauses: []*tpb.Clause{
+					toClause(&tpb.Where{Path: fp("a"), Op: "==", JsonValue: `1`}),
+					toClause(&tpb.OrderBy{Path: fp("b"), Direction: "desc"}),
+					toClause(&tpb.Clause_Limit{2}),
+				},
+				Query: &fspb.StructuredQuery{
+					From:  []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}},
+					Where: filter("a", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+					OrderBy: []*fspb.StructuredQuery_Order{
+						{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
+					},
+					Limit: &wrappers.Int32Value{Value: 2},
+				},
+			}}})
+
+		// Listen tests, with documents named within the client's database.
+		change := func(doc *fspb.Document) *fspb.ListenResponse {
+			return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{&fspb.DocumentChange{
+				Document:  doc,
+				TargetIds: []int32{watchTargetID},
+			}}}
+		}
+		current := &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+			TargetChangeType: fspb.TargetChange_CURRENT,
+		}}}
+		consistent := func(readTime *tspb.Timestamp, token string) *fspb.ListenResponse {
+			return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+				TargetChangeType: fspb.TargetChange_NO_CHANGE,
+				ReadTime:         readTime,
+				ResumeToken:      []byte(token),
+			}}}
+		}
+		added := func(doc *fspb.Document, idx int32) *tpb.DocChange {
+			return &tpb.DocChange{Kind: tpb.DocChange_ADDED, Doc: doc, OldIndex: -1, NewIndex: idx}
+		}
+		ldoc := func(id string, a, b int) *fspb.Document {
+			return &fspb.Document{Name: coll + "/" + id, Fields: mp("a", a, "b", b), CreateTime: ts(1), UpdateTime: ts(1)}
+		}
+		ld1, ld2 := ldoc("d1", 2, 1), ldoc("d2", 1, 2)
+
+		lt := &tpb.ListenTest{
+			Streams: []*tpb.ListenStream{
+				{
+					Responses: []*fspb.ListenResponse{change(ld1), current, consistent(ts(1), "token-1"), change(ld2)},
+					Code:      int32(codes.Unavailable),
+				},
+				{
+					ResumeToken: []byte("token-1"),
+					Responses:   []*fspb.ListenResponse{change(ld2), current, consistent(ts(2), "token-2")},
+				},
+			},
+			Query: listenQuery(db, nil),
+			Snapshots: []*tpb.Snapshot{
+				{Docs: []*fspb.Document{ld1}, Changes: []*tpb.DocChange{added(ld1, 0)}, ReadTime: ts(1)},
+				{Docs: []*fspb.Document{ld2, ld1}, Changes: []*tpb.DocChange{added(ld2, 0)}, ReadTime: ts(2)},
+			},
+		}
+		checkListenTest(d.suffix, lt)
+		output("listen", "listen: a reopened stream", `The query's parent is the root of the client's database. The client reopens
+the stream with the same query.`,
+			&tpb.Test{Test: &tpb.Test_Listen{lt}})
+
+		lt = &tpb.ListenTest{
+			Responses: []*fspb.ListenResponse{change(ld1), change(ld2), current, consistent(ts(1), "token-1")},
+			Clauses: []*tpb.Clause{
+				toClause(&tpb.Where{Path: fp("a"), Op: ">", JsonValue: `0`}),
+				toClause(&tpb.OrderBy{Path: fp("b"), Direction: "desc"}),
+			},
+			Query: listenQuery(db, &fspb.StructuredQuery{
+				Where: filter("a", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 0),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("b"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			}),
+			Snapshots: []*tpb.Snapshot{{
+				Docs:     []*fspb.Document{ld2, ld1},
+				Changes:  []*tpb.DocChange{added(ld2, 0), added(ld1, 1)},
+				ReadTime: ts(1),
+			}},
+		}
+		checkListenTest("query-"+d.suffix, lt)
+		output("listen-query", "listen: a query with clauses", `A query with clauses is also run on the root of the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Listen{lt}})
+
+		dect := &tpb.DecodeTest{
+			Doc: &fspb.Document{
+				Name:       doc,
+				Fields:     mp("a", refval(ref), "b", refval(defaultRef)),
+				CreateTime: ts(1),
+				UpdateTime: ts(1),
+			},
+			JsonData: `{"a": ` + refJSON(ref) + `, "b": ` + refJSON(defaultRef) + `}`,
+		}
+		checkDecodeTest("decode-"+d.suffix, dect)
+		output("decode", "decode: references", `References are decoded with their full paths, whichever database they are in.`,
+			&tpb.Test{Test: &tpb.Test_Decode{dect}})
+
+		output("path-relative", "path: a relative document path", `A relative path names a document in the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Path{&tpb.PathTest{Database: db, Path: "C/d", Name: doc}}})
+
+		output("path-default-db", "path: the resource name of a document in the default database", `A fully qualified resource name must be in the client's database.`,
+			&tpb.Test{Test: &tpb.Test_Path{&tpb.PathTest{Database: db, Path: docPath, IsError: true}}})
+	}
+}
+
+func genListen(suite *tpb.TestSuite) {
+	current := &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+		TargetChangeType: fspb.TargetChange_CURRENT,
+	}}}
+	reset := &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+		TargetChangeType: fspb.TargetChange_RESET,
+	}}}
+
+	noChange := func(readTime *tspb.Timestamp) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+			TargetChangeType: fspb.TargetChange_NO_CHANGE,
+			ReadTime:         readTime,
+		}}}
+	}
+
+	change := func(doc *fspb.Document) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{&fspb.DocumentChange{
+			Document:  doc,
+			TargetIds: []int32{watchTargetID},
+		}}}
+	}
+
+	del := func(path string) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentDelete{&fspb.DocumentDelete{
+			Document: collPath + "/" + path,
+		}}}
+	}
+
+	existence := func(count int) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_Filter{&fspb.ExistenceFilter{
+			Count: int32(count),
+		}}}
+	}
+
+	// consistent is a global NO_CHANGE response with a resume token.
+	consistent := func(readTime *tspb.Timestamp, token string) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+			TargetChangeType: fspb.TargetChange_NO_CHANGE,
+			ReadTime:         readTime,
+			ResumeToken:      []byte(token),
+		}}}
+	}
+
+	stream := func(token string, code codes.Code, responses ...*fspb.ListenResponse) *tpb.ListenStream {
+		return &tpb.ListenStream{ResumeToken: []byte(token), Responses: responses, Code: int32(code)}
+	}
+
+	ts := func(secs int) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: int64(secs)}
+	}
+
 	doc := func(path string, aval int, utime *tspb.Timestamp) *fspb.Document {
 		return &fspb.Document{
 			Name:       collPath + "/" + path,
@@ -1575,6 +4806,42 @@ func genListen(suite *tpb.TestSuite) {
 	doc4a := doc("d4", -2, ts(3))
 	doc5 := doc("d5", 4, ts(1))
 	doc6 := doc("d6", 3, ts(1))
+
+	// Documents for tests of queries with clauses.
+	fdoc := func(path string, utime *tspb.Timestamp, fields ...interface{}) *fspb.Document {
+		return &fspb.Document{
+			Name:       collPath + "/" + path,
+			Fields:     mp(fields...),
+			CreateTime: ts(1),
+			UpdateTime: utime,
+		}
+	}
+	e1 := fdoc("e1", ts(1), "a", 1, "b", 2)
+	e2 := fdoc("e2", ts(1), "a", 2, "b", 1)
+	e3 := fdoc("e3", ts(1), "a", 3, "b", 1)
+	e4 := fdoc("e4", ts(1), "a", 1, "b", 1)
+	e4a := fdoc("e4", ts(3), "a", 5, "b", 1)
+	// Values of every type, in Firestore's order.
+	tNull := fdoc("t-null", ts(1), "a", nil)
+	tBool := fdoc("t-bool", ts(1), "a", false)
+	tNaN := fdoc("t-nan", ts(1), "a", math.NaN())
+	tInt := fdoc("t-int", ts(1), "a", 1)
+	tIntA := fdoc("t-int", ts(3), "a", "z")
+	tDouble := fdoc("t-double", ts(1), "a", 1.5)
+	tTime := fdoc("t-time", ts(1), "a", testTime)
+	tString := fdoc("t-string", ts(1), "a", "s")
+	tBytes := fdoc("t-bytes", ts(1), "a", []byte("b"))
+	tRef := fdoc("t-ref", ts(1), "a", refval(docPath))
+	tGeo := fdoc("t-geo", ts(1), "a", testGeoPoint)
+	tArray := fdoc("t-array", ts(1), "a", []interface{}{1})
+	tMap := fdoc("t-map", ts(1), "a", mp("k", 1))
+	// Numbers: integers and doubles are compared by value, and NaN is first.
+	n1 := fdoc("n1", ts(1), "a", 2)
+	n2 := fdoc("n2", ts(1), "a", 2.0)
+	n3 := fdoc("n3", ts(1), "a", -1.5)
+	n4 := fdoc("n4", ts(1), "a", math.NaN())
+	n5 := fdoc("n5", ts(1), "a", -3)
+	n6 := fdoc("n6", ts(1), "a", math.Inf(-1))
 	multiDocsTest := listenTest{
 		suffix: "multi-docs",
 		desc:   "multiple documents, added, deleted and updated",
@@ -1594,353 +4861,1018 @@ Old indices refer to the immediately previous state, not the previous snapshot`,
 			change(doc4a),
 			noChange(ts(4)),
 		},
-		snapshots: []*tpb.Snapshot{
-			{
-				Docs: []*fspb.Document{doc2, doc3, doc4, doc1},
-				Changes: []*tpb.DocChange{
-					added(doc2, 0),
-					added(doc3, 1),
-					added(doc4, 2),
-					added(doc1, 3),
+		snapshots: []*tpb.Snapshot{
+			{
+				Docs: []*fspb.Document{doc2, doc3, doc4, doc1},
+				Changes: []*tpb.DocChange{
+					added(doc2, 0),
+					added(doc3, 1),
+					added(doc4, 2),
+					added(doc1, 3),
+				},
+				ReadTime: ts(2),
+			},
+			{
+				Docs: []*fspb.Document{doc4a, doc1a, doc6, doc5},
+				Changes: []*tpb.DocChange{
+					removed(doc2, 0),
+					removed(doc3, 0),
+					added(doc6, 2),
+					added(doc5, 3),
+					modified(doc4a, 0, 0),
+					modified(doc1a, 1, 1),
+				},
+				ReadTime: ts(4),
+			},
+		},
+	}
+
+	doc1r := doc("d1", 2, ts(1))
+	doc2r := doc("d2", 1, ts(2))
+	doc2ra := doc("d2", 3, ts(3))
+	doc3r := doc("d3", 3, ts(2))
+	resetTest := listenTest{
+		suffix: "reset",
+		desc:   "RESET turns off CURRENT",
+		comment: `A RESET message turns off the CURRENT state, and marks all documents as deleted.
+
+If a document appeared on the stream but was never part of a snapshot ("d3" in this test), a reset
+will make it disappear completely.
+
+For a snapshot to happen at a NO_CHANGE reponse, we need to have both seen a CURRENT response, and
+have a change from the previous snapshot. Here, after the reset, we see the same version of d2
+again. That doesn't result in a snapshot.
+`,
+		responses: []*fspb.ListenResponse{
+			change(doc1r),
+			change(doc2r),
+			current, noChange(ts(1)),
+			change(doc3r),
+			reset,
+			noChange(ts(2)), // no snapshot because no longer current
+			current,
+			change(doc2ra),
+			noChange(ts(3)),
+			reset,
+			change(doc2ra), // same docs as before, added back
+			current,
+			noChange(ts(4)), // no snapshot, because state is the same as the previous snapshot
+			change(doc3r),
+			noChange(ts(5)), // snapshot, because doc3r is new
+		},
+		snapshots: []*tpb.Snapshot{
+			{
+				Docs:     []*fspb.Document{doc2r, doc1r},
+				Changes:  []*tpb.DocChange{added(doc2r, 0), added(doc1r, 1)},
+				ReadTime: ts(1),
+			},
+			{
+				Docs:     []*fspb.Document{doc2ra},
+				Changes:  []*tpb.DocChange{removed(doc1r, 1), modified(doc2ra, 0, 0)},
+				ReadTime: ts(3),
+			},
+			{
+				Docs:     []*fspb.Document{doc2ra, doc3r},
+				Changes:  []*tpb.DocChange{added(doc3r, 1)},
+				ReadTime: ts(5),
+			},
+		},
+	}
+
+	for _, test := range []listenTest{
+		{
+			suffix:    "empty",
+			desc:      "no changes; empty snapshot",
+			comment:   `There are no changes, so the snapshot should be empty.`,
+			responses: []*fspb.ListenResponse{current, noChange(ts(1))},
+			snapshots: []*tpb.Snapshot{
+				{
+					ReadTime: ts(1),
+				},
+			},
+		},
+		{
+			suffix:    "add-one",
+			desc:      "add a doc",
+			comment:   `Snapshot with a single document.`,
+			responses: []*fspb.ListenResponse{change(doc("d1", 1, ts(1))), current, noChange(ts(2))},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
+					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix:  "add-mod-del-add",
+			desc:    "add a doc, modify it, delete it, then add it again",
+			comment: `Various changes to a single document.`,
+			responses: []*fspb.ListenResponse{
+				change(doc("d1", 1, ts(1))), current, noChange(ts(1)),
+				change(doc("d1", 2, ts(2))), noChange(ts(2)), // different update time, so new snapshot
+				del("d1"), noChange(ts(3)),
+				change(doc("d1", 3, ts(3))), noChange(ts(4)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
+					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc("d1", 2, ts(2))},
+					Changes:  []*tpb.DocChange{modified(doc("d1", 2, ts(2)), 0, 0)},
+					ReadTime: ts(2),
+				},
+				{
+					Docs:     nil,
+					Changes:  []*tpb.DocChange{removed(doc("d1", 2, ts(2)), 0)},
+					ReadTime: ts(3),
+				},
+				{
+					Docs:     []*fspb.Document{doc("d1", 3, ts(3))},
+					Changes:  []*tpb.DocChange{added(doc("d1", 3, ts(3)), 0)},
+					ReadTime: ts(4),
+				},
+			},
+		},
+		{
+			suffix: "nomod",
+			desc:   "add a doc, then change it but without changing its update time",
+			comment: `Document updates are recognized by a change in the update time, not the data.
+This shouldn't actually happen. It is just a test of the update logic.`,
+			responses: []*fspb.ListenResponse{
+				change(doc("d1", 1, ts(1))), current, noChange(ts(1)),
+				change(doc("d1", 2, ts(1))), noChange(ts(2)), // same update time, so no snapshot
+				del("d1"), noChange(ts(3)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
+					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     nil,
+					Changes:  []*tpb.DocChange{removed(doc("d1", 1, ts(1)), 0)},
+					ReadTime: ts(3),
+				},
+			},
+		},
+		{
+			suffix: "add-three",
+			desc:   "add three documents",
+			comment: `A snapshot with three documents. The documents are sorted
+first by the "a" field, then by their path. The changes are ordered the same way.`,
+			responses: []*fspb.ListenResponse{
+				change(doc("d1", 3, ts(1))),
+				change(doc("d3", 1, ts(1))),
+				change(doc("d2", 1, ts(1))),
+				current, noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs: []*fspb.Document{
+						doc("d2", 1, ts(1)), // same value, so ordered by path
+						doc("d3", 1, ts(1)),
+						doc("d1", 3, ts(1)),
+					},
+					Changes: []*tpb.DocChange{
+						added(doc("d2", 1, ts(1)), 0),
+						added(doc("d3", 1, ts(1)), 1),
+						added(doc("d1", 3, ts(1)), 2),
+					},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix:  "nocurrent",
+			desc:    "no snapshot if we don't see CURRENT",
+			comment: `If the watch state is not marked CURRENT, no snapshot is issued.`,
+			responses: []*fspb.ListenResponse{
+				change(doc("d1", 1, ts(1))),
+				noChange(ts(1)),
+				change(doc("d2", 2, ts(2))),
+				current, noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs: []*fspb.Document{
+						doc("d1", 1, ts(1)),
+						doc("d2", 2, ts(2)),
+					},
+					Changes: []*tpb.DocChange{
+						added(doc("d1", 1, ts(1)), 0),
+						added(doc("d2", 2, ts(2)), 1),
+					},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		multiDocsTest,
+		resetTest,
+		{
+			suffix:  "doc-remove",
+			desc:    "DocumentRemove behaves like DocumentDelete",
+			comment: `The DocumentRemove response behaves exactly like DocumentDelete.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), current, noChange(ts(1)),
+				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentRemove{&fspb.DocumentRemove{
+					Document: doc1.Name,
+				}}},
+				noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Changes:  []*tpb.DocChange{removed(doc1, 0)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix: "filter-nop",
+			desc:   "Filter response with same size is a no-op",
+			comment: `A Filter response whose count matches the size of the current
+state (docs in last snapshot + docs added - docs deleted) is a no-op.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), change(doc2), current, noChange(ts(1)),
+				change(doc3), del("d1"),
+				existence(2),
+				noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc1, 1)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc3},
+					Changes:  []*tpb.DocChange{removed(doc1, 1), added(doc3, 1)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix: "removed-target-ids",
+			desc:   "DocumentChange with removed_target_id is like a delete.",
+			comment: `A DocumentChange with the watch target ID in the removed_target_ids field is the
+same as deleting a document.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), current, noChange(ts(1)),
+				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{&fspb.DocumentChange{
+					Document:         doc1,
+					RemovedTargetIds: []int32{watchTargetID},
+				}}},
+				noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     nil,
+					Changes:  []*tpb.DocChange{removed(doc1, 0)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix:  "target-add-nop",
+			desc:    "TargetChange_ADD is a no-op if it has the same target ID",
+			comment: `A TargetChange_ADD response must have the same watch target ID.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), current,
+				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+					TargetChangeType: fspb.TargetChange_ADD,
+					TargetIds:        []int32{watchTargetID},
+					ReadTime:         ts(2),
+				}}},
+				noChange(ts(1)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+			},
+		},
+		// Errors
+		{
+			suffix:  "target-add-wrong-id",
+			desc:    "TargetChange_ADD is an error if it has a different target ID",
+			comment: `A TargetChange_ADD response must have the same watch target ID.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), current,
+				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+					TargetChangeType: fspb.TargetChange_ADD,
+					TargetIds:        []int32{watchTargetID + 1},
+					ReadTime:         ts(2),
+				}}},
+				noChange(ts(1)),
+			},
+			isErr: true,
+		},
+		{
+			suffix:  "target-remove",
+			desc:    "TargetChange_REMOVE should not appear",
+			comment: `A TargetChange_REMOVE response should never be sent.`,
+			responses: []*fspb.ListenResponse{
+				change(doc1), current,
+				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+					TargetChangeType: fspb.TargetChange_REMOVE,
+				}}},
+				noChange(ts(1)),
+			},
+			isErr: true,
+		},
+		{
+			suffix: "reconnect-resume",
+			desc:   "a closed stream is reopened with the last resume token",
+			comment: `When the stream closes, the client opens a new one, sending the resume token of
+its last consistent point. The service sends the changes since then again.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK, change(doc1), current, consistent(ts(1), "token-1"), change(doc2)),
+				stream("token-1", codes.OK, change(doc2), current, consistent(ts(2), "token-2")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix: "reconnect-discard",
+			desc:   "changes after the last consistent point are discarded on reconnect",
+			comment: `The changes received after the last consistent point are discarded when the
+stream ends, even if the service does not send them again on the new stream.
+Here d1 stays and d3 never appears.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.Unavailable,
+					change(doc1), change(doc2), current, consistent(ts(1), "token-1"),
+					del("d1"), change(doc3)),
+				stream("token-1", codes.OK, change(doc4), current, consistent(ts(2), "token-2")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc1, 1)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc4, doc1},
+					Changes:  []*tpb.DocChange{added(doc4, 1)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix:  "reconnect-latest-token",
+			desc:    "the stream is reopened with the latest resume token",
+			comment: `Each consistent point replaces the resume token of the one before.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.Internal,
+					change(doc1), current, consistent(ts(1), "token-1"),
+					change(doc2), consistent(ts(2), "token-2")),
+				stream("token-2", codes.OK, change(doc1a), current, consistent(ts(3), "token-3")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
+					ReadTime: ts(2),
+				},
+				{
+					Docs:     []*fspb.Document{doc1a, doc2},
+					Changes:  []*tpb.DocChange{modified(doc1a, 1, 0)},
+					ReadTime: ts(3),
 				},
-				ReadTime: ts(2),
 			},
-			{
-				Docs: []*fspb.Document{doc4a, doc1a, doc6, doc5},
-				Changes: []*tpb.DocChange{
-					removed(doc2, 0),
-					removed(doc3, 0),
-					added(doc6, 2),
-					added(doc5, 3),
-					modified(doc4a, 0, 0),
-					modified(doc1a, 1, 1),
+		},
+		{
+			suffix: "reconnect-no-token",
+			desc:   "a NO_CHANGE before CURRENT is not a consistent point",
+			comment: `A global NO_CHANGE response that arrives before the target is CURRENT is not
+a consistent point, so its resume token is ignored. The client reopens the
+stream without one.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.Unavailable, change(doc1), consistent(ts(1), "token-1"), current),
+				stream("", codes.OK, change(doc1), current, consistent(ts(2), "token-2")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(2),
 				},
-				ReadTime: ts(4),
 			},
 		},
-	}
-
-	doc1r := doc("d1", 2, ts(1))
-	doc2r := doc("d2", 1, ts(2))
-	doc2ra := doc("d2", 3, ts(3))
-	doc3r := doc("d3", 3, ts(2))
-	resetTest := listenTest{
-		suffix: "reset",
-		desc:   "RESET turns off CURRENT",
-		comment: `A RESET message turns off the CURRENT state, and marks all documents as deleted.
-
-If a document appeared on the stream but was never part of a snapshot ("d3" in this test), a reset
-will make it disappear completely.
-
-For a snapshot to happen at a NO_CHANGE reponse, we need to have both seen a CURRENT response, and
-have a change from the previous snapshot. Here, after the reset, we see the same version of d2
-again. That doesn't result in a snapshot.
-`,
-		responses: []*fspb.ListenResponse{
-			change(doc1r),
-			change(doc2r),
-			current, noChange(ts(1)),
-			change(doc3r),
-			reset,
-			noChange(ts(2)), // no snapshot because no longer current
-			current,
-			change(doc2ra),
-			noChange(ts(3)),
-			reset,
-			change(doc2ra), // same docs as before, added back
-			current,
-			noChange(ts(4)), // no snapshot, because state is the same as the previous snapshot
-			change(doc3r),
-			noChange(ts(5)), // snapshot, because doc3r is new
+		{
+			suffix: "reconnect-target-token",
+			desc:   "a resume token on a change to the target is ignored",
+			comment: `Only a global NO_CHANGE response is a consistent point. The resume token of a
+TargetChange that names the target is ignored.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.Unavailable,
+					change(doc1), current, consistent(ts(1), "token-1"),
+					change(doc2),
+					&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
+						TargetChangeType: fspb.TargetChange_CURRENT,
+						TargetIds:        []int32{watchTargetID},
+						ResumeToken:      []byte("token-2"),
+						ReadTime:         ts(2),
+					}}}),
+				stream("token-1", codes.OK, change(doc2), current, consistent(ts(3), "token-3")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
+					ReadTime: ts(3),
+				},
+			},
 		},
-		snapshots: []*tpb.Snapshot{
-			{
-				Docs:     []*fspb.Document{doc2r, doc1r},
-				Changes:  []*tpb.DocChange{added(doc2r, 0), added(doc1r, 1)},
-				ReadTime: ts(1),
+		{
+			suffix: "reconnect-after-reset",
+			desc:   "after a RESET, the stream is reopened without a resume token",
+			comment: `RESET discards the resume token along with the results. The client reopens
+the stream without a token and starts over: d1, which the new stream does not
+send, is removed.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.Unavailable,
+					change(doc1), current, consistent(ts(1), "token-1"),
+					reset, change(doc2)),
+				stream("", codes.OK, change(doc2), current, consistent(ts(2), "token-2")),
 			},
-			{
-				Docs:     []*fspb.Document{doc2ra},
-				Changes:  []*tpb.DocChange{removed(doc1r, 1), modified(doc2ra, 0, 0)},
-				ReadTime: ts(3),
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2},
+					Changes:  []*tpb.DocChange{removed(doc1, 0), added(doc2, 0)},
+					ReadTime: ts(2),
+				},
 			},
-			{
-				Docs:     []*fspb.Document{doc2ra, doc3r},
-				Changes:  []*tpb.DocChange{added(doc3r, 1)},
-				ReadTime: ts(5),
+		},
+		{
+			suffix:  "reconnect-twice",
+			desc:    "the stream is reopened twice",
+			comment: `The results stay correct across several reconnects.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK,
+					change(doc1), change(doc4), current, consistent(ts(1), "token-1")),
+				stream("token-1", codes.DeadlineExceeded,
+					change(doc2), del("d4"), current, consistent(ts(2), "token-2"), change(doc5)),
+				stream("token-2", codes.OK,
+					change(doc4a), change(doc5), current, consistent(ts(3), "token-3")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc4, doc1},
+					Changes:  []*tpb.DocChange{added(doc4, 0), added(doc1, 1)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{removed(doc4, 0), added(doc2, 0)},
+					ReadTime: ts(2),
+				},
+				{
+					Docs:     []*fspb.Document{doc4a, doc2, doc1, doc5},
+					Changes:  []*tpb.DocChange{added(doc4a, 0), added(doc5, 3)},
+					ReadTime: ts(3),
+				},
 			},
 		},
-	}
-
-	for _, test := range []listenTest{
 		{
-			suffix:    "empty",
-			desc:      "no changes; empty snapshot",
-			comment:   `There are no changes, so the snapshot should be empty.`,
-			responses: []*fspb.ListenResponse{current, noChange(ts(1))},
+			suffix: "filter-mismatch-above",
+			desc:   "Filter response with a larger count",
+			comment: `A Filter response whose count is larger than the size of the current state
+means the client missed a document. The client discards its results and closes
+the stream, then opens a new one without a resume token. The service sends
+every result again; only those the client did not have are added.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK,
+					change(doc1), change(doc2), current, consistent(ts(1), "token-1"),
+					change(doc3), existence(4)),
+				stream("", codes.OK,
+					change(doc1), change(doc2), change(doc3), change(doc4), current, consistent(ts(2), "token-2")),
+			},
 			snapshots: []*tpb.Snapshot{
 				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc1, 1)},
 					ReadTime: ts(1),
 				},
+				{
+					Docs:     []*fspb.Document{doc2, doc3, doc4, doc1},
+					Changes:  []*tpb.DocChange{added(doc3, 1), added(doc4, 2)},
+					ReadTime: ts(2),
+				},
 			},
 		},
 		{
-			suffix:    "add-one",
-			desc:      "add a doc",
-			comment:   `Snapshot with a single document.`,
-			responses: []*fspb.ListenResponse{change(doc("d1", 1, ts(1))), current, noChange(ts(2))},
+			suffix: "filter-mismatch-below",
+			desc:   "Filter response with a smaller count",
+			comment: `A Filter response whose count is smaller than the size of the current state
+means the client missed a deletion. The client starts over on a new stream,
+and the documents that the service does not send again are removed.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK,
+					change(doc1), change(doc2), change(doc3), current, consistent(ts(1), "token-1"),
+					existence(2)),
+				stream("", codes.OK, change(doc1), change(doc2), current, consistent(ts(2), "token-2")),
+			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
-					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					Docs:     []*fspb.Document{doc2, doc3, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc3, 1), added(doc1, 2)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{removed(doc3, 1)},
 					ReadTime: ts(2),
 				},
 			},
 		},
 		{
-			suffix:  "add-mod-del-add",
-			desc:    "add a doc, modify it, delete it, then add it again",
-			comment: `Various changes to a single document.`,
-			responses: []*fspb.ListenResponse{
-				change(doc("d1", 1, ts(1))), current, noChange(ts(1)),
-				change(doc("d1", 2, ts(2))), noChange(ts(2)), // different update time, so new snapshot
-				del("d1"), noChange(ts(3)),
-				change(doc("d1", 3, ts(3))), noChange(ts(4)),
+			suffix: "filter-mismatch-before-current",
+			desc:   "Filter response with a smaller count before CURRENT",
+			comment: `A Filter response is checked even before the target is CURRENT, against the
+documents received so far. After a mismatch, the client discards them and
+starts over on a new stream: d1 never appears.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK, change(doc1), change(doc2), existence(1)),
+				stream("", codes.OK, change(doc2), current, consistent(ts(1), "token-1")),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
-					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					Docs:     []*fspb.Document{doc2},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
 					ReadTime: ts(1),
 				},
+			},
+		},
+		{
+			suffix: "filter-mismatch-above-before-current",
+			desc:   "Filter response with a larger count before CURRENT",
+			comment: `A Filter response before the target is CURRENT whose count is larger than the
+number of documents received so far also makes the client start over on a new
+stream.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK, change(doc1), change(doc2), existence(3)),
+				stream("", codes.OK, change(doc1), change(doc2), change(doc3), current, consistent(ts(1), "token-1")),
+			},
+			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc("d1", 2, ts(2))},
-					Changes:  []*tpb.DocChange{modified(doc("d1", 2, ts(2)), 0, 0)},
+					Docs:     []*fspb.Document{doc2, doc3, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc3, 1), added(doc1, 2)},
+					ReadTime: ts(1),
+				},
+			},
+		},
+		{
+			suffix: "filter-mismatch-pending",
+			desc:   "Filter response counts pending changes",
+			comment: `The size of the current state includes changes since the last snapshot. Here
+the client has d1 and d3, so a count of 1 is a mismatch even though the last
+snapshot had a single document. The pending changes are discarded.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK,
+					change(doc1), current, consistent(ts(1), "token-1"),
+					change(doc3), existence(1)),
+				stream("", codes.OK, change(doc3), current, consistent(ts(2), "token-2")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc3},
+					Changes:  []*tpb.DocChange{removed(doc1, 0), added(doc3, 0)},
+					ReadTime: ts(2),
+				},
+			},
+		},
+		{
+			suffix: "filter-mismatch-resume",
+			desc:   "resume token after a Filter mismatch",
+			comment: `After a mismatch, the client has no resume token until the new stream reaches
+a consistent point. A later reconnect resumes from there.`,
+			streams: []*tpb.ListenStream{
+				stream("", codes.OK, change(doc1), current, consistent(ts(1), "token-1"), existence(2)),
+				stream("", codes.Unavailable,
+					change(doc1), change(doc2), current, consistent(ts(2), "token-2"), change(doc3)),
+				stream("token-2", codes.OK, change(doc1a), current, consistent(ts(3), "token-3")),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1},
+					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					ReadTime: ts(1),
+				},
+				{
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
 					ReadTime: ts(2),
 				},
 				{
-					Docs:     nil,
-					Changes:  []*tpb.DocChange{removed(doc("d1", 2, ts(2)), 0)},
+					Docs:     []*fspb.Document{doc1a, doc2},
+					Changes:  []*tpb.DocChange{modified(doc1a, 1, 0)},
 					ReadTime: ts(3),
 				},
+			},
+		},
+		{
+			suffix: "query-desc",
+			desc:   "a descending order",
+			comment: `The results are in the query's order. Documents with the same values are
+ordered by name, in the direction of the last OrderBy clause.`,
+			clauses: []interface{}{&tpb.OrderBy{Path: fp("a"), Direction: "desc"}},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			},
+			responses: []*fspb.ListenResponse{
+				change(doc2), change(doc1), change(doc3), current, noChange(ts(1)),
+				change(doc4), noChange(ts(2)),
+			},
+			snapshots: []*tpb.Snapshot{
+				{
+					Docs:     []*fspb.Document{doc1, doc3, doc2},
+					Changes:  []*tpb.DocChange{added(doc1, 0), added(doc3, 1), added(doc2, 2)},
+					ReadTime: ts(1),
+				},
 				{
-					Docs:     []*fspb.Document{doc("d1", 3, ts(3))},
-					Changes:  []*tpb.DocChange{added(doc("d1", 3, ts(3)), 0)},
-					ReadTime: ts(4),
+					Docs:     []*fspb.Document{doc1, doc4, doc3, doc2},
+					Changes:  []*tpb.DocChange{added(doc4, 1)},
+					ReadTime: ts(2),
 				},
 			},
 		},
 		{
-			suffix: "nomod",
-			desc:   "add a doc, then change it but without changing its update time",
-			comment: `Document updates are recognized by a change in the update time, not the data.
-This shouldn't actually happen. It is just a test of the update logic.`,
+			suffix:  "query-multi-order",
+			desc:    "ordered by two fields",
+			comment: `Documents are ordered by the first OrderBy field, then by the second.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("b"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("b"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc("d1", 1, ts(1))), current, noChange(ts(1)),
-				change(doc("d1", 2, ts(1))), noChange(ts(2)), // same update time, so no snapshot
-				del("d1"), noChange(ts(3)),
+				change(e1), change(e2), change(e3), change(e4), current, noChange(ts(1)),
+				change(e4a), noChange(ts(2)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc("d1", 1, ts(1))},
-					Changes:  []*tpb.DocChange{added(doc("d1", 1, ts(1)), 0)},
+					Docs:     []*fspb.Document{e3, e2, e4, e1},
+					Changes:  []*tpb.DocChange{added(e3, 0), added(e2, 1), added(e4, 2), added(e1, 3)},
 					ReadTime: ts(1),
 				},
 				{
-					Docs:     nil,
-					Changes:  []*tpb.DocChange{removed(doc("d1", 1, ts(1)), 0)},
-					ReadTime: ts(3),
+					Docs:     []*fspb.Document{e4a, e3, e2, e1},
+					Changes:  []*tpb.DocChange{modified(e4a, 2, 0)},
+					ReadTime: ts(2),
 				},
 			},
 		},
 		{
-			suffix: "add-three",
-			desc:   "add three documents",
-			comment: `A snapshot with three documents. The documents are sorted
-first by the "a" field, then by their path. The changes are ordered the same way.`,
+			suffix: "query-order-name",
+			desc:   "an explicit descending order by document name",
+			comment: `An OrderBy clause on __name__ orders documents with the same values in its
+direction.`,
+			clauses: []interface{}{
+				&tpb.OrderBy{Path: fp("a"), Direction: "asc"},
+				&tpb.OrderBy{Path: fp("__name__"), Direction: "desc"},
+			},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+					{Field: fref("__name__"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc("d1", 3, ts(1))),
-				change(doc("d3", 1, ts(1))),
-				change(doc("d2", 1, ts(1))),
-				current, noChange(ts(2)),
+				change(doc2), change(doc3), change(doc4), current, noChange(ts(1)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs: []*fspb.Document{
-						doc("d2", 1, ts(1)), // same value, so ordered by path
-						doc("d3", 1, ts(1)),
-						doc("d1", 3, ts(1)),
-					},
-					Changes: []*tpb.DocChange{
-						added(doc("d2", 1, ts(1)), 0),
-						added(doc("d3", 1, ts(1)), 1),
-						added(doc("d1", 3, ts(1)), 2),
-					},
-					ReadTime: ts(2),
+					Docs:     []*fspb.Document{doc3, doc2, doc4},
+					Changes:  []*tpb.DocChange{added(doc3, 0), added(doc2, 1), added(doc4, 2)},
+					ReadTime: ts(1),
 				},
 			},
 		},
 		{
-			suffix:  "nocurrent",
-			desc:    "no snapshot if we don't see CURRENT",
-			comment: `If the watch state is not marked CURRENT, no snapshot is issued.`,
+			suffix: "query-inequality",
+			desc:   "an inequality filter and no OrderBy",
+			comment: `A query with an inequality filter and no OrderBy clause is ordered by the
+filter's field.`,
+			clauses: []interface{}{&tpb.Where{Path: fp("b"), Op: ">", JsonValue: `0`}},
+			query: &fspb.StructuredQuery{
+				Where: filter("b", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 0),
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc("d1", 1, ts(1))),
-				noChange(ts(1)),
-				change(doc("d2", 2, ts(2))),
-				current, noChange(ts(2)),
+				change(e1), change(e2), change(e3), change(e4), current, noChange(ts(1)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs: []*fspb.Document{
-						doc("d1", 1, ts(1)),
-						doc("d2", 2, ts(2)),
-					},
-					Changes: []*tpb.DocChange{
-						added(doc("d1", 1, ts(1)), 0),
-						added(doc("d2", 2, ts(2)), 1),
-					},
-					ReadTime: ts(2),
+					Docs:     []*fspb.Document{e2, e3, e4, e1},
+					Changes:  []*tpb.DocChange{added(e2, 0), added(e3, 1), added(e4, 2), added(e1, 3)},
+					ReadTime: ts(1),
 				},
 			},
 		},
-		multiDocsTest,
-		resetTest,
 		{
-			suffix:  "doc-remove",
-			desc:    "DocumentRemove behaves like DocumentDelete",
-			comment: `The DocumentRemove response behaves exactly like DocumentDelete.`,
+			suffix: "query-inequality-orderby",
+			desc:   "an inequality filter and an OrderBy on another field",
+			comment: `The field of an inequality filter that no OrderBy clause names is ordered
+after the explicit orders, in the direction of the last one. Here e1 and e4
+have the same value of "a", so they are ordered by "b", descending.`,
+			clauses: []interface{}{
+				&tpb.Where{Path: fp("b"), Op: ">", JsonValue: `0`},
+				&tpb.OrderBy{Path: fp("a"), Direction: "desc"},
+			},
+			query: &fspb.StructuredQuery{
+				Where: filter("b", fspb.StructuredQuery_FieldFilter_GREATER_THAN, 0),
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc1), current, noChange(ts(1)),
-				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentRemove{&fspb.DocumentRemove{
-					Document: doc1.Name,
-				}}},
-				noChange(ts(2)),
+				change(e1), change(e2), change(e3), change(e4), current, noChange(ts(1)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc1},
-					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					Docs:     []*fspb.Document{e3, e2, e1, e4},
+					Changes:  []*tpb.DocChange{added(e3, 0), added(e2, 1), added(e1, 2), added(e4, 3)},
 					ReadTime: ts(1),
 				},
-				{
-					Changes:  []*tpb.DocChange{removed(doc1, 0)},
-					ReadTime: ts(2),
-				},
 			},
 		},
 		{
-			suffix: "filter-nop",
-			desc:   "Filter response with same size is a no-op",
-			comment: `A Filter response whose count matches the size of the current
-state (docs in last snapshot + docs added - docs deleted) is a no-op.`,
+			suffix: "query-equality",
+			desc:   "an equality filter and no OrderBy",
+			comment: `An equality filter does not order the results, so a query with only an
+equality filter is ordered by document name.`,
+			clauses: []interface{}{&tpb.Where{Path: fp("b"), Op: "==", JsonValue: `1`}},
+			query: &fspb.StructuredQuery{
+				Where: filter("b", fspb.StructuredQuery_FieldFilter_EQUAL, 1),
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc1), change(doc2), current, noChange(ts(1)),
-				change(doc3), del("d1"),
-				filter(2),
-				noChange(ts(2)),
+				change(e4), change(e3), change(e2), current, noChange(ts(1)),
+				change(e4a), noChange(ts(2)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc2, doc1},
-					Changes:  []*tpb.DocChange{added(doc2, 0), added(doc1, 1)},
+					Docs:     []*fspb.Document{e2, e3, e4},
+					Changes:  []*tpb.DocChange{added(e2, 0), added(e3, 1), added(e4, 2)},
 					ReadTime: ts(1),
 				},
 				{
-					Docs:     []*fspb.Document{doc2, doc3},
-					Changes:  []*tpb.DocChange{removed(doc1, 1), added(doc3, 1)},
+					Docs:     []*fspb.Document{e2, e3, e4a},
+					Changes:  []*tpb.DocChange{modified(e4a, 2, 2)},
 					ReadTime: ts(2),
 				},
 			},
 		},
 		{
-			suffix: "removed-target-ids",
-			desc:   "DocumentChange with removed_target_id is like a delete.",
-			comment: `A DocumentChange with the watch target ID in the removed_target_ids field is the
-same as deleting a document.`,
+			suffix: "query-value-types",
+			desc:   "values of different types",
+			comment: `Values of different types are ordered by type: null, booleans, numbers,
+timestamps, strings, bytes, references, geo points, arrays, then maps. A
+document whose value changes type moves accordingly.`,
+			clauses: []interface{}{&tpb.OrderBy{Path: fp("a"), Direction: "asc"}},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+				},
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc1), current, noChange(ts(1)),
-				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{&fspb.DocumentChange{
-					Document:         doc1,
-					RemovedTargetIds: []int32{watchTargetID},
-				}}},
-				noChange(ts(2)),
+				change(tMap), change(tString), change(tNull), change(tGeo), change(tInt),
+				change(tBytes), change(tArray), change(tBool), change(tTime), change(tRef),
+				current, noChange(ts(1)),
+				change(tIntA), noChange(ts(2)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc1},
-					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					Docs: []*fspb.Document{tNull, tBool, tInt, tTime, tString, tBytes, tRef, tGeo, tArray, tMap},
+					Changes: []*tpb.DocChange{
+						added(tNull, 0), added(tBool, 1), added(tInt, 2), added(tTime, 3), added(tString, 4),
+						added(tBytes, 5), added(tRef, 6), added(tGeo, 7), added(tArray, 8), added(tMap, 9),
+					},
 					ReadTime: ts(1),
 				},
 				{
-					Docs:     nil,
-					Changes:  []*tpb.DocChange{removed(doc1, 0)},
+					Docs:     []*fspb.Document{tNull, tBool, tTime, tString, tIntA, tBytes, tRef, tGeo, tArray, tMap},
+					Changes:  []*tpb.DocChange{modified(tIntA, 2, 4)},
 					ReadTime: ts(2),
 				},
 			},
 		},
 		{
-			suffix:  "target-add-nop",
-			desc:    "TargetChange_ADD is a no-op if it has the same target ID",
-			comment: `A TargetChange_ADD response must have the same watch target ID.`,
+			suffix: "query-numbers",
+			desc:   "integers and doubles",
+			comment: `Integers and doubles are ordered together, by value. NaN comes before every
+other number, and equal numbers are ordered by document name.`,
+			clauses: []interface{}{&tpb.OrderBy{Path: fp("a"), Direction: "desc"}},
+			query: &fspb.StructuredQuery{
+				OrderBy: []*fspb.StructuredQuery_Order{
+					{Field: fref("a"), Direction: fspb.StructuredQuery_DESCENDING},
+				},
+			},
 			responses: []*fspb.ListenResponse{
-				change(doc1), current,
-				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-					TargetChangeType: fspb.TargetChange_ADD,
-					TargetIds:        []int32{watchTargetID},
-					ReadTime:         ts(2),
-				}}},
-				noChange(ts(1)),
+				change(n1), change(n2), change(n3), change(n4), change(n5), change(n6),
+				current, noChange(ts(1)),
+				change(tDouble), change(tNaN), noChange(ts(2)),
 			},
 			snapshots: []*tpb.Snapshot{
 				{
-					Docs:     []*fspb.Document{doc1},
-					Changes:  []*tpb.DocChange{added(doc1, 0)},
+					Docs: []*fspb.Document{n2, n1, n3, n5, n6, n4},
+					Changes: []*tpb.DocChange{
+						added(n2, 0), added(n1, 1), added(n3, 2), added(n5, 3), added(n6, 4), added(n4, 5),
+					},
 					ReadTime: ts(1),
 				},
+				{
+					Docs:     []*fspb.Document{n2, n1, tDouble, n3, n5, n6, tNaN, n4},
+					Changes:  []*tpb.DocChange{added(tDouble, 2), added(tNaN, 6)},
+					ReadTime: ts(2),
+				},
 			},
 		},
-		// Errors
-		{
-			suffix:  "target-add-wrong-id",
-			desc:    "TargetChange_ADD is an error if it has a different target ID",
-			comment: `A TargetChange_ADD response must have the same watch target ID.`,
-			responses: []*fspb.ListenResponse{
-				change(doc1), current,
-				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-					TargetChangeType: fspb.TargetChange_ADD,
-					TargetIds:        []int32{watchTargetID + 1},
-					ReadTime:         ts(2),
-				}}},
-				noChange(ts(1)),
-			},
-			isErr: true,
-		},
-		{
-			suffix:  "target-remove",
-			desc:    "TargetChange_REMOVE should not appear",
-			comment: `A TargetChange_REMOVE response should never be sent.`,
-			responses: []*fspb.ListenResponse{
-				change(doc1), current,
-				&fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{&fspb.TargetChange{
-					TargetChangeType: fspb.TargetChange_REMOVE,
-				}}},
-				noChange(ts(1)),
-			},
-			isErr: true,
-		},
 	} {
+		var tclauses []*tpb.Clause
+		for _, c := range test.clauses {
+			tclauses = append(tclauses, toClause(c))
+		}
+		lt := &tpb.ListenTest{
+			Responses: test.responses,
+			Streams:   test.streams,
+			Clauses:   tclauses,
+			Query:     listenQuery(database, test.query),
+			Snapshots: test.snapshots,
+			IsError:   test.isErr,
+		}
+		checkListenTest(test.suffix, lt)
 		tp := &tpb.Test{
 			Description: "listen: " + test.desc,
-			Test: &tpb.Test_Listen{&tpb.ListenTest{
-				Responses: test.responses,
-				Snapshots: test.snapshots,
-				IsError:   test.isErr,
-			}},
+			Test:        &tpb.Test_Listen{lt},
 		}
 		suite.Tests = append(suite.Tests, tp)
 		outputTestText(fmt.Sprintf("listen-%s", test.suffix), test.comment, tp)
 	}
+
+	// A stream that ends with a retryable code is reopened, and one that ends
+	// with any other code ends the listen with an error.
+	for _, test := range []struct {
+		suffix    string
+		code      codes.Code
+		retryable bool
+	}{
+		{"unknown", codes.Unknown, true},
+		{"deadline-exceeded", codes.DeadlineExceeded, true},
+		{"resource-exhausted", codes.ResourceExhausted, true},
+		{"internal", codes.Internal, true},
+		{"unavailable", codes.Unavailable, true},
+		{"unauthenticated", codes.Unauthenticated, true},
+		{"invalid-argument", codes.InvalidArgument, false},
+		{"not-found", codes.NotFound, false},
+		{"permission-denied", codes.PermissionDenied, false},
+		{"failed-precondition", codes.FailedPrecondition, false},
+	} {
+		name := strings.ToUpper(strings.Replace(test.suffix, "-", "_", -1))
+		first := stream("", test.code, change(doc1), current, consistent(ts(1), "token-1"), change(doc2))
+		snap1 := &tpb.Snapshot{
+			Docs:     []*fspb.Document{doc1},
+			Changes:  []*tpb.DocChange{added(doc1, 0)},
+			ReadTime: ts(1),
+		}
+		var lt *tpb.ListenTest
+		var desc, comment, suffix string
+		if test.retryable {
+			lt = &tpb.ListenTest{
+				Streams: []*tpb.ListenStream{
+					first,
+					stream("token-1", codes.OK, change(doc2), current, consistent(ts(2), "token-2")),
+				},
+				Query: listenQuery(database, nil),
+				Snapshots: []*tpb.Snapshot{snap1, {
+					Docs:     []*fspb.Document{doc2, doc1},
+					Changes:  []*tpb.DocChange{added(doc2, 0)},
+					ReadTime: ts(2),
+				}},
+			}
+			suffix = "retry-" + test.suffix
+			desc = fmt.Sprintf("a stream that ends with %s is reopened", name)
+			comment = fmt.Sprintf(`%s is a retryable error. The client reopens the stream, resuming from
+its last consistent point.`, name)
+		} else {
+			lt = &tpb.ListenTest{
+				Streams:   []*tpb.ListenStream{first},
+				Snapshots: []*tpb.Snapshot{snap1},
+				IsError:   true,
+				Query:     listenQuery(database, nil),
+			}
+			suffix = "error-" + test.suffix
+			desc = fmt.Sprintf("a stream that ends with %s is an error", name)
+			comment = fmt.Sprintf(`%s is not a retryable error. The client does not reopen the stream,
+but signals an error after the snapshots it has produced.`, name)
+		}
+		checkListenTest(suffix, lt)
+		tp := &tpb.Test{
+			Description: "listen: " + desc,
+			Test:        &tpb.Test_Listen{lt},
+		}
+		suite.Tests = append(suite.Tests, tp)
+		outputTestText("listen-"+suffix, comment, tp)
+	}
+}
+
+// listenQuery returns the target of a listen test's query on the collection
+// "C" in db, q with From set, or the default query if q is nil.
+func listenQuery(db string, q *fspb.StructuredQuery) *fspb.Target_QueryTarget {
+	if q == nil {
+		q = &fspb.StructuredQuery{
+			OrderBy: []*fspb.StructuredQuery_Order{
+				{Field: fref("a"), Direction: fspb.StructuredQuery_ASCENDING},
+			},
+		}
+	}
+	q.From = []*fspb.StructuredQuery_CollectionSelector{{CollectionId: "C"}}
+	return &fspb.Target_QueryTarget{
+		Parent:    db + "/documents",
+		QueryType: &fspb.Target_QueryTarget_StructuredQuery{q},
+	}
+}
+
+// checkListenTest compares the snapshots of a listen test with those computed
+// by the reference model in package watch.
+func checkListenTest(suffix string, lt *tpb.ListenTest) {
+	snaps, err := watch.Snapshots(lt)
+	if (err != nil) != lt.IsError {
+		log.Fatalf("listen-%s: model returned error %v, but isErr is %t", suffix, err, lt.IsError)
+	}
+	if len(snaps) != len(lt.Snapshots) {
+		log.Fatalf("listen-%s: model produced %d snapshots, test has %d", suffix, len(snaps), len(lt.Snapshots))
+	}
+	for i, snap := range snaps {
+		if !proto.Equal(snap, lt.Snapshots[i]) {
+			log.Fatalf("listen-%s: snapshot #%d: model produced\n%s\ntest has\n%s", suffix, i,
+				proto.MarshalTextString(snap), proto.MarshalTextString(lt.Snapshots[i]))
+		}
+	}
+	if len(lt.Streams) == 0 {
+		return
+	}
+	tokens := watch.ResumeTokens(lt)
+	if len(tokens) != len(lt.Streams) {
+		log.Fatalf("listen-%s: model opened %d streams, test has %d", suffix, len(tokens), len(lt.Streams))
+	}
+	for i, token := range tokens {
+		if string(token) != string(lt.Streams[i].ResumeToken) {
+			log.Fatalf("listen-%s: stream #%d: model sent resume token %q, test has %q", suffix, i,
+				token, lt.Streams[i].ResumeToken)
+		}
+	}
 }
 
 func toClause(m interface{}) *tpb.Clause {
@@ -1955,6 +5887,8 @@ func toClause(m interface{}) *tpb.Clause {
 		return &tpb.Clause{Clause: c}
 	case *tpb.Clause_Limit:
 		return &tpb.Clause{Clause: c}
+	case *tpb.Clause_LimitToLast:
+		return &tpb.Clause{Clause: c}
 	case *tpb.Clause_StartAt:
 		return &tpb.Clause{Clause: c}
 	case *tpb.Clause_StartAfter:
@@ -1963,11 +5897,51 @@ func toClause(m interface{}) *tpb.Clause {
 		return &tpb.Clause{Clause: c}
 	case *tpb.Clause_EndBefore:
 		return &tpb.Clause{Clause: c}
+	case *tpb.Filter:
+		return &tpb.Clause{Clause: &tpb.Clause_Filter{c}}
 	default:
 		panic("unknown clause type")
 	}
 }
 
+func toWriteOp(m interface{}) *tpb.WriteOp {
+	switch t := m.(type) {
+	case *tpb.CreateTest:
+		return &tpb.WriteOp{Op: &tpb.WriteOp_Create{t}}
+	case *tpb.SetTest:
+		return &tpb.WriteOp{Op: &tpb.WriteOp_Set{t}}
+	case *tpb.UpdateTest:
+		return &tpb.WriteOp{Op: &tpb.WriteOp_Update{t}}
+	case *tpb.UpdatePathsTest:
+		return &tpb.WriteOp{Op: &tpb.WriteOp_UpdatePaths{t}}
+	case *tpb.DeleteTest:
+		return &tpb.WriteOp{Op: &tpb.WriteOp_Delete{t}}
+	default:
+		log.Fatalf("bad write op type %T", m)
+		return nil
+	}
+}
+
+func txnGet(path string) *tpb.TransactionOp {
+	return &tpb.TransactionOp{Op: &tpb.TransactionOp_Get{path}}
+}
+
+func txnWrite(m interface{}) *tpb.TransactionOp {
+	return &tpb.TransactionOp{Op: &tpb.TransactionOp_Write{toWriteOp(m)}}
+}
+
+func deleteWrites(path string, precond *fspb.Precondition) []*fspb.Write {
+	return []*fspb.Write{{Operation: &fspb.Write_Delete{path}, CurrentDocument: precond}}
+}
+
+func concatWrites(wss ...[]*fspb.Write) []*fspb.Write {
+	var writes []*fspb.Write
+	for _, ws := range wss {
+		writes = append(writes, ws...)
+	}
+	return writes
+}
+
 func toFieldPaths(fps [][]string) []*tpb.FieldPath {
 	var ps []*tpb.FieldPath
 	for _, fp := range fps {
@@ -1988,6 +5962,25 @@ func filter(field string, op fspb.StructuredQuery_FieldFilter_Operator, v interf
 	}
 }
 
+func composite(op fspb.StructuredQuery_CompositeFilter_Operator, filters ...*fspb.StructuredQuery_Filter) *fspb.StructuredQuery_Filter {
+	return &fspb.StructuredQuery_Filter{
+		FilterType: &fspb.StructuredQuery_Filter_CompositeFilter{
+			CompositeFilter: &fspb.StructuredQuery_CompositeFilter{
+				Op:      op,
+				Filters: filters,
+			},
+		},
+	}
+}
+
+func whereFilter(field, op, jsonValue string) *tpb.Filter {
+	return &tpb.Filter{Filter: &tpb.Filter_Where{&tpb.Where{Path: fp(field), Op: op, JsonValue: jsonValue}}}
+}
+
+func compositeFilter(op string, filters ...*tpb.Filter) *tpb.Filter {
+	return &tpb.Filter{Filter: &tpb.Filter_Composite{&tpb.CompositeFilter{Op: op, Filters: filters}}}
+}
+
 func unaryFilter(field string, op fspb.StructuredQuery_UnaryFilter_Operator) *fspb.StructuredQuery_Filter {
 	return &fspb.StructuredQuery_Filter{
 		FilterType: &fspb.StructuredQuery_Filter_UnaryFilter{
@@ -2014,14 +6007,103 @@ func outputTestText(filename, comment string, t *tpb.Test) {
 		log.Fatalf("duplicate filename %q", filename)
 	}
 	filenames[filename] = true
+	m, ok := forAPI(t)
+	if !ok {
+		return
+	}
 	basename := filepath.Join(*outputDir, filename+".textproto")
-	if err := writeTestToFile(basename, comment, t); err != nil {
+	if err := writeTestToFile(basename, comment, m); err != nil {
 		log.Fatalf("writing test: %v", err)
 	}
 	nTests++
 }
 
-func writeTestToFile(pathname, comment string, t *tpb.Test) (err error) {
+// forAPI returns t as a message for the API selected by -api. It returns false
+// if t uses features that the API lacks.
+func forAPI(t *tpb.Test) (proto.Message, bool) {
+	if *api == "v1" {
+		return t, true
+	}
+	// The v1beta1 messages have the same wire format as their v1 counterparts,
+	// but some fields are missing. The v1beta1 ListenTest has no query; its
+	// query is always the default one. A v1beta1 Write has no update
+	// transforms; the transforms are in a separate transform write.
+	t = proto.Clone(t).(*tpb.Test)
+	if lt := t.GetListen(); lt != nil && len(lt.Clauses) == 0 && proto.Equal(lt.Query, listenQuery(database, nil)) {
+		lt.Query = nil
+	}
+	for _, req := range commitRequests(t) {
+		if req != nil {
+			req.Writes = splitTransforms(req.Writes)
+		}
+	}
+	bytes, err := proto.Marshal(t)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bt := &v1beta1pb.Test{}
+	if err := proto.Unmarshal(bytes, bt); err != nil {
+		log.Fatal(err)
+	}
+	proto.DiscardUnknown(bt)
+	return bt, proto.Size(bt) == len(bytes)
+}
+
+// commitRequests returns the CommitRequests that t expects.
+func commitRequests(t *tpb.Test) []*fspb.CommitRequest {
+	switch tt := t.Test.(type) {
+	case *tpb.Test_Create:
+		return []*fspb.CommitRequest{tt.Create.Request}
+	case *tpb.Test_Set:
+		return []*fspb.CommitRequest{tt.Set.Request}
+	case *tpb.Test_Update:
+		return []*fspb.CommitRequest{tt.Update.Request}
+	case *tpb.Test_UpdatePaths:
+		return []*fspb.CommitRequest{tt.UpdatePaths.Request}
+	case *tpb.Test_Batch:
+		return []*fspb.CommitRequest{tt.Batch.Request}
+	case *tpb.Test_Transaction:
+		var reqs []*fspb.CommitRequest
+		for _, r := range tt.Transaction.Requests {
+			if c := r.GetCommit(); c != nil {
+				reqs = append(reqs, c)
+			}
+		}
+		return reqs
+	}
+	return nil
+}
+
+// splitTransforms returns ws in the form of the v1beta1 API, in which a
+// write's transforms are in a separate transform write that follows it. A
+// write that only applies transforms, with no fields and an empty mask,
+// becomes a transform write alone.
+func splitTransforms(ws []*fspb.Write) []*fspb.Write {
+	var res []*fspb.Write
+	for _, w := range ws {
+		doc := w.GetUpdate()
+		if doc == nil || len(w.UpdateTransforms) == 0 {
+			res = append(res, w)
+			continue
+		}
+		tw := &fspb.Write{
+			Operation: &fspb.Write_Transform{&fspb.DocumentTransform{
+				Document:        doc.Name,
+				FieldTransforms: w.UpdateTransforms,
+			}},
+		}
+		if len(doc.Fields) == 0 && w.UpdateMask != nil && len(w.UpdateMask.FieldPaths) == 0 {
+			tw.CurrentDocument = w.CurrentDocument
+			res = append(res, tw)
+			continue
+		}
+		w.UpdateTransforms = nil
+		res = append(res, w, tw)
+	}
+	return res
+}
+
+func writeTestToFile(pathname, comment string, t proto.Message) (err error) {
 	f, err := os.Create(pathname)
 	if err != nil {
 		return err
@@ -2052,11 +6134,14 @@ func writeProtoToFile(filename string, p proto.Message) (err error) {
 			err = err2
 		}
 	}()
-	bytes, err := proto.Marshal(p)
-	if err != nil {
+	// Marshal deterministically, so that regenerating unchanged tests leaves
+	// the file unchanged.
+	var buf proto.Buffer
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(p); err != nil {
 		return err
 	}
-	_, err = f.Write(bytes)
+	_, err = f.Write(buf.Bytes())
 	return err
 }
 
@@ -2073,14 +6158,26 @@ func mp(args ...interface{}) map[string]*fspb.Value {
 
 func val(a interface{}) *fspb.Value {
 	switch x := a.(type) {
+	case nil:
+		return &fspb.Value{ValueType: &fspb.Value_NullValue{}}
 	case int:
 		return &fspb.Value{ValueType: &fspb.Value_IntegerValue{int64(x)}}
+	case int64:
+		return &fspb.Value{ValueType: &fspb.Value_IntegerValue{x}}
 	case float64:
 		return &fspb.Value{ValueType: &fspb.Value_DoubleValue{x}}
 	case bool:
 		return &fspb.Value{ValueType: &fspb.Value_BooleanValue{x}}
 	case string:
 		return &fspb.Value{ValueType: &fspb.Value_StringValue{x}}
+	case []byte:
+		return &fspb.Value{ValueType: &fspb.Value_BytesValue{x}}
+	case time.Time:
+		return &fspb.Value{ValueType: &fspb.Value_TimestampValue{&tspb.Timestamp{Seconds: x.Unix(), Nanos: int32(x.Nanosecond())}}}
+	case *latlng.LatLng:
+		return &fspb.Value{ValueType: &fspb.Value_GeoPointValue{x}}
+	case *fspb.Value:
+		return x
 	case map[string]*fspb.Value:
 		return &fspb.Value{ValueType: &fspb.Value_MapValue{&fspb.MapValue{Fields: x}}}
 	case []interface{}:
@@ -2095,6 +6192,48 @@ func val(a interface{}) *fspb.Value {
 	}
 }
 
+func transforms(fts ...*fspb.DocumentTransform_FieldTransform) []*fspb.DocumentTransform_FieldTransform {
+	return fts
+}
+
+// st returns a ServerTimestamp transform of the field at path.
+func st(path string) *fspb.DocumentTransform_FieldTransform {
+	return &fspb.DocumentTransform_FieldTransform{
+		FieldPath: path,
+		TransformType: &fspb.DocumentTransform_FieldTransform_SetToServerValue{
+			fspb.DocumentTransform_FieldTransform_REQUEST_TIME,
+		},
+	}
+}
+
+// arrayUnion returns an ArrayUnion transform of the field at path.
+func arrayUnion(path string, elems ...interface{}) *fspb.DocumentTransform_FieldTransform {
+	return &fspb.DocumentTransform_FieldTransform{
+		FieldPath: path,
+		TransformType: &fspb.DocumentTransform_FieldTransform_AppendMissingElements{
+			val(elems).GetArrayValue(),
+		},
+	}
+}
+
+// arrayRemove returns an ArrayRemove transform of the field at path.
+func arrayRemove(path string, elems ...interface{}) *fspb.DocumentTransform_FieldTransform {
+	return &fspb.DocumentTransform_FieldTransform{
+		FieldPath: path,
+		TransformType: &fspb.DocumentTransform_FieldTransform_RemoveAllFromArray{
+			val(elems).GetArrayValue(),
+		},
+	}
+}
+
+// increment returns an Increment transform of the field at path.
+func increment(path string, n interface{}) *fspb.DocumentTransform_FieldTransform {
+	return &fspb.DocumentTransform_FieldTransform{
+		FieldPath:     path,
+		TransformType: &fspb.DocumentTransform_FieldTransform_Increment{val(n)},
+	}
+}
+
 func refval(path string) *fspb.Value {
 	return &fspb.Value{ValueType: &fspb.Value_ReferenceValue{path}}
 }
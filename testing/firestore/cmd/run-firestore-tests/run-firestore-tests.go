@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// run-firestore-tests runs the cross-language Firestore tests against a client.
+//
+// Usage:
+//
+//	run-firestore-tests [flags] program [args...]
+//
+// The program is run once per test, as described in the documentation of
+// conformance.ExecClient.
+//
+// With -server, the tests are run against an in-process fake Firestore service
+// instead (see package fakeserver). The program's environment has
+// FIRESTORE_EMULATOR_HOST set to the service's address, and the program should
+// connect its client there. The requests the service receives are checked, so
+// the program need not write them to its standard output. For a ListenTest,
+// the service replays the test's streams on successive Listen calls, and
+// checks the resume token with which the client reopens the stream; the
+// program listens to the test's query and stops after receiving as many
+// snapshots as the test expects, or an error. For a GetAllTest,
+// QueryResultsTest or AggregationQueryTest, the service replies to
+// BatchGetDocuments, RunQuery or RunAggregationQuery with the test's
+// responses, and the program reports the snapshots or result as without
+// -server. For a GetAllTest or TransactionTest, the service's BeginTransaction
+// returns the test's transaction ID. An ObjectTest, DecodeTest, FieldPathTest or
+// PathTest sends no request, and is run as without -server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/conformance"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/fakeserver"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+)
+
+var (
+	suiteFile = flag.String("suite", "testdata/test-suite.binproto", "file holding the TestSuite to run")
+	runRegexp = flag.String("run", "", "run only the tests whose descriptions match this regexp")
+	verbose   = flag.Bool("v", false, "report passing tests as well as failing ones")
+	useServer = flag.Bool("server", false, "check requests sent to a fake Firestore service")
+	timeout   = flag.Duration("timeout", time.Minute, "how long the program may run for each test")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] program [args...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	suite, err := conformance.LoadSuite(*suiteFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *runRegexp != "" {
+		re, err := regexp.Compile(*runRegexp)
+		if err != nil {
+			log.Fatalf("-run: %v", err)
+		}
+		var tests []*tpb.Test
+		for _, t := range suite.Tests {
+			if re.MatchString(t.Description) {
+				tests = append(tests, t)
+			}
+		}
+		suite = &tpb.TestSuite{Tests: tests}
+	}
+	os.Exit(run(suite))
+}
+
+// run runs the tests of suite and reports the results. It returns the exit
+// status of the command.
+func run(suite *tpb.TestSuite) int {
+	ec := &conformance.ExecClient{Path: flag.Arg(0), Args: flag.Args()[1:], Timeout: *timeout}
+	var client conformance.Client = ec
+	if *useServer {
+		srv, err := fakeserver.New()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer srv.Close()
+		ec.Env = []string{"FIRESTORE_EMULATOR_HOST=" + srv.Addr}
+		client = &fakeserver.Client{Server: srv, Driver: ec}
+	}
+	nFailed := 0
+	results := conformance.Run(context.Background(), client, suite)
+	for _, r := range results {
+		if r.Err != nil {
+			nFailed++
+			fmt.Printf("FAIL: %s\n\t%v\n", r.Description, r.Err)
+		} else if *verbose {
+			fmt.Printf("PASS: %s\n", r.Description)
+		}
+	}
+	fmt.Printf("%d of %d tests failed\n", nFailed, len(results))
+	if nFailed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,382 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance runs the cross-language Firestore tests against a client.
+//
+// A test interpreter implements Client for the client under test, or uses
+// ExecClient to drive a client written in another language. RunTest then
+// checks the client's behavior against a single Test, and Run does the same
+// for every test in a TestSuite.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/writes"
+	"github.com/golang/protobuf/proto"
+)
+
+// A Client performs the call that a test describes.
+//
+// Each method except GetAll, QueryResults, AggregationQuery, Transaction and
+// Listen returns the request the client would send to the Firestore service.
+// If the client signals an error instead of sending a request, the method
+// returns that error.
+type Client interface {
+	Get(ctx context.Context, t *tpb.GetTest) (*fspb.GetDocumentRequest, error)
+
+	// GetAll reads the documents of t against a service that replies with
+	// t.Responses. It returns the request the client sent, and either the
+	// snapshots the call returned or the error it signaled.
+	GetAll(ctx context.Context, t *tpb.GetAllTest) (*fspb.BatchGetDocumentsRequest, []*tpb.DocumentSnapshot, error)
+
+	Create(ctx context.Context, t *tpb.CreateTest) (*fspb.CommitRequest, error)
+	Set(ctx context.Context, t *tpb.SetTest) (*fspb.CommitRequest, error)
+	Update(ctx context.Context, t *tpb.UpdateTest) (*fspb.CommitRequest, error)
+	UpdatePaths(ctx context.Context, t *tpb.UpdatePathsTest) (*fspb.CommitRequest, error)
+	Delete(ctx context.Context, t *tpb.DeleteTest) (*fspb.CommitRequest, error)
+	Query(ctx context.Context, t *tpb.QueryTest) (*fspb.StructuredQuery, error)
+
+	// QueryResults runs the query that t describes against a service that
+	// replies with t.Responses. It returns the document snapshots the query
+	// produced along with the error that ended it, if any.
+	QueryResults(ctx context.Context, t *tpb.QueryResultsTest) ([]*tpb.DocumentSnapshot, error)
+
+	// AggregationQuery runs the aggregation query that t describes against a
+	// service that replies with t.Responses. It returns the query the client
+	// sent and the aggregate values the call returned, keyed by alias. If the
+	// client signals an error instead, AggregationQuery returns that error.
+	AggregationQuery(ctx context.Context, t *tpb.AggregationQueryTest) (*fspb.StructuredAggregationQuery, map[string]*fspb.Value, error)
+
+	// EncodeObject encodes t.Object, an instance of t.Schema, as the data of a
+	// document. It returns the document's fields and the fields set to the
+	// time of the write.
+	EncodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, []*tpb.FieldPath, error)
+
+	// DecodeObject decodes t.Data, the fields of the document at t.DocRefPath,
+	// into an instance of t.Schema, and returns the object.
+	DecodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, error)
+
+	// Decode decodes the data of t.Doc, and returns it as JSON.
+	Decode(ctx context.Context, t *tpb.DecodeTest) (string, error)
+
+	// FieldPath parses t.Path, and returns its components and its encoding in
+	// requests.
+	FieldPath(ctx context.Context, t *tpb.FieldPathTest) (*tpb.FieldPath, string, error)
+
+	// Reference makes a reference to the document or collection that t.Path
+	// names, with a client connected to t.Database, and returns its resource
+	// name.
+	Reference(ctx context.Context, t *tpb.PathTest) (string, error)
+
+	// Batch performs t.Ops on a WriteBatch and returns the request that
+	// committing it would send.
+	Batch(ctx context.Context, t *tpb.BatchTest) (*fspb.CommitRequest, error)
+
+	// Transaction runs a transaction whose function performs t.Ops, against
+	// a service whose BeginTransaction returns t.Transaction. It returns the
+	// requests the client sent along with the error that RunTransaction
+	// returned, if any.
+	Transaction(ctx context.Context, t *tpb.TransactionTest) ([]*tpb.TransactionRequest, error)
+
+	// Listen feeds the responses of each of t's streams (see watch.Streams)
+	// to the client's watch implementation, ending each stream as t says, and
+	// returns the snapshots it produced along with the error that ended the
+	// listen, if any.
+	Listen(ctx context.Context, t *tpb.ListenTest) ([]*tpb.Snapshot, error)
+}
+
+// A Result is the outcome of running a single test.
+type Result struct {
+	Description string // the description of the test
+	Err         error  // how the client failed the test, or nil if it passed
+}
+
+// Failf returns an error that makes RunTest fail a test, even one that expects
+// the client to signal an error. A Client returns it when it cannot run a test,
+// or when the client misbehaves in a way that the Client's results cannot
+// express.
+func Failf(format string, args ...interface{}) error {
+	return failure{fmt.Errorf(format, args...)}
+}
+
+type failure struct{ error }
+
+// LoadSuite reads a binary-encoded TestSuite from filename.
+func LoadSuite(filename string) (*tpb.TestSuite, error) {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	suite := &tpb.TestSuite{}
+	if err := proto.Unmarshal(bytes, suite); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return suite, nil
+}
+
+// Run runs every test in suite against c, in order.
+func Run(ctx context.Context, c Client, suite *tpb.TestSuite) []Result {
+	var results []Result
+	for _, t := range suite.Tests {
+		results = append(results, Result{
+			Description: t.Description,
+			Err:         RunTest(ctx, c, t),
+		})
+	}
+	return results
+}
+
+// RunTest runs a single test against c. It returns nil if the client behaved
+// as the test expects, and an error describing the difference otherwise.
+func RunTest(ctx context.Context, c Client, t *tpb.Test) error {
+	switch tt := t.Test.(type) {
+	case *tpb.Test_Get:
+		req, err := c.Get(ctx, tt.Get)
+		return checkRequest(req, err, tt.Get.Request, false)
+	case *tpb.Test_GetAll:
+		req, snaps, err := c.GetAll(ctx, tt.GetAll)
+		return checkGetAll(req, snaps, err, tt.GetAll)
+	case *tpb.Test_Create:
+		req, err := c.Create(ctx, tt.Create)
+		return checkRequest(req, err, tt.Create.Request, tt.Create.IsError)
+	case *tpb.Test_Set:
+		req, err := c.Set(ctx, tt.Set)
+		return checkRequest(req, err, tt.Set.Request, tt.Set.IsError)
+	case *tpb.Test_Update:
+		req, err := c.Update(ctx, tt.Update)
+		return checkRequest(req, err, tt.Update.Request, tt.Update.IsError)
+	case *tpb.Test_UpdatePaths:
+		req, err := c.UpdatePaths(ctx, tt.UpdatePaths)
+		return checkRequest(req, err, tt.UpdatePaths.Request, tt.UpdatePaths.IsError)
+	case *tpb.Test_Delete:
+		req, err := c.Delete(ctx, tt.Delete)
+		return checkRequest(req, err, tt.Delete.Request, tt.Delete.IsError)
+	case *tpb.Test_Query:
+		q, err := c.Query(ctx, tt.Query)
+		return checkRequest(q, err, tt.Query.Query, tt.Query.IsError)
+	case *tpb.Test_QueryResults:
+		snaps, err := c.QueryResults(ctx, tt.QueryResults)
+		var got, want []proto.Message
+		for _, s := range snaps {
+			got = append(got, s)
+		}
+		for _, s := range tt.QueryResults.Snapshots {
+			want = append(want, s)
+		}
+		return checkSequence("snapshot", got, err, want, tt.QueryResults.IsError)
+	case *tpb.Test_AggregationQuery:
+		q, result, err := c.AggregationQuery(ctx, tt.AggregationQuery)
+		return checkAggregationQuery(q, result, err, tt.AggregationQuery)
+	case *tpb.Test_Object:
+		return runObjectTest(ctx, c, tt.Object)
+	case *tpb.Test_Decode:
+		data, err := c.Decode(ctx, tt.Decode)
+		return checkDecode(data, err, tt.Decode)
+	case *tpb.Test_FieldPath:
+		fp, enc, err := c.FieldPath(ctx, tt.FieldPath)
+		if err := checkMessage("field path", fp, err, tt.FieldPath.FieldPath, tt.FieldPath.IsError); err != nil || tt.FieldPath.IsError {
+			return err
+		}
+		if enc != tt.FieldPath.Encoded {
+			return fmt.Errorf("got encoding %q, want %q", enc, tt.FieldPath.Encoded)
+		}
+		return nil
+	case *tpb.Test_Path:
+		name, err := c.Reference(ctx, tt.Path)
+		return checkPath(name, err, tt.Path)
+	case *tpb.Test_Batch:
+		req, err := c.Batch(ctx, tt.Batch)
+		return checkRequest(req, err, tt.Batch.Request, tt.Batch.IsError)
+	case *tpb.Test_Transaction:
+		reqs, err := c.Transaction(ctx, tt.Transaction)
+		var got, want []proto.Message
+		for _, r := range reqs {
+			got = append(got, r)
+		}
+		for _, r := range tt.Transaction.Requests {
+			want = append(want, r)
+		}
+		return checkSequence("request", got, err, want, tt.Transaction.IsError)
+	case *tpb.Test_Listen:
+		snaps, err := c.Listen(ctx, tt.Listen)
+		return checkSnapshots(snaps, err, tt.Listen.Snapshots, tt.Listen.IsError)
+	default:
+		return fmt.Errorf("unknown test type %T", t.Test)
+	}
+}
+
+// checkRequest compares the outcome of a call that sends a single request
+// with the outcome the test expects.
+func checkRequest(got proto.Message, err error, want proto.Message, wantErr bool) error {
+	return checkMessage("request", got, err, want, wantErr)
+}
+
+// checkMessage compares the outcome of a call that produces a single message
+// with the outcome the test expects.
+func checkMessage(noun string, got proto.Message, err error, want proto.Message, wantErr bool) error {
+	if f, ok := err.(failure); ok {
+		return f.error
+	}
+	switch {
+	case err != nil && wantErr:
+		return nil
+	case err != nil:
+		return fmt.Errorf("got error %v, want %s %s", err, noun, proto.CompactTextString(want))
+	case wantErr:
+		return fmt.Errorf("got %s %s, want error", noun, proto.CompactTextString(got))
+	case !proto.Equal(got, want):
+		return fmt.Errorf("got %s\n%s\nwant\n%s", noun, proto.MarshalTextString(got), proto.MarshalTextString(want))
+	}
+	return nil
+}
+
+// checkDecode compares the JSON returned by a Decode call with the JSON the
+// test expects, by the values they denote.
+func checkDecode(data string, err error, t *tpb.DecodeTest) error {
+	if f, ok := err.(failure); ok {
+		return f.error
+	}
+	if err != nil {
+		return fmt.Errorf("got error %v, want data %s", err, t.JsonData)
+	}
+	got, err := writes.ParseValue(data)
+	if err != nil {
+		return Failf("bad JSON from client: %v", err)
+	}
+	want, err := writes.ParseValue(t.JsonData)
+	if err != nil {
+		return err
+	}
+	if !proto.Equal(got, want) {
+		return fmt.Errorf("got data %s, want %s", data, t.JsonData)
+	}
+	return nil
+}
+
+// checkPath compares the outcome of a Reference call with the outcome the test
+// expects.
+func checkPath(name string, err error, t *tpb.PathTest) error {
+	if f, ok := err.(failure); ok {
+		return f.error
+	}
+	switch {
+	case err != nil && t.IsError:
+		return nil
+	case err != nil:
+		return fmt.Errorf("got error %v, want name %q", err, t.Name)
+	case t.IsError:
+		return fmt.Errorf("got name %q, want error", name)
+	case name != t.Name:
+		return fmt.Errorf("got name %q, want %q", name, t.Name)
+	}
+	return nil
+}
+
+// runObjectTest encodes or decodes the object of t, and compares the outcome
+// with the one the test expects.
+func runObjectTest(ctx context.Context, c Client, t *tpb.ObjectTest) error {
+	if t.Decode {
+		obj, err := c.DecodeObject(ctx, t)
+		return checkMessage("object", obj, err, t.Object, t.IsError)
+	}
+	data, sts, err := c.EncodeObject(ctx, t)
+	if err := checkMessage("data", data, err, t.Data, t.IsError); err != nil || t.IsError {
+		return err
+	}
+	var got, want []proto.Message
+	for _, p := range sts {
+		got = append(got, p)
+	}
+	for _, p := range t.ServerTimestamps {
+		want = append(want, p)
+	}
+	return checkSequence("server timestamp", got, nil, want, false)
+}
+
+// checkGetAll compares the outcome of a GetAll call with the outcome the test
+// expects. The request is checked even if the call signaled an error.
+func checkGetAll(req *fspb.BatchGetDocumentsRequest, snaps []*tpb.DocumentSnapshot, err error, t *tpb.GetAllTest) error {
+	if f, ok := err.(failure); ok {
+		return f.error
+	}
+	if !proto.Equal(req, t.Request) {
+		return fmt.Errorf("got request\n%s\nwant\n%s", proto.MarshalTextString(req), proto.MarshalTextString(t.Request))
+	}
+	var got, want []proto.Message
+	for _, s := range snaps {
+		got = append(got, s)
+	}
+	for _, s := range t.Snapshots {
+		want = append(want, s)
+	}
+	return checkSequence("snapshot", got, err, want, t.IsError)
+}
+
+// checkAggregationQuery compares the outcome of an AggregationQuery call with
+// the outcome the test expects.
+func checkAggregationQuery(q *fspb.StructuredAggregationQuery, result map[string]*fspb.Value, err error, t *tpb.AggregationQueryTest) error {
+	if err := checkRequest(q, err, t.Query, t.IsError); err != nil || t.IsError {
+		return err
+	}
+	got := &fspb.AggregationResult{AggregateFields: result}
+	want := &fspb.AggregationResult{AggregateFields: t.Result}
+	if !proto.Equal(got, want) {
+		return fmt.Errorf("got result\n%s\nwant\n%s", proto.MarshalTextString(got), proto.MarshalTextString(want))
+	}
+	return nil
+}
+
+// checkSnapshots compares the snapshots produced by a Listen call with the
+// ones the test expects.
+func checkSnapshots(got []*tpb.Snapshot, err error, want []*tpb.Snapshot, wantErr bool) error {
+	var gotm, wantm []proto.Message
+	for _, s := range got {
+		gotm = append(gotm, s)
+	}
+	for _, s := range want {
+		wantm = append(wantm, s)
+	}
+	return checkSequence("snapshot", gotm, err, wantm, wantErr)
+}
+
+// checkSequence compares the outcome of a call that produces a sequence of
+// messages, and possibly an error, with the outcome the test expects. The
+// messages are compared even if an error is expected.
+func checkSequence(noun string, got []proto.Message, err error, want []proto.Message, wantErr bool) error {
+	if f, ok := err.(failure); ok {
+		return f.error
+	}
+	if err != nil && !wantErr {
+		return fmt.Errorf("got error %v, want none", err)
+	}
+	if err == nil && wantErr {
+		return errors.New("got no error, want one")
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("got %d %ss, want %d", len(got), noun, len(want))
+	}
+	for i := range got {
+		if !proto.Equal(got[i], want[i]) {
+			return fmt.Errorf("%s #%d: got\n%s\nwant\n%s", noun, i,
+				proto.MarshalTextString(got[i]), proto.MarshalTextString(want[i]))
+		}
+	}
+	return nil
+}
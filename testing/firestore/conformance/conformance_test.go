@@ -0,0 +1,38 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+)
+
+func TestCheckDecode(t *testing.T) {
+	dt := &tpb.DecodeTest{JsonData: `{"a": 1}`}
+	if err := checkDecode(`{"a":1}`, nil, dt); err != nil {
+		t.Errorf("matching data: %v", err)
+	}
+	if err := checkDecode(`{"a": 2}`, nil, dt); err == nil {
+		t.Error("different data: got nil, want error")
+	}
+	// Bad JSON from the client fails the test rather than panicking.
+	for _, data := range []string{`{"a": 1e400}`, `{"a": 9223372036854775808}`, `{"a": `} {
+		err := checkDecode(data, nil, dt)
+		if _, ok := err.(failure); !ok {
+			t.Errorf("%s: got %v, want failure", data, err)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/golang/protobuf/proto"
+)
+
+// ExecClient is a Client that runs a program once for each test, so that
+// clients written in any language can be tested.
+//
+// The program reads a binary-encoded Test from its standard input, performs
+// the call it describes, and writes the outcome to its standard output as a
+// binary-encoded proto:
+//
+//	GetTest              GetDocumentRequest
+//	GetAllTest           GetAllTest, holding the request and snapshots
+//	QueryTest            StructuredQuery
+//	QueryResultsTest     QueryResultsTest, holding the snapshots
+//	AggregationQueryTest AggregationQueryTest, holding the query and result
+//	ListenTest           ListenTest, holding the snapshots
+//	ObjectTest           ObjectTest, holding the data and server timestamps
+//	                     when encoding, or the object when decoding
+//	DecodeTest           DecodeTest, holding the JSON data
+//	FieldPathTest        FieldPathTest, holding the field path and its encoding
+//	PathTest             PathTest, holding the resource name
+//	TransactionTest      TransactionTest, holding the requests
+//	anything else        CommitRequest
+//
+// If the call signals an error, the program exits with a non-zero status.
+// For a GetAllTest, it should still write the request; for a QueryResultsTest
+// or ListenTest, the snapshots produced before the error; and for a
+// TransactionTest, the requests sent before the error.
+type ExecClient struct {
+	Path    string        // the program to run
+	Args    []string      // arguments to the program
+	Env     []string      // additional environment variables, in "key=value" form
+	Timeout time.Duration // if non-zero, how long the program may run for each test
+}
+
+func (c *ExecClient) Get(ctx context.Context, t *tpb.GetTest) (*fspb.GetDocumentRequest, error) {
+	req := &fspb.GetDocumentRequest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Get{Get: t}}, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (c *ExecClient) GetAll(ctx context.Context, t *tpb.GetAllTest) (*fspb.BatchGetDocumentsRequest, []*tpb.DocumentSnapshot, error) {
+	res := &tpb.GetAllTest{}
+	err := c.call(ctx, &tpb.Test{Test: &tpb.Test_GetAll{GetAll: t}}, res)
+	if err == nil && res.IsError {
+		err = errors.New("client signaled an error")
+	}
+	return res.Request, res.Snapshots, err
+}
+
+func (c *ExecClient) Create(ctx context.Context, t *tpb.CreateTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_Create{Create: t}})
+}
+
+func (c *ExecClient) Set(ctx context.Context, t *tpb.SetTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_Set{Set: t}})
+}
+
+func (c *ExecClient) Update(ctx context.Context, t *tpb.UpdateTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_Update{Update: t}})
+}
+
+func (c *ExecClient) UpdatePaths(ctx context.Context, t *tpb.UpdatePathsTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_UpdatePaths{UpdatePaths: t}})
+}
+
+func (c *ExecClient) Delete(ctx context.Context, t *tpb.DeleteTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_Delete{Delete: t}})
+}
+
+func (c *ExecClient) Query(ctx context.Context, t *tpb.QueryTest) (*fspb.StructuredQuery, error) {
+	q := &fspb.StructuredQuery{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Query{Query: t}}, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (c *ExecClient) QueryResults(ctx context.Context, t *tpb.QueryResultsTest) ([]*tpb.DocumentSnapshot, error) {
+	res := &tpb.QueryResultsTest{}
+	err := c.call(ctx, &tpb.Test{Test: &tpb.Test_QueryResults{QueryResults: t}}, res)
+	if err == nil && res.IsError {
+		err = errors.New("client signaled an error")
+	}
+	return res.Snapshots, err
+}
+
+func (c *ExecClient) AggregationQuery(ctx context.Context, t *tpb.AggregationQueryTest) (*fspb.StructuredAggregationQuery, map[string]*fspb.Value, error) {
+	res := &tpb.AggregationQueryTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_AggregationQuery{AggregationQuery: t}}, res); err != nil {
+		return nil, nil, err
+	}
+	if res.IsError {
+		return nil, nil, errors.New("client signaled an error")
+	}
+	return res.Query, res.Result, nil
+}
+
+func (c *ExecClient) EncodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, []*tpb.FieldPath, error) {
+	res := &tpb.ObjectTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Object{Object: t}}, res); err != nil {
+		return nil, nil, err
+	}
+	if res.IsError {
+		return nil, nil, errors.New("client signaled an error")
+	}
+	return res.Data, res.ServerTimestamps, nil
+}
+
+func (c *ExecClient) DecodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, error) {
+	res := &tpb.ObjectTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Object{Object: t}}, res); err != nil {
+		return nil, err
+	}
+	if res.IsError {
+		return nil, errors.New("client signaled an error")
+	}
+	return res.Object, nil
+}
+
+func (c *ExecClient) Decode(ctx context.Context, t *tpb.DecodeTest) (string, error) {
+	res := &tpb.DecodeTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Decode{Decode: t}}, res); err != nil {
+		return "", err
+	}
+	return res.JsonData, nil
+}
+
+func (c *ExecClient) FieldPath(ctx context.Context, t *tpb.FieldPathTest) (*tpb.FieldPath, string, error) {
+	res := &tpb.FieldPathTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_FieldPath{FieldPath: t}}, res); err != nil {
+		return nil, "", err
+	}
+	if res.IsError {
+		return nil, "", errors.New("client signaled an error")
+	}
+	return res.FieldPath, res.Encoded, nil
+}
+
+func (c *ExecClient) Reference(ctx context.Context, t *tpb.PathTest) (string, error) {
+	res := &tpb.PathTest{}
+	if err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Path{Path: t}}, res); err != nil {
+		return "", err
+	}
+	if res.IsError {
+		return "", errors.New("client signaled an error")
+	}
+	return res.Name, nil
+}
+
+func (c *ExecClient) Batch(ctx context.Context, t *tpb.BatchTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, &tpb.Test{Test: &tpb.Test_Batch{Batch: t}})
+}
+
+func (c *ExecClient) Transaction(ctx context.Context, t *tpb.TransactionTest) ([]*tpb.TransactionRequest, error) {
+	res := &tpb.TransactionTest{}
+	err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Transaction{Transaction: t}}, res)
+	if err == nil && res.IsError {
+		err = errors.New("client signaled an error")
+	}
+	return res.Requests, err
+}
+
+func (c *ExecClient) Listen(ctx context.Context, t *tpb.ListenTest) ([]*tpb.Snapshot, error) {
+	res := &tpb.ListenTest{}
+	err := c.call(ctx, &tpb.Test{Test: &tpb.Test_Listen{Listen: t}}, res)
+	if err == nil && res.IsError {
+		err = errors.New("client signaled an error")
+	}
+	return res.Snapshots, err
+}
+
+func (c *ExecClient) commit(ctx context.Context, t *tpb.Test) (*fspb.CommitRequest, error) {
+	req := &fspb.CommitRequest{}
+	if err := c.call(ctx, t, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// waitDelay bounds how long call waits for the program's output to close
+// after the program is killed.
+const waitDelay = time.Second
+
+// call runs the program on t and decodes its output into res. Output is
+// decoded even if the program exits with a non-zero status. A program that
+// cannot be started, or that runs past the context's deadline, is a failure
+// of the test rather than an error signaled by the client.
+func (c *ExecClient) call(ctx context.Context, t *tpb.Test, res proto.Message) error {
+	in, err := proto.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return Failf("running %s: %v", c.Path, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return Failf("running %s: %v", c.Path, runErr)
+	}
+	if err := proto.Unmarshal(stdout.Bytes(), res); err != nil {
+		return Failf("decoding output of %s: %v", c.Path, err)
+	}
+	if runErr != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("%v: %s", runErr, msg)
+		}
+		return runErr
+	}
+	return nil
+}
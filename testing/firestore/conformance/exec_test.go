@@ -0,0 +1,67 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+)
+
+var createTest = &tpb.CreateTest{
+	DocRefPath: "projects/projectID/databases/(default)/documents/C/d",
+	JsonData:   `{"a": 1}`,
+	IsError:    true,
+}
+
+func TestExecClientError(t *testing.T) {
+	// A program that exits with a non-zero status signals an error.
+	c := &ExecClient{Path: "/bin/false"}
+	_, err := c.Create(context.Background(), createTest)
+	if err == nil {
+		t.Fatal("got nil, want error")
+	}
+	if _, ok := err.(failure); ok {
+		t.Errorf("got failure %v, want client error", err)
+	}
+}
+
+func TestExecClientFailure(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		c    *ExecClient
+	}{
+		{"missing program", &ExecClient{Path: "/nonexistent/prog"}},
+		{"not executable", &ExecClient{Path: "/"}},
+		{"bad output", &ExecClient{Path: "/bin/echo", Args: []string{"not a proto"}}},
+		{"timeout", &ExecClient{
+			Path:    "/bin/sh",
+			Args:    []string{"-c", "sleep 30; true"},
+			Timeout: 10 * time.Millisecond,
+		}},
+	} {
+		start := time.Now()
+		_, err := test.c.Create(context.Background(), createTest)
+		if _, ok := err.(failure); !ok {
+			t.Errorf("%s: got %v, want failure", test.desc, err)
+		}
+		// The shell's child keeps its output open after the shell is killed.
+		if d := time.Since(start); d > 10*time.Second {
+			t.Errorf("%s: took %s", test.desc, d)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/conformance"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/watch"
+	"github.com/golang/protobuf/proto"
+)
+
+// Client is a conformance.Client that checks what a real client sends over
+// the wire. It has Driver perform each call with a client connected to Server,
+// and reports the request that Server received, not the one that Driver
+// returned.
+//
+// A call that sends a request must not signal an error: a test expects either
+// a request or an error instead of one.
+type Client struct {
+	Server *Server
+	Driver conformance.Client // typically a conformance.ExecClient
+}
+
+func (c *Client) Get(ctx context.Context, t *tpb.GetTest) (*fspb.GetDocumentRequest, error) {
+	req, err := c.request(ctx, func() error {
+		_, err := c.Driver.Get(ctx, t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch r := req.(type) {
+	case *fspb.GetDocumentRequest:
+		return r, nil
+	case *fspb.BatchGetDocumentsRequest:
+		// Some clients get a single document with BatchGetDocuments.
+		if len(r.Documents) == 1 && r.Mask == nil && r.ConsistencySelector == nil {
+			return &fspb.GetDocumentRequest{Name: r.Documents[0]}, nil
+		}
+	}
+	return nil, unexpected(req, "GetDocumentRequest")
+}
+
+// GetAll has Driver read documents with a client connected to Server, which
+// replies with t.Responses and returns t.Transaction from BeginTransaction.
+// It returns the one BatchGetDocumentsRequest that Server received, ignoring
+// the requests that begin and end a transaction, and the snapshots that
+// Driver reports.
+func (c *Client) GetAll(ctx context.Context, t *tpb.GetAllTest) (*fspb.BatchGetDocumentsRequest, []*tpb.DocumentSnapshot, error) {
+	c.Server.Reset()
+	c.Server.SetGetAllTest(t)
+	c.Server.SetTransaction(t.Transaction)
+	defer func() {
+		c.Server.SetGetAllTest(nil)
+		c.Server.SetTransaction(nil)
+	}()
+	_, snaps, err := c.Driver.GetAll(ctx, t)
+	var req *fspb.BatchGetDocumentsRequest
+	for _, r := range c.Server.Requests() {
+		switch r := r.(type) {
+		case *fspb.BatchGetDocumentsRequest:
+			if req != nil {
+				return nil, nil, conformance.Failf("client sent more than one BatchGetDocumentsRequest")
+			}
+			req = r
+		case *fspb.BeginTransactionRequest, *fspb.CommitRequest, *fspb.RollbackRequest:
+		default:
+			return nil, nil, unexpected(r, "BatchGetDocumentsRequest")
+		}
+	}
+	if req == nil {
+		return nil, nil, conformance.Failf("client sent no BatchGetDocumentsRequest")
+	}
+	return req, snaps, err
+}
+
+func (c *Client) Create(ctx context.Context, t *tpb.CreateTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.Create(ctx, t)
+		return err
+	})
+}
+
+func (c *Client) Set(ctx context.Context, t *tpb.SetTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.Set(ctx, t)
+		return err
+	})
+}
+
+func (c *Client) Update(ctx context.Context, t *tpb.UpdateTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.Update(ctx, t)
+		return err
+	})
+}
+
+func (c *Client) UpdatePaths(ctx context.Context, t *tpb.UpdatePathsTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.UpdatePaths(ctx, t)
+		return err
+	})
+}
+
+func (c *Client) Delete(ctx context.Context, t *tpb.DeleteTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.Delete(ctx, t)
+		return err
+	})
+}
+
+func (c *Client) Query(ctx context.Context, t *tpb.QueryTest) (*fspb.StructuredQuery, error) {
+	req, err := c.request(ctx, func() error {
+		_, err := c.Driver.Query(ctx, t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	r, ok := req.(*fspb.RunQueryRequest)
+	if !ok {
+		return nil, unexpected(req, "RunQueryRequest")
+	}
+	want := path.Dir(t.CollPath)
+	if t.CollectionGroup {
+		want = database(t.CollPath) + "/documents"
+	}
+	if r.Parent != want {
+		return nil, conformance.Failf("got parent %q, want %q", r.Parent, want)
+	}
+	return r.GetStructuredQuery(), nil
+}
+
+// QueryResults has Driver run a query with a client connected to Server, which
+// replies with t.Responses. The snapshots are those that Driver reports.
+func (c *Client) QueryResults(ctx context.Context, t *tpb.QueryResultsTest) ([]*tpb.DocumentSnapshot, error) {
+	c.Server.Reset()
+	c.Server.SetQueryResultsTest(t)
+	defer c.Server.SetQueryResultsTest(nil)
+	snaps, err := c.Driver.QueryResults(ctx, t)
+	reqs := c.Server.Requests()
+	if len(reqs) != 1 {
+		return nil, conformance.Failf("client sent %d requests, want one", len(reqs))
+	}
+	r, ok := reqs[0].(*fspb.RunQueryRequest)
+	if !ok {
+		return nil, unexpected(reqs[0], "RunQueryRequest")
+	}
+	if want := path.Dir(t.CollPath); r.Parent != want {
+		return nil, conformance.Failf("got parent %q, want %q", r.Parent, want)
+	}
+	return snaps, err
+}
+
+// AggregationQuery has Driver run an aggregation query with a client connected
+// to Server, which replies with t.Responses. It returns the query that Server
+// received and the result that Driver reports.
+func (c *Client) AggregationQuery(ctx context.Context, t *tpb.AggregationQueryTest) (*fspb.StructuredAggregationQuery, map[string]*fspb.Value, error) {
+	c.Server.SetAggregationQueryTest(t)
+	defer c.Server.SetAggregationQueryTest(nil)
+	var result map[string]*fspb.Value
+	req, err := c.request(ctx, func() error {
+		var err error
+		_, result, err = c.Driver.AggregationQuery(ctx, t)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	r, ok := req.(*fspb.RunAggregationQueryRequest)
+	if !ok {
+		return nil, nil, unexpected(req, "RunAggregationQueryRequest")
+	}
+	if want := path.Dir(t.CollPath); r.Parent != want {
+		return nil, nil, conformance.Failf("got parent %q, want %q", r.Parent, want)
+	}
+	return r.GetStructuredAggregationQuery(), result, nil
+}
+
+// EncodeObject has Driver encode an object. No request is involved.
+func (c *Client) EncodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, []*tpb.FieldPath, error) {
+	return c.Driver.EncodeObject(ctx, t)
+}
+
+// DecodeObject has Driver decode an object. No request is involved.
+func (c *Client) DecodeObject(ctx context.Context, t *tpb.ObjectTest) (*fspb.MapValue, error) {
+	return c.Driver.DecodeObject(ctx, t)
+}
+
+// Decode has Driver decode a document. No request is involved.
+func (c *Client) Decode(ctx context.Context, t *tpb.DecodeTest) (string, error) {
+	return c.Driver.Decode(ctx, t)
+}
+
+// FieldPath has Driver parse a field path. No request is involved.
+func (c *Client) FieldPath(ctx context.Context, t *tpb.FieldPathTest) (*tpb.FieldPath, string, error) {
+	return c.Driver.FieldPath(ctx, t)
+}
+
+// Reference has Driver make a reference. No request is involved.
+func (c *Client) Reference(ctx context.Context, t *tpb.PathTest) (string, error) {
+	return c.Driver.Reference(ctx, t)
+}
+
+func (c *Client) Batch(ctx context.Context, t *tpb.BatchTest) (*fspb.CommitRequest, error) {
+	return c.commit(ctx, func() error {
+		_, err := c.Driver.Batch(ctx, t)
+		return err
+	})
+}
+
+// Transaction has Driver run a transaction with a client connected to Server,
+// whose BeginTransaction returns t.Transaction. It returns every request that
+// Server received, and the error that Driver reported.
+func (c *Client) Transaction(ctx context.Context, t *tpb.TransactionTest) ([]*tpb.TransactionRequest, error) {
+	c.Server.Reset()
+	c.Server.SetTransaction(t.Transaction)
+	defer c.Server.SetTransaction(nil)
+	_, err := c.Driver.Transaction(ctx, t)
+	var treqs []*tpb.TransactionRequest
+	for _, req := range c.Server.Requests() {
+		tr := &tpb.TransactionRequest{}
+		switch r := req.(type) {
+		case *fspb.BeginTransactionRequest:
+			tr.Request = &tpb.TransactionRequest_BeginTransaction{BeginTransaction: r}
+		case *fspb.BatchGetDocumentsRequest:
+			tr.Request = &tpb.TransactionRequest_BatchGetDocuments{BatchGetDocuments: r}
+		case *fspb.GetDocumentRequest:
+			// Some clients read a single document in a transaction with GetDocument.
+			tr.Request = &tpb.TransactionRequest_BatchGetDocuments{BatchGetDocuments: &fspb.BatchGetDocumentsRequest{
+				Database:            database(r.Name),
+				Documents:           []string{r.Name},
+				Mask:                r.Mask,
+				ConsistencySelector: &fspb.BatchGetDocumentsRequest_Transaction{Transaction: r.GetTransaction()},
+			}}
+		case *fspb.CommitRequest:
+			tr.Request = &tpb.TransactionRequest_Commit{Commit: r}
+		case *fspb.RollbackRequest:
+			tr.Request = &tpb.TransactionRequest_Rollback{Rollback: r}
+		default:
+			return nil, unexpected(req, "transaction request")
+		}
+		treqs = append(treqs, tr)
+	}
+	return treqs, err
+}
+
+// Listen has Driver listen with a client connected to Server, which replays
+// the streams of t. It checks that the client opens each stream with an
+// AddTarget for t.Query, holding the stream's resume token. The snapshots are
+// those that Driver reports.
+func (c *Client) Listen(ctx context.Context, t *tpb.ListenTest) ([]*tpb.Snapshot, error) {
+	c.Server.Reset()
+	c.Server.SetListenTest(t)
+	defer c.Server.SetListenTest(nil)
+	snaps, err := c.Driver.Listen(ctx, t)
+	reqs := c.Server.Requests()
+	if len(reqs) == 0 {
+		return nil, conformance.Failf("client did not call Listen")
+	}
+	req, ok := reqs[0].(*fspb.ListenRequest)
+	if !ok {
+		return nil, unexpected(reqs[0], "ListenRequest")
+	}
+	if req.GetAddTarget().GetTargetId() == 0 {
+		return nil, conformance.Failf("client's first Listen request %s is not an AddTarget with a target ID",
+			proto.CompactTextString(req))
+	}
+	var targets []*fspb.Target
+	for _, r := range reqs {
+		if r, ok := r.(*fspb.ListenRequest); ok && r.GetAddTarget() != nil {
+			targets = append(targets, r.GetAddTarget())
+		}
+	}
+	streams := watch.Streams(t)
+	if len(targets) > len(streams) {
+		return nil, conformance.Failf("client opened %d Listen streams, want %d", len(targets), len(streams))
+	}
+	want := t.Query
+	if want == nil {
+		want = targets[0].GetQuery()
+	}
+	for i, target := range targets {
+		if !proto.Equal(target.GetQuery(), want) {
+			return nil, conformance.Failf("client opened Listen stream #%d with query %s, want %s", i,
+				proto.CompactTextString(target.GetQuery()), proto.CompactTextString(want))
+		}
+		if !bytes.Equal(target.GetResumeToken(), streams[i].ResumeToken) {
+			return nil, conformance.Failf("client opened Listen stream #%d with resume token %q, want %q", i,
+				target.GetResumeToken(), streams[i].ResumeToken)
+		}
+	}
+	return snaps, err
+}
+
+func (c *Client) commit(ctx context.Context, call func() error) (*fspb.CommitRequest, error) {
+	req, err := c.request(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := req.(*fspb.CommitRequest)
+	if !ok {
+		return nil, unexpected(req, "CommitRequest")
+	}
+	return r, nil
+}
+
+// request makes a call that should send at most one request, and returns
+// the request the server received. If the client sent nothing, it returns
+// the error the call signaled. It is a failure for the call to both send a
+// request and signal an error.
+func (c *Client) request(ctx context.Context, call func() error) (proto.Message, error) {
+	c.Server.Reset()
+	err := call()
+	reqs := c.Server.Requests()
+	switch {
+	case len(reqs) == 1 && err != nil:
+		return nil, conformance.Failf("client sent %T %s, then signaled error %v", reqs[0],
+			proto.CompactTextString(reqs[0]), err)
+	case len(reqs) == 1:
+		return reqs[0], nil
+	case len(reqs) > 1:
+		return nil, conformance.Failf("client sent %d requests, want one", len(reqs))
+	case err != nil:
+		return nil, err
+	default:
+		return nil, conformance.Failf("client sent no request and signaled no error")
+	}
+}
+
+// database returns the database of the document with the given name.
+func database(name string) string {
+	if i := strings.Index(name, "/documents/"); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+func unexpected(req proto.Message, want string) error {
+	return conformance.Failf("client sent %T %s, want a %s", req, proto.CompactTextString(req), want)
+}
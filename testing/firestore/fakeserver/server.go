@@ -0,0 +1,339 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakeserver provides an in-process Firestore service, so that the
+// cross-language tests can be run against unmodified clients.
+//
+// A Server listens on a local port and records every request it receives.
+// Clients connect to it the way they connect to the Firestore emulator,
+// typically by setting FIRESTORE_EMULATOR_HOST to the server's address. A
+// Client then checks the recorded requests against the tests.
+package fakeserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/watch"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serverTime is the time the server reports for every read and write.
+var serverTime = &tspb.Timestamp{Seconds: 42}
+
+// watchTargetID is the target ID used in the responses of ListenTests.
+const watchTargetID = 1
+
+// A Server is a fake Firestore service. It records the requests it receives
+// and answers each with a minimal successful response.
+type Server struct {
+	fspb.UnimplementedFirestoreServer // methods not defined below return codes.Unimplemented
+
+	Addr string // the address the server listens on, in "host:port" form
+
+	gsrv *grpc.Server
+
+	mu               sync.Mutex
+	reqs             []proto.Message
+	getAll           *tpb.GetAllTest
+	queryResults     *tpb.QueryResultsTest
+	aggregationQuery *tpb.AggregationQueryTest
+	listen           *tpb.ListenTest
+	listenStreams    int // the number of Listen streams opened for listen
+	transaction      []byte
+}
+
+// New starts a Server on a local port.
+func New() (*Server, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		Addr: l.Addr().String(),
+		gsrv: grpc.NewServer(),
+	}
+	fspb.RegisterFirestoreServer(s.gsrv, s)
+	go s.gsrv.Serve(l)
+	return s, nil
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.gsrv.Stop()
+}
+
+// Reset discards the recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = nil
+}
+
+// Requests returns the requests received since the server was started or
+// last reset, in the order they arrived.
+func (s *Server) Requests() []proto.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]proto.Message(nil), s.reqs...)
+}
+
+// SetGetAllTest sets the test whose responses the server sends in reply to
+// BatchGetDocuments. If it is nil, BatchGetDocuments finds every document.
+func (s *Server) SetGetAllTest(t *tpb.GetAllTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.getAll = t
+}
+
+// SetQueryResultsTest sets the test whose responses the server sends in reply
+// to RunQuery. If it is nil, RunQuery replies with no documents.
+func (s *Server) SetQueryResultsTest(t *tpb.QueryResultsTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryResults = t
+}
+
+// SetAggregationQueryTest sets the test whose responses the server sends in
+// reply to RunAggregationQuery. If it is nil, RunAggregationQuery replies with
+// an empty result.
+func (s *Server) SetAggregationQueryTest(t *tpb.AggregationQueryTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aggregationQuery = t
+}
+
+// SetListenTest sets the test whose streams the server replays on successive
+// Listen calls.
+func (s *Server) SetListenTest(t *tpb.ListenTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listen = t
+	s.listenStreams = 0
+}
+
+// SetTransaction sets the transaction ID that BeginTransaction returns.
+func (s *Server) SetTransaction(id []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transaction = id
+}
+
+func (s *Server) record(req proto.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, req)
+}
+
+func (s *Server) GetDocument(_ context.Context, req *fspb.GetDocumentRequest) (*fspb.Document, error) {
+	s.record(req)
+	return &fspb.Document{
+		Name:       req.Name,
+		CreateTime: serverTime,
+		UpdateTime: serverTime,
+	}, nil
+}
+
+// BatchGetDocuments replays the responses of the current GetAllTest, ending
+// the stream with a non-retryable error if the test expects one. Without a
+// test, it finds every requested document.
+func (s *Server) BatchGetDocuments(req *fspb.BatchGetDocumentsRequest, stream fspb.Firestore_BatchGetDocumentsServer) error {
+	s.record(req)
+	s.mu.Lock()
+	t := s.getAll
+	s.mu.Unlock()
+	if t != nil {
+		for _, res := range t.Responses {
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+		}
+		if t.IsError {
+			return status.Error(codes.InvalidArgument, "fakeserver: the test expects an error")
+		}
+		return nil
+	}
+	for _, name := range req.Documents {
+		err := stream.Send(&fspb.BatchGetDocumentsResponse{
+			Result: &fspb.BatchGetDocumentsResponse_Found{
+				Found: &fspb.Document{
+					Name:       name,
+					CreateTime: serverTime,
+					UpdateTime: serverTime,
+				},
+			},
+			ReadTime: serverTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) Commit(_ context.Context, req *fspb.CommitRequest) (*fspb.CommitResponse, error) {
+	s.record(req)
+	res := &fspb.CommitResponse{CommitTime: serverTime}
+	for range req.Writes {
+		res.WriteResults = append(res.WriteResults, &fspb.WriteResult{UpdateTime: serverTime})
+	}
+	return res, nil
+}
+
+func (s *Server) BeginTransaction(_ context.Context, req *fspb.BeginTransactionRequest) (*fspb.BeginTransactionResponse, error) {
+	s.record(req)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &fspb.BeginTransactionResponse{Transaction: s.transaction}, nil
+}
+
+func (s *Server) Rollback(_ context.Context, req *fspb.RollbackRequest) (*empty.Empty, error) {
+	s.record(req)
+	return &empty.Empty{}, nil
+}
+
+// RunQuery replays the responses of the current QueryResultsTest, ending the
+// stream with a non-retryable error if the test expects one.
+func (s *Server) RunQuery(req *fspb.RunQueryRequest, stream fspb.Firestore_RunQueryServer) error {
+	s.record(req)
+	s.mu.Lock()
+	t := s.queryResults
+	s.mu.Unlock()
+	if t == nil {
+		return stream.Send(&fspb.RunQueryResponse{ReadTime: serverTime})
+	}
+	for _, res := range t.Responses {
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	if t.IsError {
+		return status.Error(codes.InvalidArgument, "fakeserver: the test expects an error")
+	}
+	return nil
+}
+
+// RunAggregationQuery replays the responses of the current
+// AggregationQueryTest.
+func (s *Server) RunAggregationQuery(req *fspb.RunAggregationQueryRequest, stream fspb.Firestore_RunAggregationQueryServer) error {
+	s.record(req)
+	s.mu.Lock()
+	t := s.aggregationQuery
+	s.mu.Unlock()
+	if t == nil {
+		return stream.Send(&fspb.RunAggregationQueryResponse{
+			Result:   &fspb.AggregationResult{},
+			ReadTime: serverTime,
+		})
+	}
+	for _, res := range t.Responses {
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen replays the next stream of the current ListenTest. Target IDs in the
+// responses are shifted so that watchTargetID becomes the ID that the client
+// chose in its AddTarget request. After the responses, the stream ends as the
+// test says. The last stream, unless it ends with an error code, ends with a
+// non-retryable error if the test expects one, and otherwise stays open until
+// the client closes it.
+func (s *Server) Listen(stream fspb.Firestore_ListenServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.record(req)
+	s.mu.Lock()
+	t := s.listen
+	n := s.listenStreams
+	s.listenStreams++
+	s.mu.Unlock()
+	if t == nil {
+		return status.Error(codes.FailedPrecondition, "fakeserver: no ListenTest to replay")
+	}
+	streams := watch.Streams(t)
+	if n >= len(streams) {
+		return status.Errorf(codes.FailedPrecondition, "fakeserver: the test has only %d Listen streams", len(streams))
+	}
+	targetID := req.GetAddTarget().GetTargetId()
+	if targetID == 0 {
+		return status.Errorf(codes.InvalidArgument, "fakeserver: want an AddTarget with a target ID, got %s",
+			proto.CompactTextString(req))
+	}
+	ls := streams[n]
+	for _, res := range ls.Responses {
+		if err := stream.Send(retarget(res, targetID-watchTargetID)); err != nil {
+			return err
+		}
+	}
+	switch {
+	case ls.Code != 0:
+		return status.Errorf(codes.Code(ls.Code), "fakeserver: the test ends stream #%d", n)
+	case n < len(streams)-1:
+		return nil
+	case t.IsError:
+		return status.Error(codes.InvalidArgument, "fakeserver: the test expects an error")
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.record(req)
+	}
+}
+
+// retarget returns a copy of res with delta added to each target ID.
+// A zero ID means "no target" and is left alone.
+func retarget(res *fspb.ListenResponse, delta int32) *fspb.ListenResponse {
+	res = proto.Clone(res).(*fspb.ListenResponse)
+	shift := func(ids []int32) {
+		for i, id := range ids {
+			if id != 0 {
+				ids[i] = id + delta
+			}
+		}
+	}
+	switch r := res.ResponseType.(type) {
+	case *fspb.ListenResponse_TargetChange:
+		shift(r.TargetChange.TargetIds)
+	case *fspb.ListenResponse_DocumentChange:
+		shift(r.DocumentChange.TargetIds)
+		shift(r.DocumentChange.RemovedTargetIds)
+	case *fspb.ListenResponse_DocumentDelete:
+		shift(r.DocumentDelete.RemovedTargetIds)
+	case *fspb.ListenResponse_DocumentRemove:
+		shift(r.DocumentRemove.RemovedTargetIds)
+	case *fspb.ListenResponse_Filter:
+		if r.Filter.TargetId != 0 {
+			r.Filter.TargetId += delta
+		}
+	}
+	return res
+}
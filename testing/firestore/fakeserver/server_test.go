@@ -0,0 +1,213 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnimplemented(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	conn, err := grpc.Dial(s.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := fspb.NewFirestoreClient(conn)
+	_, err = c.ListCollectionIds(context.Background(), &fspb.ListCollectionIdsRequest{
+		Parent: "projects/projectID/databases/(default)/documents",
+	})
+	if got, want := status.Code(err), codes.Unimplemented; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// The server still answers after an unimplemented call.
+	if _, err := c.Commit(context.Background(), &fspb.CommitRequest{
+		Database: "projects/projectID/databases/(default)",
+	}); err != nil {
+		t.Errorf("Commit: %v", err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	conn, err := grpc.Dial(s.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := fspb.NewFirestoreClient(conn)
+
+	targetChange := func(typ fspb.TargetChange_TargetChangeType, ids ...int32) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{
+			TargetChange: &fspb.TargetChange{TargetChangeType: typ, TargetIds: ids},
+		}}
+	}
+	docChange := func(ids, removed []int32) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{
+			DocumentChange: &fspb.DocumentChange{
+				Document:         &fspb.Document{Name: "projects/projectID/databases/(default)/documents/C/d"},
+				TargetIds:        ids,
+				RemovedTargetIds: removed,
+			},
+		}}
+	}
+	existence := func(id int32) *fspb.ListenResponse {
+		return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_Filter{
+			Filter: &fspb.ExistenceFilter{TargetId: id, Count: 1},
+		}}
+	}
+	s.SetListenTest(&tpb.ListenTest{
+		Streams: []*tpb.ListenStream{
+			{
+				Responses: []*fspb.ListenResponse{
+					targetChange(fspb.TargetChange_ADD, watchTargetID),
+					docChange([]int32{watchTargetID}, nil),
+				},
+				Code: int32(codes.Unavailable),
+			},
+			{
+				Responses: []*fspb.ListenResponse{
+					docChange(nil, []int32{watchTargetID}),
+					existence(watchTargetID),
+					targetChange(fspb.TargetChange_NO_CHANGE),
+				},
+			},
+			{Responses: []*fspb.ListenResponse{targetChange(fspb.TargetChange_CURRENT, watchTargetID)}},
+		},
+		IsError: true,
+	})
+
+	// The client chooses target ID 7, so every nonzero target ID is shifted
+	// by 6.
+	const targetID = 7
+	for _, test := range []struct {
+		want []*fspb.ListenResponse
+		code codes.Code // OK means the server closes the stream
+	}{
+		{
+			want: []*fspb.ListenResponse{
+				targetChange(fspb.TargetChange_ADD, targetID),
+				docChange([]int32{targetID}, nil),
+			},
+			code: codes.Unavailable,
+		},
+		{
+			want: []*fspb.ListenResponse{
+				docChange(nil, []int32{targetID}),
+				existence(targetID),
+				targetChange(fspb.TargetChange_NO_CHANGE),
+			},
+			code: codes.OK,
+		},
+		{
+			want: []*fspb.ListenResponse{targetChange(fspb.TargetChange_CURRENT, targetID)},
+			code: codes.InvalidArgument, // the end of a test that expects an error
+		},
+		{code: codes.FailedPrecondition}, // the test has no more streams
+	} {
+		got, err := listen(c, targetID)
+		if err == io.EOF {
+			err = nil
+		}
+		if status.Code(err) != test.code {
+			t.Fatalf("got %v, want %v", err, test.code)
+		}
+		if len(got) != len(test.want) {
+			t.Fatalf("got %d responses, want %d", len(got), len(test.want))
+		}
+		for i := range got {
+			if !proto.Equal(got[i], test.want[i]) {
+				t.Errorf("response #%d:\ngot  %s\nwant %s", i, got[i], test.want[i])
+			}
+		}
+	}
+	if got, want := len(s.Requests()), 4; got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
+
+// listen opens a Listen stream with an AddTarget for targetID, and returns the
+// responses on it and the error that ends it.
+func listen(c fspb.FirestoreClient, targetID int32) ([]*fspb.ListenResponse, error) {
+	stream, err := c.Listen(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&fspb.ListenRequest{
+		Database: "projects/projectID/databases/(default)",
+		TargetChange: &fspb.ListenRequest_AddTarget{
+			AddTarget: &fspb.Target{TargetId: targetID},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	var ress []*fspb.ListenResponse
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			return ress, err
+		}
+		ress = append(ress, res)
+	}
+}
+
+func TestListenStaysOpen(t *testing.T) {
+	s, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	conn, err := grpc.Dial(s.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s.SetListenTest(&tpb.ListenTest{})
+	stream, err := fspb.NewFirestoreClient(conn).Listen(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&fspb.ListenRequest{
+		TargetChange: &fspb.ListenRequest_AddTarget{AddTarget: &fspb.Target{TargetId: 1}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The last stream of a test that expects no error ends only when the
+	// client closes it.
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
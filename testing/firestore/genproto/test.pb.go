@@ -479,9 +479,29 @@ func (m *GetTest) GetRequest() *v1beta1.GetDocumentRequest {
 type CreateTest struct {
 	// The path of the doc, e.g. "projects/projectID/databases/(default)/documents/C/d"
 	DocRefPath string `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
-	// The data passed to Create, as JSON. The strings "Delete" and "ServerTimestamp"
-	// denote the two special sentinel values. Values that could be interpreted as integers
-	// (i.e. digit strings) should be treated as integers.
+	// The data passed to Create, as JSON. Five sentinel values are encoded
+	// specially:
+	//   the string "Delete" denotes the Delete sentinel;
+	//   the string "ServerTimestamp" denotes the ServerTimestamp sentinel;
+	//   an array whose first element is "ArrayUnion" or "ArrayRemove" denotes
+	//     that sentinel, applied to the remaining elements;
+	//   an array of "Increment" and a number denotes an Increment sentinel with
+	//     that operand.
+	// Values that could be interpreted as integers (i.e. digit strings) should
+	// be treated as integers.
+	// Numbers with a fraction or an exponent, like 1.0 or 1e3, are doubles even if
+	// their value is integral. A number that does not fit in its type, like
+	// 9223372036854775808 or 1e400, is an error.
+	//
+	// JSON null denotes a null value. Other Firestore values without a JSON
+	// equivalent are encoded as objects with a single key that starts with "$":
+	//   a timestamp, in RFC 3339 form: {"$timestamp": "2016-01-02T03:04:05.123456789Z"}
+	//   bytes, in standard base64:     {"$bytes": "AQID"}
+	//   a geo point:                   {"$geopoint": {"latitude": 1.5, "longitude": -2}}
+	//   a document reference:          {"$reference": "projects/p/databases/d/documents/C/d"}
+	//   NaN or an infinite double:     {"$double": "NaN"}, "Infinity" or "-Infinity"
+	// Such objects are never maps. The same encoding is used wherever a test
+	// holds JSON: in data, field values, Where clauses and cursors.
 	JsonData string `protobuf:"bytes,2,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
 	// The request that the call should generate.
 	Request *v1beta1.CommitRequest `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
@@ -0,0 +1,3626 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: v1/test.proto
+
+package tests
+
+import (
+	firestorepb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type DocChange_Kind int32
+
+const (
+	DocChange_KIND_UNSPECIFIED DocChange_Kind = 0
+	DocChange_ADDED            DocChange_Kind = 1
+	DocChange_REMOVED          DocChange_Kind = 2
+	DocChange_MODIFIED         DocChange_Kind = 3
+)
+
+var DocChange_Kind_name = map[int32]string{
+	0: "KIND_UNSPECIFIED",
+	1: "ADDED",
+	2: "REMOVED",
+	3: "MODIFIED",
+}
+
+var DocChange_Kind_value = map[string]int32{
+	"KIND_UNSPECIFIED": 0,
+	"ADDED":            1,
+	"REMOVED":          2,
+	"MODIFIED":         3,
+}
+
+func (x DocChange_Kind) String() string {
+	return proto.EnumName(DocChange_Kind_name, int32(x))
+}
+
+func (DocChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{35, 0}
+}
+
+// A collection of tests.
+type TestSuite struct {
+	Tests                []*Test  `protobuf:"bytes,1,rep,name=tests,proto3" json:"tests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TestSuite) Reset()         { *m = TestSuite{} }
+func (m *TestSuite) String() string { return proto.CompactTextString(m) }
+func (*TestSuite) ProtoMessage()    {}
+func (*TestSuite) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{0}
+}
+
+func (m *TestSuite) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TestSuite.Unmarshal(m, b)
+}
+func (m *TestSuite) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TestSuite.Marshal(b, m, deterministic)
+}
+func (m *TestSuite) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TestSuite.Merge(m, src)
+}
+func (m *TestSuite) XXX_Size() int {
+	return xxx_messageInfo_TestSuite.Size(m)
+}
+func (m *TestSuite) XXX_DiscardUnknown() {
+	xxx_messageInfo_TestSuite.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TestSuite proto.InternalMessageInfo
+
+func (m *TestSuite) GetTests() []*Test {
+	if m != nil {
+		return m.Tests
+	}
+	return nil
+}
+
+// A Test describes a single client method call and its expected result.
+//
+// The call is made with a client connected to the database that the test's
+// paths are in. Most tests use the default database of project "projectID",
+// but some use a named database or another project.
+type Test struct {
+	Description string `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	// Types that are valid to be assigned to Test:
+	//	*Test_Get
+	//	*Test_Create
+	//	*Test_Set
+	//	*Test_Update
+	//	*Test_UpdatePaths
+	//	*Test_Delete
+	//	*Test_Query
+	//	*Test_Listen
+	//	*Test_Batch
+	//	*Test_Transaction
+	//	*Test_QueryResults
+	//	*Test_GetAll
+	//	*Test_AggregationQuery
+	//	*Test_Object
+	//	*Test_Decode
+	//	*Test_FieldPath
+	//	*Test_Path
+	Test                 isTest_Test `protobuf_oneof:"test"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Test) Reset()         { *m = Test{} }
+func (m *Test) String() string { return proto.CompactTextString(m) }
+func (*Test) ProtoMessage()    {}
+func (*Test) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{1}
+}
+
+func (m *Test) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Test.Unmarshal(m, b)
+}
+func (m *Test) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Test.Marshal(b, m, deterministic)
+}
+func (m *Test) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Test.Merge(m, src)
+}
+func (m *Test) XXX_Size() int {
+	return xxx_messageInfo_Test.Size(m)
+}
+func (m *Test) XXX_DiscardUnknown() {
+	xxx_messageInfo_Test.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Test proto.InternalMessageInfo
+
+func (m *Test) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type isTest_Test interface {
+	isTest_Test()
+}
+
+type Test_Get struct {
+	Get *GetTest `protobuf:"bytes,2,opt,name=get,proto3,oneof"`
+}
+
+type Test_Create struct {
+	Create *CreateTest `protobuf:"bytes,3,opt,name=create,proto3,oneof"`
+}
+
+type Test_Set struct {
+	Set *SetTest `protobuf:"bytes,4,opt,name=set,proto3,oneof"`
+}
+
+type Test_Update struct {
+	Update *UpdateTest `protobuf:"bytes,5,opt,name=update,proto3,oneof"`
+}
+
+type Test_UpdatePaths struct {
+	UpdatePaths *UpdatePathsTest `protobuf:"bytes,6,opt,name=update_paths,json=updatePaths,proto3,oneof"`
+}
+
+type Test_Delete struct {
+	Delete *DeleteTest `protobuf:"bytes,7,opt,name=delete,proto3,oneof"`
+}
+
+type Test_Query struct {
+	Query *QueryTest `protobuf:"bytes,8,opt,name=query,proto3,oneof"`
+}
+
+type Test_Listen struct {
+	Listen *ListenTest `protobuf:"bytes,9,opt,name=listen,proto3,oneof"`
+}
+
+type Test_Batch struct {
+	Batch *BatchTest `protobuf:"bytes,10,opt,name=batch,proto3,oneof"`
+}
+
+type Test_Transaction struct {
+	Transaction *TransactionTest `protobuf:"bytes,11,opt,name=transaction,proto3,oneof"`
+}
+
+type Test_QueryResults struct {
+	QueryResults *QueryResultsTest `protobuf:"bytes,12,opt,name=query_results,json=queryResults,proto3,oneof"`
+}
+
+type Test_GetAll struct {
+	GetAll *GetAllTest `protobuf:"bytes,13,opt,name=get_all,json=getAll,proto3,oneof"`
+}
+
+type Test_AggregationQuery struct {
+	AggregationQuery *AggregationQueryTest `protobuf:"bytes,14,opt,name=aggregation_query,json=aggregationQuery,proto3,oneof"`
+}
+
+type Test_Object struct {
+	Object *ObjectTest `protobuf:"bytes,15,opt,name=object,proto3,oneof"`
+}
+
+type Test_Decode struct {
+	Decode *DecodeTest `protobuf:"bytes,16,opt,name=decode,proto3,oneof"`
+}
+
+type Test_FieldPath struct {
+	FieldPath *FieldPathTest `protobuf:"bytes,17,opt,name=field_path,json=fieldPath,proto3,oneof"`
+}
+
+type Test_Path struct {
+	Path *PathTest `protobuf:"bytes,18,opt,name=path,proto3,oneof"`
+}
+
+func (*Test_Get) isTest_Test() {}
+
+func (*Test_Create) isTest_Test() {}
+
+func (*Test_Set) isTest_Test() {}
+
+func (*Test_Update) isTest_Test() {}
+
+func (*Test_UpdatePaths) isTest_Test() {}
+
+func (*Test_Delete) isTest_Test() {}
+
+func (*Test_Query) isTest_Test() {}
+
+func (*Test_Listen) isTest_Test() {}
+
+func (*Test_Batch) isTest_Test() {}
+
+func (*Test_Transaction) isTest_Test() {}
+
+func (*Test_QueryResults) isTest_Test() {}
+
+func (*Test_GetAll) isTest_Test() {}
+
+func (*Test_AggregationQuery) isTest_Test() {}
+
+func (*Test_Object) isTest_Test() {}
+
+func (*Test_Decode) isTest_Test() {}
+
+func (*Test_FieldPath) isTest_Test() {}
+
+func (*Test_Path) isTest_Test() {}
+
+func (m *Test) GetTest() isTest_Test {
+	if m != nil {
+		return m.Test
+	}
+	return nil
+}
+
+func (m *Test) GetGet() *GetTest {
+	if x, ok := m.GetTest().(*Test_Get); ok {
+		return x.Get
+	}
+	return nil
+}
+
+func (m *Test) GetCreate() *CreateTest {
+	if x, ok := m.GetTest().(*Test_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (m *Test) GetSet() *SetTest {
+	if x, ok := m.GetTest().(*Test_Set); ok {
+		return x.Set
+	}
+	return nil
+}
+
+func (m *Test) GetUpdate() *UpdateTest {
+	if x, ok := m.GetTest().(*Test_Update); ok {
+		return x.Update
+	}
+	return nil
+}
+
+func (m *Test) GetUpdatePaths() *UpdatePathsTest {
+	if x, ok := m.GetTest().(*Test_UpdatePaths); ok {
+		return x.UpdatePaths
+	}
+	return nil
+}
+
+func (m *Test) GetDelete() *DeleteTest {
+	if x, ok := m.GetTest().(*Test_Delete); ok {
+		return x.Delete
+	}
+	return nil
+}
+
+func (m *Test) GetQuery() *QueryTest {
+	if x, ok := m.GetTest().(*Test_Query); ok {
+		return x.Query
+	}
+	return nil
+}
+
+func (m *Test) GetListen() *ListenTest {
+	if x, ok := m.GetTest().(*Test_Listen); ok {
+		return x.Listen
+	}
+	return nil
+}
+
+func (m *Test) GetBatch() *BatchTest {
+	if x, ok := m.GetTest().(*Test_Batch); ok {
+		return x.Batch
+	}
+	return nil
+}
+
+func (m *Test) GetTransaction() *TransactionTest {
+	if x, ok := m.GetTest().(*Test_Transaction); ok {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (m *Test) GetQueryResults() *QueryResultsTest {
+	if x, ok := m.GetTest().(*Test_QueryResults); ok {
+		return x.QueryResults
+	}
+	return nil
+}
+
+func (m *Test) GetGetAll() *GetAllTest {
+	if x, ok := m.GetTest().(*Test_GetAll); ok {
+		return x.GetAll
+	}
+	return nil
+}
+
+func (m *Test) GetAggregationQuery() *AggregationQueryTest {
+	if x, ok := m.GetTest().(*Test_AggregationQuery); ok {
+		return x.AggregationQuery
+	}
+	return nil
+}
+
+func (m *Test) GetObject() *ObjectTest {
+	if x, ok := m.GetTest().(*Test_Object); ok {
+		return x.Object
+	}
+	return nil
+}
+
+func (m *Test) GetDecode() *DecodeTest {
+	if x, ok := m.GetTest().(*Test_Decode); ok {
+		return x.Decode
+	}
+	return nil
+}
+
+func (m *Test) GetFieldPath() *FieldPathTest {
+	if x, ok := m.GetTest().(*Test_FieldPath); ok {
+		return x.FieldPath
+	}
+	return nil
+}
+
+func (m *Test) GetPath() *PathTest {
+	if x, ok := m.GetTest().(*Test_Path); ok {
+		return x.Path
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Test) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Test_Get)(nil),
+		(*Test_Create)(nil),
+		(*Test_Set)(nil),
+		(*Test_Update)(nil),
+		(*Test_UpdatePaths)(nil),
+		(*Test_Delete)(nil),
+		(*Test_Query)(nil),
+		(*Test_Listen)(nil),
+		(*Test_Batch)(nil),
+		(*Test_Transaction)(nil),
+		(*Test_QueryResults)(nil),
+		(*Test_GetAll)(nil),
+		(*Test_AggregationQuery)(nil),
+		(*Test_Object)(nil),
+		(*Test_Decode)(nil),
+		(*Test_FieldPath)(nil),
+		(*Test_Path)(nil),
+	}
+}
+
+// Call to the DocumentRef.Get method.
+type GetTest struct {
+	// The path of the doc, e.g. "projects/projectID/databases/(default)/documents/C/d"
+	DocRefPath string `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	// The request that the call should send to the Firestore service.
+	Request              *firestorepb.GetDocumentRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                        `json:"-"`
+	XXX_unrecognized     []byte                          `json:"-"`
+	XXX_sizecache        int32                           `json:"-"`
+}
+
+func (m *GetTest) Reset()         { *m = GetTest{} }
+func (m *GetTest) String() string { return proto.CompactTextString(m) }
+func (*GetTest) ProtoMessage()    {}
+func (*GetTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{2}
+}
+
+func (m *GetTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTest.Unmarshal(m, b)
+}
+func (m *GetTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTest.Marshal(b, m, deterministic)
+}
+func (m *GetTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTest.Merge(m, src)
+}
+func (m *GetTest) XXX_Size() int {
+	return xxx_messageInfo_GetTest.Size(m)
+}
+func (m *GetTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTest proto.InternalMessageInfo
+
+func (m *GetTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *GetTest) GetRequest() *firestorepb.GetDocumentRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+// A call to GetAll, which reads several documents with BatchGetDocuments.
+// The service replies to the request with the given responses, which may
+// arrive in any order.
+type GetAllTest struct {
+	DocRefPaths []string     `protobuf:"bytes,1,rep,name=doc_ref_paths,json=docRefPaths,proto3" json:"doc_ref_paths,omitempty"`
+	FieldMask   []*FieldPath `protobuf:"bytes,2,rep,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	Transaction []byte       `protobuf:"bytes,3,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// The request that the call should send. It names each document once, in
+	// the order first passed.
+	Request   *firestorepb.BatchGetDocumentsRequest    `protobuf:"bytes,4,opt,name=request,proto3" json:"request,omitempty"`
+	Responses []*firestorepb.BatchGetDocumentsResponse `protobuf:"bytes,5,rep,name=responses,proto3" json:"responses,omitempty"`
+	// The snapshots the call should return, one for each path in doc_ref_paths.
+	Snapshots []*DocumentSnapshot `protobuf:"bytes,6,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	// If true, the stream ends with an error after the responses, and the call
+	// should signal an error instead of returning snapshots.
+	IsError              bool     `protobuf:"varint,7,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAllTest) Reset()         { *m = GetAllTest{} }
+func (m *GetAllTest) String() string { return proto.CompactTextString(m) }
+func (*GetAllTest) ProtoMessage()    {}
+func (*GetAllTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{3}
+}
+
+func (m *GetAllTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAllTest.Unmarshal(m, b)
+}
+func (m *GetAllTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAllTest.Marshal(b, m, deterministic)
+}
+func (m *GetAllTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAllTest.Merge(m, src)
+}
+func (m *GetAllTest) XXX_Size() int {
+	return xxx_messageInfo_GetAllTest.Size(m)
+}
+func (m *GetAllTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAllTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAllTest proto.InternalMessageInfo
+
+func (m *GetAllTest) GetDocRefPaths() []string {
+	if m != nil {
+		return m.DocRefPaths
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetFieldMask() []*FieldPath {
+	if m != nil {
+		return m.FieldMask
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetRequest() *firestorepb.BatchGetDocumentsRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetResponses() []*firestorepb.BatchGetDocumentsResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetSnapshots() []*DocumentSnapshot {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+func (m *GetAllTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// Call to DocumentRef.Create.
+type CreateTest struct {
+	// The path of the doc, e.g. "projects/projectID/databases/(default)/documents/C/d"
+	DocRefPath string `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	// The data passed to Create, as JSON. Five sentinel values are encoded
+	// specially:
+	//   the string "Delete" denotes the Delete sentinel;
+	//   the string "ServerTimestamp" denotes the ServerTimestamp sentinel;
+	//   an array whose first element is "ArrayUnion" or "ArrayRemove" denotes
+	//     that sentinel, applied to the remaining elements;
+	//   an array of "Increment" and a number denotes an Increment sentinel with
+	//     that operand.
+	// Values that could be interpreted as integers (i.e. digit strings) should
+	// be treated as integers.
+	// Numbers with a fraction or an exponent, like 1.0 or 1e3, are doubles even if
+	// their value is integral. A number that does not fit in its type, like
+	// 9223372036854775808 or 1e400, is an error.
+	//
+	// JSON null denotes a null value. Other Firestore values without a JSON
+	// equivalent are encoded as objects with a single key that starts with "$":
+	//   a timestamp, in RFC 3339 form: {"$timestamp": "2016-01-02T03:04:05.123456789Z"}
+	//   bytes, in standard base64:     {"$bytes": "AQID"}
+	//   a geo point:                   {"$geopoint": {"latitude": 1.5, "longitude": -2}}
+	//   a document reference:          {"$reference": "projects/p/databases/d/documents/C/d"}
+	//   NaN or an infinite double:     {"$double": "NaN"}, "Infinity" or "-Infinity"
+	// Such objects are never maps. The same encoding is used wherever a test
+	// holds JSON: in data, field values, Where clauses and cursors.
+	JsonData string `protobuf:"bytes,2,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	// The request that the call should generate.
+	Request *firestorepb.CommitRequest `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
+	// If true, the call should result in an error without generating a request.
+	// If this is true, request should not be set.
+	IsError              bool     `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateTest) Reset()         { *m = CreateTest{} }
+func (m *CreateTest) String() string { return proto.CompactTextString(m) }
+func (*CreateTest) ProtoMessage()    {}
+func (*CreateTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{4}
+}
+
+func (m *CreateTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateTest.Unmarshal(m, b)
+}
+func (m *CreateTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateTest.Marshal(b, m, deterministic)
+}
+func (m *CreateTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateTest.Merge(m, src)
+}
+func (m *CreateTest) XXX_Size() int {
+	return xxx_messageInfo_CreateTest.Size(m)
+}
+func (m *CreateTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateTest proto.InternalMessageInfo
+
+func (m *CreateTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *CreateTest) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+func (m *CreateTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *CreateTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A call to DocumentRef.Set.
+type SetTest struct {
+	DocRefPath           string                     `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	Option               *SetOption                 `protobuf:"bytes,2,opt,name=option,proto3" json:"option,omitempty"`
+	JsonData             string                     `protobuf:"bytes,3,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	Request              *firestorepb.CommitRequest `protobuf:"bytes,4,opt,name=request,proto3" json:"request,omitempty"`
+	IsError              bool                       `protobuf:"varint,5,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *SetTest) Reset()         { *m = SetTest{} }
+func (m *SetTest) String() string { return proto.CompactTextString(m) }
+func (*SetTest) ProtoMessage()    {}
+func (*SetTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{5}
+}
+
+func (m *SetTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetTest.Unmarshal(m, b)
+}
+func (m *SetTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetTest.Marshal(b, m, deterministic)
+}
+func (m *SetTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetTest.Merge(m, src)
+}
+func (m *SetTest) XXX_Size() int {
+	return xxx_messageInfo_SetTest.Size(m)
+}
+func (m *SetTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetTest proto.InternalMessageInfo
+
+func (m *SetTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *SetTest) GetOption() *SetOption {
+	if m != nil {
+		return m.Option
+	}
+	return nil
+}
+
+func (m *SetTest) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+func (m *SetTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *SetTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A call to the form of DocumentRef.Update that represents the data as a map
+// or dictionary.
+type UpdateTest struct {
+	DocRefPath           string                     `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	Precondition         *firestorepb.Precondition  `protobuf:"bytes,2,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	JsonData             string                     `protobuf:"bytes,3,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	Request              *firestorepb.CommitRequest `protobuf:"bytes,4,opt,name=request,proto3" json:"request,omitempty"`
+	IsError              bool                       `protobuf:"varint,5,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *UpdateTest) Reset()         { *m = UpdateTest{} }
+func (m *UpdateTest) String() string { return proto.CompactTextString(m) }
+func (*UpdateTest) ProtoMessage()    {}
+func (*UpdateTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{6}
+}
+
+func (m *UpdateTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateTest.Unmarshal(m, b)
+}
+func (m *UpdateTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateTest.Marshal(b, m, deterministic)
+}
+func (m *UpdateTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateTest.Merge(m, src)
+}
+func (m *UpdateTest) XXX_Size() int {
+	return xxx_messageInfo_UpdateTest.Size(m)
+}
+func (m *UpdateTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateTest proto.InternalMessageInfo
+
+func (m *UpdateTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *UpdateTest) GetPrecondition() *firestorepb.Precondition {
+	if m != nil {
+		return m.Precondition
+	}
+	return nil
+}
+
+func (m *UpdateTest) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+func (m *UpdateTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *UpdateTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A call to the form of DocumentRef.Update that represents the data as a list
+// of field paths and their values.
+type UpdatePathsTest struct {
+	DocRefPath   string                    `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	Precondition *firestorepb.Precondition `protobuf:"bytes,2,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	// parallel sequences: field_paths[i] corresponds to json_values[i]
+	FieldPaths           []*FieldPath               `protobuf:"bytes,3,rep,name=field_paths,json=fieldPaths,proto3" json:"field_paths,omitempty"`
+	JsonValues           []string                   `protobuf:"bytes,4,rep,name=json_values,json=jsonValues,proto3" json:"json_values,omitempty"`
+	Request              *firestorepb.CommitRequest `protobuf:"bytes,5,opt,name=request,proto3" json:"request,omitempty"`
+	IsError              bool                       `protobuf:"varint,6,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *UpdatePathsTest) Reset()         { *m = UpdatePathsTest{} }
+func (m *UpdatePathsTest) String() string { return proto.CompactTextString(m) }
+func (*UpdatePathsTest) ProtoMessage()    {}
+func (*UpdatePathsTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{7}
+}
+
+func (m *UpdatePathsTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdatePathsTest.Unmarshal(m, b)
+}
+func (m *UpdatePathsTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdatePathsTest.Marshal(b, m, deterministic)
+}
+func (m *UpdatePathsTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdatePathsTest.Merge(m, src)
+}
+func (m *UpdatePathsTest) XXX_Size() int {
+	return xxx_messageInfo_UpdatePathsTest.Size(m)
+}
+func (m *UpdatePathsTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdatePathsTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdatePathsTest proto.InternalMessageInfo
+
+func (m *UpdatePathsTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *UpdatePathsTest) GetPrecondition() *firestorepb.Precondition {
+	if m != nil {
+		return m.Precondition
+	}
+	return nil
+}
+
+func (m *UpdatePathsTest) GetFieldPaths() []*FieldPath {
+	if m != nil {
+		return m.FieldPaths
+	}
+	return nil
+}
+
+func (m *UpdatePathsTest) GetJsonValues() []string {
+	if m != nil {
+		return m.JsonValues
+	}
+	return nil
+}
+
+func (m *UpdatePathsTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *UpdatePathsTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A call to DocmentRef.Delete
+type DeleteTest struct {
+	DocRefPath           string                     `protobuf:"bytes,1,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	Precondition         *firestorepb.Precondition  `protobuf:"bytes,2,opt,name=precondition,proto3" json:"precondition,omitempty"`
+	Request              *firestorepb.CommitRequest `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
+	IsError              bool                       `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *DeleteTest) Reset()         { *m = DeleteTest{} }
+func (m *DeleteTest) String() string { return proto.CompactTextString(m) }
+func (*DeleteTest) ProtoMessage()    {}
+func (*DeleteTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{8}
+}
+
+func (m *DeleteTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteTest.Unmarshal(m, b)
+}
+func (m *DeleteTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteTest.Marshal(b, m, deterministic)
+}
+func (m *DeleteTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteTest.Merge(m, src)
+}
+func (m *DeleteTest) XXX_Size() int {
+	return xxx_messageInfo_DeleteTest.Size(m)
+}
+func (m *DeleteTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteTest proto.InternalMessageInfo
+
+func (m *DeleteTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *DeleteTest) GetPrecondition() *firestorepb.Precondition {
+	if m != nil {
+		return m.Precondition
+	}
+	return nil
+}
+
+func (m *DeleteTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *DeleteTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// An option to the DocumentRef.Set call.
+type SetOption struct {
+	All                  bool         `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	Fields               []*FieldPath `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *SetOption) Reset()         { *m = SetOption{} }
+func (m *SetOption) String() string { return proto.CompactTextString(m) }
+func (*SetOption) ProtoMessage()    {}
+func (*SetOption) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{9}
+}
+
+func (m *SetOption) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetOption.Unmarshal(m, b)
+}
+func (m *SetOption) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetOption.Marshal(b, m, deterministic)
+}
+func (m *SetOption) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetOption.Merge(m, src)
+}
+func (m *SetOption) XXX_Size() int {
+	return xxx_messageInfo_SetOption.Size(m)
+}
+func (m *SetOption) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetOption.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetOption proto.InternalMessageInfo
+
+func (m *SetOption) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
+func (m *SetOption) GetFields() []*FieldPath {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type QueryTest struct {
+	CollPath string                       `protobuf:"bytes,1,opt,name=coll_path,json=collPath,proto3" json:"coll_path,omitempty"`
+	Clauses  []*Clause                    `protobuf:"bytes,2,rep,name=clauses,proto3" json:"clauses,omitempty"`
+	Query    *firestorepb.StructuredQuery `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	IsError  bool                         `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	// If true, the query is a collection group query: it is over every
+	// collection in the database whose ID is the last component of coll_path,
+	// and its parent is the root of the database.
+	CollectionGroup      bool     `protobuf:"varint,5,opt,name=collection_group,json=collectionGroup,proto3" json:"collection_group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryTest) Reset()         { *m = QueryTest{} }
+func (m *QueryTest) String() string { return proto.CompactTextString(m) }
+func (*QueryTest) ProtoMessage()    {}
+func (*QueryTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{10}
+}
+
+func (m *QueryTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryTest.Unmarshal(m, b)
+}
+func (m *QueryTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryTest.Marshal(b, m, deterministic)
+}
+func (m *QueryTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryTest.Merge(m, src)
+}
+func (m *QueryTest) XXX_Size() int {
+	return xxx_messageInfo_QueryTest.Size(m)
+}
+func (m *QueryTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryTest proto.InternalMessageInfo
+
+func (m *QueryTest) GetCollPath() string {
+	if m != nil {
+		return m.CollPath
+	}
+	return ""
+}
+
+func (m *QueryTest) GetClauses() []*Clause {
+	if m != nil {
+		return m.Clauses
+	}
+	return nil
+}
+
+func (m *QueryTest) GetQuery() *firestorepb.StructuredQuery {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *QueryTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+func (m *QueryTest) GetCollectionGroup() bool {
+	if m != nil {
+		return m.CollectionGroup
+	}
+	return false
+}
+
+type Clause struct {
+	// Types that are valid to be assigned to Clause:
+	//	*Clause_Select
+	//	*Clause_Where
+	//	*Clause_OrderBy
+	//	*Clause_Offset
+	//	*Clause_Limit
+	//	*Clause_StartAt
+	//	*Clause_StartAfter
+	//	*Clause_EndAt
+	//	*Clause_EndBefore
+	//	*Clause_Filter
+	//	*Clause_LimitToLast
+	Clause               isClause_Clause `protobuf_oneof:"clause"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Clause) Reset()         { *m = Clause{} }
+func (m *Clause) String() string { return proto.CompactTextString(m) }
+func (*Clause) ProtoMessage()    {}
+func (*Clause) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{11}
+}
+
+func (m *Clause) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Clause.Unmarshal(m, b)
+}
+func (m *Clause) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Clause.Marshal(b, m, deterministic)
+}
+func (m *Clause) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Clause.Merge(m, src)
+}
+func (m *Clause) XXX_Size() int {
+	return xxx_messageInfo_Clause.Size(m)
+}
+func (m *Clause) XXX_DiscardUnknown() {
+	xxx_messageInfo_Clause.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Clause proto.InternalMessageInfo
+
+type isClause_Clause interface {
+	isClause_Clause()
+}
+
+type Clause_Select struct {
+	Select *Select `protobuf:"bytes,1,opt,name=select,proto3,oneof"`
+}
+
+type Clause_Where struct {
+	Where *Where `protobuf:"bytes,2,opt,name=where,proto3,oneof"`
+}
+
+type Clause_OrderBy struct {
+	OrderBy *OrderBy `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3,oneof"`
+}
+
+type Clause_Offset struct {
+	Offset int32 `protobuf:"varint,4,opt,name=offset,proto3,oneof"`
+}
+
+type Clause_Limit struct {
+	Limit int32 `protobuf:"varint,5,opt,name=limit,proto3,oneof"`
+}
+
+type Clause_StartAt struct {
+	StartAt *Cursor `protobuf:"bytes,6,opt,name=start_at,json=startAt,proto3,oneof"`
+}
+
+type Clause_StartAfter struct {
+	StartAfter *Cursor `protobuf:"bytes,7,opt,name=start_after,json=startAfter,proto3,oneof"`
+}
+
+type Clause_EndAt struct {
+	EndAt *Cursor `protobuf:"bytes,8,opt,name=end_at,json=endAt,proto3,oneof"`
+}
+
+type Clause_EndBefore struct {
+	EndBefore *Cursor `protobuf:"bytes,9,opt,name=end_before,json=endBefore,proto3,oneof"`
+}
+
+type Clause_Filter struct {
+	Filter *Filter `protobuf:"bytes,10,opt,name=filter,proto3,oneof"`
+}
+
+type Clause_LimitToLast struct {
+	LimitToLast int32 `protobuf:"varint,11,opt,name=limit_to_last,json=limitToLast,proto3,oneof"`
+}
+
+func (*Clause_Select) isClause_Clause() {}
+
+func (*Clause_Where) isClause_Clause() {}
+
+func (*Clause_OrderBy) isClause_Clause() {}
+
+func (*Clause_Offset) isClause_Clause() {}
+
+func (*Clause_Limit) isClause_Clause() {}
+
+func (*Clause_StartAt) isClause_Clause() {}
+
+func (*Clause_StartAfter) isClause_Clause() {}
+
+func (*Clause_EndAt) isClause_Clause() {}
+
+func (*Clause_EndBefore) isClause_Clause() {}
+
+func (*Clause_Filter) isClause_Clause() {}
+
+func (*Clause_LimitToLast) isClause_Clause() {}
+
+func (m *Clause) GetClause() isClause_Clause {
+	if m != nil {
+		return m.Clause
+	}
+	return nil
+}
+
+func (m *Clause) GetSelect() *Select {
+	if x, ok := m.GetClause().(*Clause_Select); ok {
+		return x.Select
+	}
+	return nil
+}
+
+func (m *Clause) GetWhere() *Where {
+	if x, ok := m.GetClause().(*Clause_Where); ok {
+		return x.Where
+	}
+	return nil
+}
+
+func (m *Clause) GetOrderBy() *OrderBy {
+	if x, ok := m.GetClause().(*Clause_OrderBy); ok {
+		return x.OrderBy
+	}
+	return nil
+}
+
+func (m *Clause) GetOffset() int32 {
+	if x, ok := m.GetClause().(*Clause_Offset); ok {
+		return x.Offset
+	}
+	return 0
+}
+
+func (m *Clause) GetLimit() int32 {
+	if x, ok := m.GetClause().(*Clause_Limit); ok {
+		return x.Limit
+	}
+	return 0
+}
+
+func (m *Clause) GetStartAt() *Cursor {
+	if x, ok := m.GetClause().(*Clause_StartAt); ok {
+		return x.StartAt
+	}
+	return nil
+}
+
+func (m *Clause) GetStartAfter() *Cursor {
+	if x, ok := m.GetClause().(*Clause_StartAfter); ok {
+		return x.StartAfter
+	}
+	return nil
+}
+
+func (m *Clause) GetEndAt() *Cursor {
+	if x, ok := m.GetClause().(*Clause_EndAt); ok {
+		return x.EndAt
+	}
+	return nil
+}
+
+func (m *Clause) GetEndBefore() *Cursor {
+	if x, ok := m.GetClause().(*Clause_EndBefore); ok {
+		return x.EndBefore
+	}
+	return nil
+}
+
+func (m *Clause) GetFilter() *Filter {
+	if x, ok := m.GetClause().(*Clause_Filter); ok {
+		return x.Filter
+	}
+	return nil
+}
+
+func (m *Clause) GetLimitToLast() int32 {
+	if x, ok := m.GetClause().(*Clause_LimitToLast); ok {
+		return x.LimitToLast
+	}
+	return 0
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Clause) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Clause_Select)(nil),
+		(*Clause_Where)(nil),
+		(*Clause_OrderBy)(nil),
+		(*Clause_Offset)(nil),
+		(*Clause_Limit)(nil),
+		(*Clause_StartAt)(nil),
+		(*Clause_StartAfter)(nil),
+		(*Clause_EndAt)(nil),
+		(*Clause_EndBefore)(nil),
+		(*Clause_Filter)(nil),
+		(*Clause_LimitToLast)(nil),
+	}
+}
+
+type Select struct {
+	Fields               []*FieldPath `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Select) Reset()         { *m = Select{} }
+func (m *Select) String() string { return proto.CompactTextString(m) }
+func (*Select) ProtoMessage()    {}
+func (*Select) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{12}
+}
+
+func (m *Select) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Select.Unmarshal(m, b)
+}
+func (m *Select) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Select.Marshal(b, m, deterministic)
+}
+func (m *Select) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Select.Merge(m, src)
+}
+func (m *Select) XXX_Size() int {
+	return xxx_messageInfo_Select.Size(m)
+}
+func (m *Select) XXX_DiscardUnknown() {
+	xxx_messageInfo_Select.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Select proto.InternalMessageInfo
+
+func (m *Select) GetFields() []*FieldPath {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type Where struct {
+	Path *FieldPath `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// One of "<", "<=", "==", "!=", ">=", ">", "array-contains",
+	// "array-contains-any", "in" or "not-in".
+	Op string `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`
+	// For "array-contains-any", "in" and "not-in", a JSON list.
+	JsonValue            string   `protobuf:"bytes,3,opt,name=json_value,json=jsonValue,proto3" json:"json_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Where) Reset()         { *m = Where{} }
+func (m *Where) String() string { return proto.CompactTextString(m) }
+func (*Where) ProtoMessage()    {}
+func (*Where) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{13}
+}
+
+func (m *Where) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Where.Unmarshal(m, b)
+}
+func (m *Where) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Where.Marshal(b, m, deterministic)
+}
+func (m *Where) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Where.Merge(m, src)
+}
+func (m *Where) XXX_Size() int {
+	return xxx_messageInfo_Where.Size(m)
+}
+func (m *Where) XXX_DiscardUnknown() {
+	xxx_messageInfo_Where.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Where proto.InternalMessageInfo
+
+func (m *Where) GetPath() *FieldPath {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+func (m *Where) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *Where) GetJsonValue() string {
+	if m != nil {
+		return m.JsonValue
+	}
+	return ""
+}
+
+// A filter passed to Query.Where: either a single condition, or a composite
+// built with Filter.and or Filter.or.
+type Filter struct {
+	// Types that are valid to be assigned to Filter:
+	//	*Filter_Where
+	//	*Filter_Composite
+	Filter               isFilter_Filter `protobuf_oneof:"filter"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Filter) Reset()         { *m = Filter{} }
+func (m *Filter) String() string { return proto.CompactTextString(m) }
+func (*Filter) ProtoMessage()    {}
+func (*Filter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{14}
+}
+
+func (m *Filter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Filter.Unmarshal(m, b)
+}
+func (m *Filter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Filter.Marshal(b, m, deterministic)
+}
+func (m *Filter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Filter.Merge(m, src)
+}
+func (m *Filter) XXX_Size() int {
+	return xxx_messageInfo_Filter.Size(m)
+}
+func (m *Filter) XXX_DiscardUnknown() {
+	xxx_messageInfo_Filter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Filter proto.InternalMessageInfo
+
+type isFilter_Filter interface {
+	isFilter_Filter()
+}
+
+type Filter_Where struct {
+	Where *Where `protobuf:"bytes,1,opt,name=where,proto3,oneof"`
+}
+
+type Filter_Composite struct {
+	Composite *CompositeFilter `protobuf:"bytes,2,opt,name=composite,proto3,oneof"`
+}
+
+func (*Filter_Where) isFilter_Filter() {}
+
+func (*Filter_Composite) isFilter_Filter() {}
+
+func (m *Filter) GetFilter() isFilter_Filter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *Filter) GetWhere() *Where {
+	if x, ok := m.GetFilter().(*Filter_Where); ok {
+		return x.Where
+	}
+	return nil
+}
+
+func (m *Filter) GetComposite() *CompositeFilter {
+	if x, ok := m.GetFilter().(*Filter_Composite); ok {
+		return x.Composite
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Filter) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Filter_Where)(nil),
+		(*Filter_Composite)(nil),
+	}
+}
+
+// A filter that combines other filters.
+type CompositeFilter struct {
+	Op                   string    `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Filters              []*Filter `protobuf:"bytes,2,rep,name=filters,proto3" json:"filters,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CompositeFilter) Reset()         { *m = CompositeFilter{} }
+func (m *CompositeFilter) String() string { return proto.CompactTextString(m) }
+func (*CompositeFilter) ProtoMessage()    {}
+func (*CompositeFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{15}
+}
+
+func (m *CompositeFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompositeFilter.Unmarshal(m, b)
+}
+func (m *CompositeFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompositeFilter.Marshal(b, m, deterministic)
+}
+func (m *CompositeFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompositeFilter.Merge(m, src)
+}
+func (m *CompositeFilter) XXX_Size() int {
+	return xxx_messageInfo_CompositeFilter.Size(m)
+}
+func (m *CompositeFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompositeFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompositeFilter proto.InternalMessageInfo
+
+func (m *CompositeFilter) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *CompositeFilter) GetFilters() []*Filter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+type OrderBy struct {
+	Path                 *FieldPath `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Direction            string     `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *OrderBy) Reset()         { *m = OrderBy{} }
+func (m *OrderBy) String() string { return proto.CompactTextString(m) }
+func (*OrderBy) ProtoMessage()    {}
+func (*OrderBy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{16}
+}
+
+func (m *OrderBy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OrderBy.Unmarshal(m, b)
+}
+func (m *OrderBy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OrderBy.Marshal(b, m, deterministic)
+}
+func (m *OrderBy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OrderBy.Merge(m, src)
+}
+func (m *OrderBy) XXX_Size() int {
+	return xxx_messageInfo_OrderBy.Size(m)
+}
+func (m *OrderBy) XXX_DiscardUnknown() {
+	xxx_messageInfo_OrderBy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OrderBy proto.InternalMessageInfo
+
+func (m *OrderBy) GetPath() *FieldPath {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+func (m *OrderBy) GetDirection() string {
+	if m != nil {
+		return m.Direction
+	}
+	return ""
+}
+
+type Cursor struct {
+	// one of:
+	DocSnapshot          *DocSnapshot `protobuf:"bytes,1,opt,name=doc_snapshot,json=docSnapshot,proto3" json:"doc_snapshot,omitempty"`
+	JsonValues           []string     `protobuf:"bytes,2,rep,name=json_values,json=jsonValues,proto3" json:"json_values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Cursor) Reset()         { *m = Cursor{} }
+func (m *Cursor) String() string { return proto.CompactTextString(m) }
+func (*Cursor) ProtoMessage()    {}
+func (*Cursor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{17}
+}
+
+func (m *Cursor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Cursor.Unmarshal(m, b)
+}
+func (m *Cursor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Cursor.Marshal(b, m, deterministic)
+}
+func (m *Cursor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Cursor.Merge(m, src)
+}
+func (m *Cursor) XXX_Size() int {
+	return xxx_messageInfo_Cursor.Size(m)
+}
+func (m *Cursor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Cursor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Cursor proto.InternalMessageInfo
+
+func (m *Cursor) GetDocSnapshot() *DocSnapshot {
+	if m != nil {
+		return m.DocSnapshot
+	}
+	return nil
+}
+
+func (m *Cursor) GetJsonValues() []string {
+	if m != nil {
+		return m.JsonValues
+	}
+	return nil
+}
+
+type DocSnapshot struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	JsonData             string   `protobuf:"bytes,2,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DocSnapshot) Reset()         { *m = DocSnapshot{} }
+func (m *DocSnapshot) String() string { return proto.CompactTextString(m) }
+func (*DocSnapshot) ProtoMessage()    {}
+func (*DocSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{18}
+}
+
+func (m *DocSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DocSnapshot.Unmarshal(m, b)
+}
+func (m *DocSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DocSnapshot.Marshal(b, m, deterministic)
+}
+func (m *DocSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocSnapshot.Merge(m, src)
+}
+func (m *DocSnapshot) XXX_Size() int {
+	return xxx_messageInfo_DocSnapshot.Size(m)
+}
+func (m *DocSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DocSnapshot proto.InternalMessageInfo
+
+func (m *DocSnapshot) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *DocSnapshot) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+type FieldPath struct {
+	Field                []string `protobuf:"bytes,1,rep,name=field,proto3" json:"field,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldPath) Reset()         { *m = FieldPath{} }
+func (m *FieldPath) String() string { return proto.CompactTextString(m) }
+func (*FieldPath) ProtoMessage()    {}
+func (*FieldPath) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{19}
+}
+
+func (m *FieldPath) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldPath.Unmarshal(m, b)
+}
+func (m *FieldPath) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldPath.Marshal(b, m, deterministic)
+}
+func (m *FieldPath) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldPath.Merge(m, src)
+}
+func (m *FieldPath) XXX_Size() int {
+	return xxx_messageInfo_FieldPath.Size(m)
+}
+func (m *FieldPath) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldPath.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldPath proto.InternalMessageInfo
+
+func (m *FieldPath) GetField() []string {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+// A test of how a client builds a reference to a document or collection from
+// a path that a user writes, as with Client.Doc or Client.Collection. The
+// path is a sequence of IDs separated by slashes, relative to the root of the
+// database; leading and trailing slashes are ignored. It may instead be the
+// fully qualified resource name of a document or collection in the client's
+// database.
+//
+// A document path has an even number of IDs, and a collection path an odd
+// number. An ID cannot be empty, "." or "..", longer than 1500 bytes, or of
+// the form __.*__, which Firestore reserves.
+type PathTest struct {
+	// The database the client is connected to, e.g.
+	// "projects/projectID/databases/(default)".
+	Database string `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	Path     string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// If true, the path names a collection. Otherwise, it names a document.
+	Collection bool `protobuf:"varint,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	// The resource name of the reference, e.g.
+	// "projects/projectID/databases/(default)/documents/C/d".
+	Name string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	// If true, the path is invalid, and the client should signal an error.
+	IsError              bool     `protobuf:"varint,5,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PathTest) Reset()         { *m = PathTest{} }
+func (m *PathTest) String() string { return proto.CompactTextString(m) }
+func (*PathTest) ProtoMessage()    {}
+func (*PathTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{20}
+}
+
+func (m *PathTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PathTest.Unmarshal(m, b)
+}
+func (m *PathTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PathTest.Marshal(b, m, deterministic)
+}
+func (m *PathTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PathTest.Merge(m, src)
+}
+func (m *PathTest) XXX_Size() int {
+	return xxx_messageInfo_PathTest.Size(m)
+}
+func (m *PathTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PathTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PathTest proto.InternalMessageInfo
+
+func (m *PathTest) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+func (m *PathTest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *PathTest) GetCollection() bool {
+	if m != nil {
+		return m.Collection
+	}
+	return false
+}
+
+func (m *PathTest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PathTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A test of how a client parses a field path that a user writes as a single
+// string, with its components separated by dots. A component may be quoted
+// with backquotes, so that it can hold any character; inside the quotes, a
+// backslash escapes the character that follows it. Outside quotes, a
+// component cannot hold any of the characters "~*/[]`". No component may be
+// empty.
+type FieldPathTest struct {
+	Path      string     `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	FieldPath *FieldPath `protobuf:"bytes,2,opt,name=field_path,json=fieldPath,proto3" json:"field_path,omitempty"`
+	// The path as it appears in requests, for example in a DocumentMask or a
+	// StructuredQuery.FieldReference. A component is written as is if it is
+	// made of ASCII letters, digits and underscores and does not begin with a
+	// digit. Otherwise it is quoted with backquotes, and the backquotes and
+	// backslashes in it are escaped with a backslash.
+	Encoded string `protobuf:"bytes,3,opt,name=encoded,proto3" json:"encoded,omitempty"`
+	// If true, path is not a valid field path, and field_path and encoded are
+	// not set.
+	IsError              bool     `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldPathTest) Reset()         { *m = FieldPathTest{} }
+func (m *FieldPathTest) String() string { return proto.CompactTextString(m) }
+func (*FieldPathTest) ProtoMessage()    {}
+func (*FieldPathTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{21}
+}
+
+func (m *FieldPathTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldPathTest.Unmarshal(m, b)
+}
+func (m *FieldPathTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldPathTest.Marshal(b, m, deterministic)
+}
+func (m *FieldPathTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldPathTest.Merge(m, src)
+}
+func (m *FieldPathTest) XXX_Size() int {
+	return xxx_messageInfo_FieldPathTest.Size(m)
+}
+func (m *FieldPathTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldPathTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldPathTest proto.InternalMessageInfo
+
+func (m *FieldPathTest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FieldPathTest) GetFieldPath() *FieldPath {
+	if m != nil {
+		return m.FieldPath
+	}
+	return nil
+}
+
+func (m *FieldPathTest) GetEncoded() string {
+	if m != nil {
+		return m.Encoded
+	}
+	return ""
+}
+
+func (m *FieldPathTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// An aggregation query, built from the query that coll_path and clauses
+// describe as in QueryTest, and the given aggregations. The service replies
+// to RunAggregationQuery with the given responses; the one that holds a
+// result carries the aggregate values by alias.
+type AggregationQueryTest struct {
+	CollPath     string         `protobuf:"bytes,1,opt,name=coll_path,json=collPath,proto3" json:"coll_path,omitempty"`
+	Clauses      []*Clause      `protobuf:"bytes,2,rep,name=clauses,proto3" json:"clauses,omitempty"`
+	Aggregations []*Aggregation `protobuf:"bytes,3,rep,name=aggregations,proto3" json:"aggregations,omitempty"`
+	// The query the call should send.
+	Query     *firestorepb.StructuredAggregationQuery    `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	Responses []*firestorepb.RunAggregationQueryResponse `protobuf:"bytes,5,rep,name=responses,proto3" json:"responses,omitempty"`
+	// The aggregate values the call should return, by alias.
+	Result map[string]*firestorepb.Value `protobuf:"bytes,6,rep,name=result,proto3" json:"result,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// If true, the call should signal an error without sending a request.
+	IsError              bool     `protobuf:"varint,7,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AggregationQueryTest) Reset()         { *m = AggregationQueryTest{} }
+func (m *AggregationQueryTest) String() string { return proto.CompactTextString(m) }
+func (*AggregationQueryTest) ProtoMessage()    {}
+func (*AggregationQueryTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{22}
+}
+
+func (m *AggregationQueryTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AggregationQueryTest.Unmarshal(m, b)
+}
+func (m *AggregationQueryTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AggregationQueryTest.Marshal(b, m, deterministic)
+}
+func (m *AggregationQueryTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AggregationQueryTest.Merge(m, src)
+}
+func (m *AggregationQueryTest) XXX_Size() int {
+	return xxx_messageInfo_AggregationQueryTest.Size(m)
+}
+func (m *AggregationQueryTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AggregationQueryTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AggregationQueryTest proto.InternalMessageInfo
+
+func (m *AggregationQueryTest) GetCollPath() string {
+	if m != nil {
+		return m.CollPath
+	}
+	return ""
+}
+
+func (m *AggregationQueryTest) GetClauses() []*Clause {
+	if m != nil {
+		return m.Clauses
+	}
+	return nil
+}
+
+func (m *AggregationQueryTest) GetAggregations() []*Aggregation {
+	if m != nil {
+		return m.Aggregations
+	}
+	return nil
+}
+
+func (m *AggregationQueryTest) GetQuery() *firestorepb.StructuredAggregationQuery {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *AggregationQueryTest) GetResponses() []*firestorepb.RunAggregationQueryResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func (m *AggregationQueryTest) GetResult() map[string]*firestorepb.Value {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *AggregationQueryTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// An aggregation. Every aggregation in the tests has an alias, because
+// clients choose different aliases for those without one.
+type Aggregation struct {
+	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// Types that are valid to be assigned to Aggregation:
+	//	*Aggregation_Count
+	//	*Aggregation_Sum
+	//	*Aggregation_Avg
+	Aggregation          isAggregation_Aggregation `protobuf_oneof:"aggregation"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *Aggregation) Reset()         { *m = Aggregation{} }
+func (m *Aggregation) String() string { return proto.CompactTextString(m) }
+func (*Aggregation) ProtoMessage()    {}
+func (*Aggregation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{23}
+}
+
+func (m *Aggregation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Aggregation.Unmarshal(m, b)
+}
+func (m *Aggregation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Aggregation.Marshal(b, m, deterministic)
+}
+func (m *Aggregation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Aggregation.Merge(m, src)
+}
+func (m *Aggregation) XXX_Size() int {
+	return xxx_messageInfo_Aggregation.Size(m)
+}
+func (m *Aggregation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Aggregation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Aggregation proto.InternalMessageInfo
+
+func (m *Aggregation) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type isAggregation_Aggregation interface {
+	isAggregation_Aggregation()
+}
+
+type Aggregation_Count struct {
+	Count *Count `protobuf:"bytes,2,opt,name=count,proto3,oneof"`
+}
+
+type Aggregation_Sum struct {
+	Sum *FieldPath `protobuf:"bytes,3,opt,name=sum,proto3,oneof"`
+}
+
+type Aggregation_Avg struct {
+	Avg *FieldPath `protobuf:"bytes,4,opt,name=avg,proto3,oneof"`
+}
+
+func (*Aggregation_Count) isAggregation_Aggregation() {}
+
+func (*Aggregation_Sum) isAggregation_Aggregation() {}
+
+func (*Aggregation_Avg) isAggregation_Aggregation() {}
+
+func (m *Aggregation) GetAggregation() isAggregation_Aggregation {
+	if m != nil {
+		return m.Aggregation
+	}
+	return nil
+}
+
+func (m *Aggregation) GetCount() *Count {
+	if x, ok := m.GetAggregation().(*Aggregation_Count); ok {
+		return x.Count
+	}
+	return nil
+}
+
+func (m *Aggregation) GetSum() *FieldPath {
+	if x, ok := m.GetAggregation().(*Aggregation_Sum); ok {
+		return x.Sum
+	}
+	return nil
+}
+
+func (m *Aggregation) GetAvg() *FieldPath {
+	if x, ok := m.GetAggregation().(*Aggregation_Avg); ok {
+		return x.Avg
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Aggregation) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Aggregation_Count)(nil),
+		(*Aggregation_Sum)(nil),
+		(*Aggregation_Avg)(nil),
+	}
+}
+
+type Count struct {
+	UpTo                 int64    `protobuf:"varint,1,opt,name=up_to,json=upTo,proto3" json:"up_to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Count) Reset()         { *m = Count{} }
+func (m *Count) String() string { return proto.CompactTextString(m) }
+func (*Count) ProtoMessage()    {}
+func (*Count) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{24}
+}
+
+func (m *Count) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Count.Unmarshal(m, b)
+}
+func (m *Count) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Count.Marshal(b, m, deterministic)
+}
+func (m *Count) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Count.Merge(m, src)
+}
+func (m *Count) XXX_Size() int {
+	return xxx_messageInfo_Count.Size(m)
+}
+func (m *Count) XXX_DiscardUnknown() {
+	xxx_messageInfo_Count.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Count proto.InternalMessageInfo
+
+func (m *Count) GetUpTo() int64 {
+	if m != nil {
+		return m.UpTo
+	}
+	return 0
+}
+
+// A test of how a client turns the response stream of the RunQuery RPC into
+// document snapshots. The query is built from coll_path and clauses as in
+// QueryTest. If the sequence of responses is provided to the implementation,
+// it should produce the sequence of snapshots, one for each response that
+// holds a document. Responses without a document, such as those that only
+// report a read time, skipped results or a transaction ID, produce no snapshot.
+//
+// If the query has a limit_to_last clause, the service returns the documents
+// in the reverse of the query's order, and the snapshots are in the reverse
+// order of the responses.
+//
+// If is_error is true, the stream ends with an error after the responses, and
+// the query should signal an error after producing the snapshots.
+type QueryResultsTest struct {
+	CollPath             string                          `protobuf:"bytes,1,opt,name=coll_path,json=collPath,proto3" json:"coll_path,omitempty"`
+	Clauses              []*Clause                       `protobuf:"bytes,2,rep,name=clauses,proto3" json:"clauses,omitempty"`
+	Responses            []*firestorepb.RunQueryResponse `protobuf:"bytes,3,rep,name=responses,proto3" json:"responses,omitempty"`
+	Snapshots            []*DocumentSnapshot             `protobuf:"bytes,4,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	IsError              bool                            `protobuf:"varint,5,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                        `json:"-"`
+	XXX_unrecognized     []byte                          `json:"-"`
+	XXX_sizecache        int32                           `json:"-"`
+}
+
+func (m *QueryResultsTest) Reset()         { *m = QueryResultsTest{} }
+func (m *QueryResultsTest) String() string { return proto.CompactTextString(m) }
+func (*QueryResultsTest) ProtoMessage()    {}
+func (*QueryResultsTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{25}
+}
+
+func (m *QueryResultsTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryResultsTest.Unmarshal(m, b)
+}
+func (m *QueryResultsTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryResultsTest.Marshal(b, m, deterministic)
+}
+func (m *QueryResultsTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryResultsTest.Merge(m, src)
+}
+func (m *QueryResultsTest) XXX_Size() int {
+	return xxx_messageInfo_QueryResultsTest.Size(m)
+}
+func (m *QueryResultsTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryResultsTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryResultsTest proto.InternalMessageInfo
+
+func (m *QueryResultsTest) GetCollPath() string {
+	if m != nil {
+		return m.CollPath
+	}
+	return ""
+}
+
+func (m *QueryResultsTest) GetClauses() []*Clause {
+	if m != nil {
+		return m.Clauses
+	}
+	return nil
+}
+
+func (m *QueryResultsTest) GetResponses() []*firestorepb.RunQueryResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func (m *QueryResultsTest) GetSnapshots() []*DocumentSnapshot {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+func (m *QueryResultsTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A snapshot of a single document, as read by a query or GetAll.
+type DocumentSnapshot struct {
+	Doc                  *firestorepb.Document `protobuf:"bytes,1,opt,name=doc,proto3" json:"doc,omitempty"`
+	ReadTime             *timestamp.Timestamp  `protobuf:"bytes,2,opt,name=read_time,json=readTime,proto3" json:"read_time,omitempty"`
+	Missing              bool                  `protobuf:"varint,3,opt,name=missing,proto3" json:"missing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DocumentSnapshot) Reset()         { *m = DocumentSnapshot{} }
+func (m *DocumentSnapshot) String() string { return proto.CompactTextString(m) }
+func (*DocumentSnapshot) ProtoMessage()    {}
+func (*DocumentSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{26}
+}
+
+func (m *DocumentSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DocumentSnapshot.Unmarshal(m, b)
+}
+func (m *DocumentSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DocumentSnapshot.Marshal(b, m, deterministic)
+}
+func (m *DocumentSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocumentSnapshot.Merge(m, src)
+}
+func (m *DocumentSnapshot) XXX_Size() int {
+	return xxx_messageInfo_DocumentSnapshot.Size(m)
+}
+func (m *DocumentSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocumentSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DocumentSnapshot proto.InternalMessageInfo
+
+func (m *DocumentSnapshot) GetDoc() *firestorepb.Document {
+	if m != nil {
+		return m.Doc
+	}
+	return nil
+}
+
+func (m *DocumentSnapshot) GetReadTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReadTime
+	}
+	return nil
+}
+
+func (m *DocumentSnapshot) GetMissing() bool {
+	if m != nil {
+		return m.Missing
+	}
+	return false
+}
+
+// A test of the Listen streaming RPC (a.k.a. FireStore watch).
+// If the sequence of responses is provided to the implementation,
+// it should produce the sequence of snapshots.
+// If is_error is true, an error should occur after the snapshots.
+//
+// The query is the test's clauses applied to the collection "C" under the
+// parent of query, e.g.
+// Collection("projects/projectID/databases/(default)/documents/C"),
+// or, for a test without clauses, that collection ordered by "a":
+// Collection("projects/projectID/databases/(default)/documents/C").OrderBy("a", Ascending)
+//
+// The watch target ID used in these tests is 1. Test interpreters
+// should either change their client's ID for testing,
+// or change the ID in the tests before running them.
+//
+// A test of a client that reopens its stream has streams instead of
+// responses.
+type ListenTest struct {
+	Responses []*firestorepb.ListenResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	Snapshots []*Snapshot                   `protobuf:"bytes,2,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	IsError   bool                          `protobuf:"varint,3,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	// The Listen streams that the client opens, in order. The client receives
+	// the responses of each stream, then the stream ends and the client opens
+	// the next one.
+	Streams []*ListenStream `protobuf:"bytes,4,rep,name=streams,proto3" json:"streams,omitempty"`
+	// The clauses of the query, as in QueryTest. The service sends only the
+	// documents that match the query's filters; the clauses determine the
+	// order of the results, and so the indexes in the snapshots' changes.
+	Clauses []*Clause `protobuf:"bytes,5,rep,name=clauses,proto3" json:"clauses,omitempty"`
+	// The query that the clauses describe, as the client sends it in the
+	// AddTarget request that opens each stream.
+	Query                *firestorepb.Target_QueryTarget `protobuf:"bytes,6,opt,name=query,proto3" json:"query,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                        `json:"-"`
+	XXX_unrecognized     []byte                          `json:"-"`
+	XXX_sizecache        int32                           `json:"-"`
+}
+
+func (m *ListenTest) Reset()         { *m = ListenTest{} }
+func (m *ListenTest) String() string { return proto.CompactTextString(m) }
+func (*ListenTest) ProtoMessage()    {}
+func (*ListenTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{27}
+}
+
+func (m *ListenTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListenTest.Unmarshal(m, b)
+}
+func (m *ListenTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListenTest.Marshal(b, m, deterministic)
+}
+func (m *ListenTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListenTest.Merge(m, src)
+}
+func (m *ListenTest) XXX_Size() int {
+	return xxx_messageInfo_ListenTest.Size(m)
+}
+func (m *ListenTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListenTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListenTest proto.InternalMessageInfo
+
+func (m *ListenTest) GetResponses() []*firestorepb.ListenResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func (m *ListenTest) GetSnapshots() []*Snapshot {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+func (m *ListenTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+func (m *ListenTest) GetStreams() []*ListenStream {
+	if m != nil {
+		return m.Streams
+	}
+	return nil
+}
+
+func (m *ListenTest) GetClauses() []*Clause {
+	if m != nil {
+		return m.Clauses
+	}
+	return nil
+}
+
+func (m *ListenTest) GetQuery() *firestorepb.Target_QueryTarget {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+// A Listen stream in a ListenTest, and how it ends.
+//
+// After a stream ends, the client opens a new one with an AddTarget for the
+// same query. It discards the changes it received since its last consistent
+// point, a global NO_CHANGE response with a read time while the target is
+// CURRENT, and resumes from there: the AddTarget holds the resume token of
+// that response. RESET and a mismatched ExistenceFilter leave the client with
+// no resume token; a client that has none starts over, as after a RESET.
+//
+// An ExistenceFilter is mismatched if its count differs from the number of
+// results the client has: those in its last snapshot, plus those added and
+// less those removed since. The client then closes the stream itself, so the
+// filter is the stream's last response, and opens a new one.
+type ListenStream struct {
+	// The resume token in the AddTarget request that opens the stream, or empty
+	// if the request should have none.
+	ResumeToken []byte                        `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	Responses   []*firestorepb.ListenResponse `protobuf:"bytes,2,rep,name=responses,proto3" json:"responses,omitempty"`
+	// How the stream ends after the responses, unless the client closed it: with
+	// this gRPC status code, or, if it is zero, by the service closing the
+	// stream. The client reopens the stream after a close or one of the codes
+	// UNKNOWN, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED, INTERNAL, UNAVAILABLE and
+	// UNAUTHENTICATED, and signals an error after any other code. (Clients
+	// differ on some codes, like ABORTED and CANCELLED, which the tests do not
+	// use.)
+	//
+	// The last stream of a test ends only with a code the client does not
+	// retry; with a zero code, it stays open.
+	Code                 int32    `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListenStream) Reset()         { *m = ListenStream{} }
+func (m *ListenStream) String() string { return proto.CompactTextString(m) }
+func (*ListenStream) ProtoMessage()    {}
+func (*ListenStream) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{28}
+}
+
+func (m *ListenStream) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListenStream.Unmarshal(m, b)
+}
+func (m *ListenStream) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListenStream.Marshal(b, m, deterministic)
+}
+func (m *ListenStream) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListenStream.Merge(m, src)
+}
+func (m *ListenStream) XXX_Size() int {
+	return xxx_messageInfo_ListenStream.Size(m)
+}
+func (m *ListenStream) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListenStream.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListenStream proto.InternalMessageInfo
+
+func (m *ListenStream) GetResumeToken() []byte {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return nil
+}
+
+func (m *ListenStream) GetResponses() []*firestorepb.ListenResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func (m *ListenStream) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+// A WriteBatch: a sequence of write operations, on one or more documents,
+// committed together by WriteBatch.Commit.
+type BatchTest struct {
+	Ops []*WriteOp `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`
+	// The request that the commit should send. The writes of each operation
+	// appear in the order of the operations.
+	Request *firestorepb.CommitRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	// If true, one of the operations or the commit should signal an error,
+	// and no request should be sent.
+	IsError              bool     `protobuf:"varint,3,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchTest) Reset()         { *m = BatchTest{} }
+func (m *BatchTest) String() string { return proto.CompactTextString(m) }
+func (*BatchTest) ProtoMessage()    {}
+func (*BatchTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{29}
+}
+
+func (m *BatchTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchTest.Unmarshal(m, b)
+}
+func (m *BatchTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchTest.Marshal(b, m, deterministic)
+}
+func (m *BatchTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchTest.Merge(m, src)
+}
+func (m *BatchTest) XXX_Size() int {
+	return xxx_messageInfo_BatchTest.Size(m)
+}
+func (m *BatchTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchTest proto.InternalMessageInfo
+
+func (m *BatchTest) GetOps() []*WriteOp {
+	if m != nil {
+		return m.Ops
+	}
+	return nil
+}
+
+func (m *BatchTest) GetRequest() *firestorepb.CommitRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *BatchTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// A single write operation in a batch or transaction. It is described by the
+// test of the corresponding DocumentRef method, whose request and is_error
+// fields are not set.
+type WriteOp struct {
+	// Types that are valid to be assigned to Op:
+	//	*WriteOp_Create
+	//	*WriteOp_Set
+	//	*WriteOp_Update
+	//	*WriteOp_UpdatePaths
+	//	*WriteOp_Delete
+	Op                   isWriteOp_Op `protobuf_oneof:"op"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *WriteOp) Reset()         { *m = WriteOp{} }
+func (m *WriteOp) String() string { return proto.CompactTextString(m) }
+func (*WriteOp) ProtoMessage()    {}
+func (*WriteOp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{30}
+}
+
+func (m *WriteOp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteOp.Unmarshal(m, b)
+}
+func (m *WriteOp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteOp.Marshal(b, m, deterministic)
+}
+func (m *WriteOp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteOp.Merge(m, src)
+}
+func (m *WriteOp) XXX_Size() int {
+	return xxx_messageInfo_WriteOp.Size(m)
+}
+func (m *WriteOp) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteOp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteOp proto.InternalMessageInfo
+
+type isWriteOp_Op interface {
+	isWriteOp_Op()
+}
+
+type WriteOp_Create struct {
+	Create *CreateTest `protobuf:"bytes,1,opt,name=create,proto3,oneof"`
+}
+
+type WriteOp_Set struct {
+	Set *SetTest `protobuf:"bytes,2,opt,name=set,proto3,oneof"`
+}
+
+type WriteOp_Update struct {
+	Update *UpdateTest `protobuf:"bytes,3,opt,name=update,proto3,oneof"`
+}
+
+type WriteOp_UpdatePaths struct {
+	UpdatePaths *UpdatePathsTest `protobuf:"bytes,4,opt,name=update_paths,json=updatePaths,proto3,oneof"`
+}
+
+type WriteOp_Delete struct {
+	Delete *DeleteTest `protobuf:"bytes,5,opt,name=delete,proto3,oneof"`
+}
+
+func (*WriteOp_Create) isWriteOp_Op() {}
+
+func (*WriteOp_Set) isWriteOp_Op() {}
+
+func (*WriteOp_Update) isWriteOp_Op() {}
+
+func (*WriteOp_UpdatePaths) isWriteOp_Op() {}
+
+func (*WriteOp_Delete) isWriteOp_Op() {}
+
+func (m *WriteOp) GetOp() isWriteOp_Op {
+	if m != nil {
+		return m.Op
+	}
+	return nil
+}
+
+func (m *WriteOp) GetCreate() *CreateTest {
+	if x, ok := m.GetOp().(*WriteOp_Create); ok {
+		return x.Create
+	}
+	return nil
+}
+
+func (m *WriteOp) GetSet() *SetTest {
+	if x, ok := m.GetOp().(*WriteOp_Set); ok {
+		return x.Set
+	}
+	return nil
+}
+
+func (m *WriteOp) GetUpdate() *UpdateTest {
+	if x, ok := m.GetOp().(*WriteOp_Update); ok {
+		return x.Update
+	}
+	return nil
+}
+
+func (m *WriteOp) GetUpdatePaths() *UpdatePathsTest {
+	if x, ok := m.GetOp().(*WriteOp_UpdatePaths); ok {
+		return x.UpdatePaths
+	}
+	return nil
+}
+
+func (m *WriteOp) GetDelete() *DeleteTest {
+	if x, ok := m.GetOp().(*WriteOp_Delete); ok {
+		return x.Delete
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*WriteOp) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*WriteOp_Create)(nil),
+		(*WriteOp_Set)(nil),
+		(*WriteOp_Update)(nil),
+		(*WriteOp_UpdatePaths)(nil),
+		(*WriteOp_Delete)(nil),
+	}
+}
+
+// A call to RunTransaction. The transaction function performs the
+// operations in order, then returns. The service's reply to BeginTransaction
+// carries the given transaction ID, and all other calls succeed.
+//
+// The client should begin a transaction, read each document it is asked to
+// get within the transaction, and commit the writes with the transaction ID.
+// If the transaction function fails, the client should roll back the
+// transaction instead of committing it, and RunTransaction should signal an
+// error.
+type TransactionTest struct {
+	Ops []*TransactionOp `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`
+	// If true, the transaction function returns an error after performing the
+	// operations.
+	FunctionError bool `protobuf:"varint,2,opt,name=function_error,json=functionError,proto3" json:"function_error,omitempty"`
+	// The transaction ID returned by BeginTransaction.
+	Transaction []byte `protobuf:"bytes,3,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// The requests that the client should send, in order.
+	Requests []*TransactionRequest `protobuf:"bytes,4,rep,name=requests,proto3" json:"requests,omitempty"`
+	// If true, RunTransaction should signal an error.
+	IsError              bool     `protobuf:"varint,5,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionTest) Reset()         { *m = TransactionTest{} }
+func (m *TransactionTest) String() string { return proto.CompactTextString(m) }
+func (*TransactionTest) ProtoMessage()    {}
+func (*TransactionTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{31}
+}
+
+func (m *TransactionTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionTest.Unmarshal(m, b)
+}
+func (m *TransactionTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionTest.Marshal(b, m, deterministic)
+}
+func (m *TransactionTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionTest.Merge(m, src)
+}
+func (m *TransactionTest) XXX_Size() int {
+	return xxx_messageInfo_TransactionTest.Size(m)
+}
+func (m *TransactionTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionTest proto.InternalMessageInfo
+
+func (m *TransactionTest) GetOps() []*TransactionOp {
+	if m != nil {
+		return m.Ops
+	}
+	return nil
+}
+
+func (m *TransactionTest) GetFunctionError() bool {
+	if m != nil {
+		return m.FunctionError
+	}
+	return false
+}
+
+func (m *TransactionTest) GetTransaction() []byte {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *TransactionTest) GetRequests() []*TransactionRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+func (m *TransactionTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// An operation performed by a transaction function.
+type TransactionOp struct {
+	// Types that are valid to be assigned to Op:
+	//	*TransactionOp_Get
+	//	*TransactionOp_Write
+	Op                   isTransactionOp_Op `protobuf_oneof:"op"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TransactionOp) Reset()         { *m = TransactionOp{} }
+func (m *TransactionOp) String() string { return proto.CompactTextString(m) }
+func (*TransactionOp) ProtoMessage()    {}
+func (*TransactionOp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{32}
+}
+
+func (m *TransactionOp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionOp.Unmarshal(m, b)
+}
+func (m *TransactionOp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionOp.Marshal(b, m, deterministic)
+}
+func (m *TransactionOp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionOp.Merge(m, src)
+}
+func (m *TransactionOp) XXX_Size() int {
+	return xxx_messageInfo_TransactionOp.Size(m)
+}
+func (m *TransactionOp) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionOp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionOp proto.InternalMessageInfo
+
+type isTransactionOp_Op interface {
+	isTransactionOp_Op()
+}
+
+type TransactionOp_Get struct {
+	Get string `protobuf:"bytes,1,opt,name=get,proto3,oneof"`
+}
+
+type TransactionOp_Write struct {
+	Write *WriteOp `protobuf:"bytes,2,opt,name=write,proto3,oneof"`
+}
+
+func (*TransactionOp_Get) isTransactionOp_Op() {}
+
+func (*TransactionOp_Write) isTransactionOp_Op() {}
+
+func (m *TransactionOp) GetOp() isTransactionOp_Op {
+	if m != nil {
+		return m.Op
+	}
+	return nil
+}
+
+func (m *TransactionOp) GetGet() string {
+	if x, ok := m.GetOp().(*TransactionOp_Get); ok {
+		return x.Get
+	}
+	return ""
+}
+
+func (m *TransactionOp) GetWrite() *WriteOp {
+	if x, ok := m.GetOp().(*TransactionOp_Write); ok {
+		return x.Write
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*TransactionOp) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*TransactionOp_Get)(nil),
+		(*TransactionOp_Write)(nil),
+	}
+}
+
+// A request sent by the client during a transaction. Reading a single
+// document may be done with either GetDocument or BatchGetDocuments;
+// the tests use BatchGetDocuments.
+type TransactionRequest struct {
+	// Types that are valid to be assigned to Request:
+	//	*TransactionRequest_BeginTransaction
+	//	*TransactionRequest_BatchGetDocuments
+	//	*TransactionRequest_Commit
+	//	*TransactionRequest_Rollback
+	Request              isTransactionRequest_Request `protobuf_oneof:"request"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *TransactionRequest) Reset()         { *m = TransactionRequest{} }
+func (m *TransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*TransactionRequest) ProtoMessage()    {}
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{33}
+}
+
+func (m *TransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionRequest.Unmarshal(m, b)
+}
+func (m *TransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionRequest.Marshal(b, m, deterministic)
+}
+func (m *TransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionRequest.Merge(m, src)
+}
+func (m *TransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_TransactionRequest.Size(m)
+}
+func (m *TransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionRequest proto.InternalMessageInfo
+
+type isTransactionRequest_Request interface {
+	isTransactionRequest_Request()
+}
+
+type TransactionRequest_BeginTransaction struct {
+	BeginTransaction *firestorepb.BeginTransactionRequest `protobuf:"bytes,1,opt,name=begin_transaction,json=beginTransaction,proto3,oneof"`
+}
+
+type TransactionRequest_BatchGetDocuments struct {
+	BatchGetDocuments *firestorepb.BatchGetDocumentsRequest `protobuf:"bytes,2,opt,name=batch_get_documents,json=batchGetDocuments,proto3,oneof"`
+}
+
+type TransactionRequest_Commit struct {
+	Commit *firestorepb.CommitRequest `protobuf:"bytes,3,opt,name=commit,proto3,oneof"`
+}
+
+type TransactionRequest_Rollback struct {
+	Rollback *firestorepb.RollbackRequest `protobuf:"bytes,4,opt,name=rollback,proto3,oneof"`
+}
+
+func (*TransactionRequest_BeginTransaction) isTransactionRequest_Request() {}
+
+func (*TransactionRequest_BatchGetDocuments) isTransactionRequest_Request() {}
+
+func (*TransactionRequest_Commit) isTransactionRequest_Request() {}
+
+func (*TransactionRequest_Rollback) isTransactionRequest_Request() {}
+
+func (m *TransactionRequest) GetRequest() isTransactionRequest_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *TransactionRequest) GetBeginTransaction() *firestorepb.BeginTransactionRequest {
+	if x, ok := m.GetRequest().(*TransactionRequest_BeginTransaction); ok {
+		return x.BeginTransaction
+	}
+	return nil
+}
+
+func (m *TransactionRequest) GetBatchGetDocuments() *firestorepb.BatchGetDocumentsRequest {
+	if x, ok := m.GetRequest().(*TransactionRequest_BatchGetDocuments); ok {
+		return x.BatchGetDocuments
+	}
+	return nil
+}
+
+func (m *TransactionRequest) GetCommit() *firestorepb.CommitRequest {
+	if x, ok := m.GetRequest().(*TransactionRequest_Commit); ok {
+		return x.Commit
+	}
+	return nil
+}
+
+func (m *TransactionRequest) GetRollback() *firestorepb.RollbackRequest {
+	if x, ok := m.GetRequest().(*TransactionRequest_Rollback); ok {
+		return x.Rollback
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*TransactionRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*TransactionRequest_BeginTransaction)(nil),
+		(*TransactionRequest_BatchGetDocuments)(nil),
+		(*TransactionRequest_Commit)(nil),
+		(*TransactionRequest_Rollback)(nil),
+	}
+}
+
+type Snapshot struct {
+	Docs                 []*firestorepb.Document `protobuf:"bytes,1,rep,name=docs,proto3" json:"docs,omitempty"`
+	Changes              []*DocChange            `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
+	ReadTime             *timestamp.Timestamp    `protobuf:"bytes,3,opt,name=read_time,json=readTime,proto3" json:"read_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *Snapshot) Reset()         { *m = Snapshot{} }
+func (m *Snapshot) String() string { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()    {}
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{34}
+}
+
+func (m *Snapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Snapshot.Unmarshal(m, b)
+}
+func (m *Snapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Snapshot.Marshal(b, m, deterministic)
+}
+func (m *Snapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Snapshot.Merge(m, src)
+}
+func (m *Snapshot) XXX_Size() int {
+	return xxx_messageInfo_Snapshot.Size(m)
+}
+func (m *Snapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Snapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Snapshot proto.InternalMessageInfo
+
+func (m *Snapshot) GetDocs() []*firestorepb.Document {
+	if m != nil {
+		return m.Docs
+	}
+	return nil
+}
+
+func (m *Snapshot) GetChanges() []*DocChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *Snapshot) GetReadTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReadTime
+	}
+	return nil
+}
+
+type DocChange struct {
+	Kind                 DocChange_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=tests.v1.DocChange_Kind" json:"kind,omitempty"`
+	Doc                  *firestorepb.Document `protobuf:"bytes,2,opt,name=doc,proto3" json:"doc,omitempty"`
+	OldIndex             int32                 `protobuf:"varint,3,opt,name=old_index,json=oldIndex,proto3" json:"old_index,omitempty"`
+	NewIndex             int32                 `protobuf:"varint,4,opt,name=new_index,json=newIndex,proto3" json:"new_index,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DocChange) Reset()         { *m = DocChange{} }
+func (m *DocChange) String() string { return proto.CompactTextString(m) }
+func (*DocChange) ProtoMessage()    {}
+func (*DocChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{35}
+}
+
+func (m *DocChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DocChange.Unmarshal(m, b)
+}
+func (m *DocChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DocChange.Marshal(b, m, deterministic)
+}
+func (m *DocChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocChange.Merge(m, src)
+}
+func (m *DocChange) XXX_Size() int {
+	return xxx_messageInfo_DocChange.Size(m)
+}
+func (m *DocChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DocChange proto.InternalMessageInfo
+
+func (m *DocChange) GetKind() DocChange_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return DocChange_KIND_UNSPECIFIED
+}
+
+func (m *DocChange) GetDoc() *firestorepb.Document {
+	if m != nil {
+		return m.Doc
+	}
+	return nil
+}
+
+func (m *DocChange) GetOldIndex() int32 {
+	if m != nil {
+		return m.OldIndex
+	}
+	return 0
+}
+
+func (m *DocChange) GetNewIndex() int32 {
+	if m != nil {
+		return m.NewIndex
+	}
+	return 0
+}
+
+// A test of how a client decodes the fields of a document it reads, with Get,
+// a query or Listen, into the values a user sees. The client should decode
+// the document's data into its native form, then encode that as JSON, in the
+// encoding described in CreateTest.json_data. Integers should be written
+// without a fraction or exponent, and doubles with one, even if their value is
+// integral. The JSON is compared by value, so the order of keys and the form
+// of numbers do not otherwise matter.
+type DecodeTest struct {
+	Doc                  *firestorepb.Document `protobuf:"bytes,1,opt,name=doc,proto3" json:"doc,omitempty"`
+	JsonData             string                `protobuf:"bytes,2,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DecodeTest) Reset()         { *m = DecodeTest{} }
+func (m *DecodeTest) String() string { return proto.CompactTextString(m) }
+func (*DecodeTest) ProtoMessage()    {}
+func (*DecodeTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{36}
+}
+
+func (m *DecodeTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecodeTest.Unmarshal(m, b)
+}
+func (m *DecodeTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecodeTest.Marshal(b, m, deterministic)
+}
+func (m *DecodeTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecodeTest.Merge(m, src)
+}
+func (m *DecodeTest) XXX_Size() int {
+	return xxx_messageInfo_DecodeTest.Size(m)
+}
+func (m *DecodeTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecodeTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecodeTest proto.InternalMessageInfo
+
+func (m *DecodeTest) GetDoc() *firestorepb.Document {
+	if m != nil {
+		return m.Doc
+	}
+	return nil
+}
+
+func (m *DecodeTest) GetJsonData() string {
+	if m != nil {
+		return m.JsonData
+	}
+	return ""
+}
+
+// A test of how a client maps a native object, like a struct or class
+// instance, to and from the fields of a document. The object's type is
+// described by a schema, which the test interpreter translates into a type of
+// its language.
+//
+// An object is represented by a map from the name of each field in the schema
+// to its value. A field that is not set holds its type's zero value: false, 0,
+// the empty string, bytes, array or map, an object of zero values, or null for
+// a nullable field. The tests do not rely on the zero value of a timestamp,
+// geo point or reference.
+type ObjectTest struct {
+	Schema *ObjectSchema `protobuf:"bytes,1,opt,name=schema,proto3" json:"schema,omitempty"`
+	// The path of the document the object is written to or read from.
+	DocRefPath string `protobuf:"bytes,2,opt,name=doc_ref_path,json=docRefPath,proto3" json:"doc_ref_path,omitempty"`
+	// If false, the test encodes object, and the client should produce data and
+	// server_timestamps. If true, the test decodes data, and the client should
+	// produce object.
+	Decode bool                  `protobuf:"varint,3,opt,name=decode,proto3" json:"decode,omitempty"`
+	Object *firestorepb.MapValue `protobuf:"bytes,4,opt,name=object,proto3" json:"object,omitempty"`
+	Data   *firestorepb.MapValue `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	// The fields whose values the service should set to the time of the write,
+	// in the order of the schema's fields. They are not in data, nor is a map
+	// left empty by removing them, as with the ServerTimestamp sentinel.
+	ServerTimestamps []*FieldPath `protobuf:"bytes,6,rep,name=server_timestamps,json=serverTimestamps,proto3" json:"server_timestamps,omitempty"`
+	// If true, the call should signal an error.
+	IsError              bool     `protobuf:"varint,7,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ObjectTest) Reset()         { *m = ObjectTest{} }
+func (m *ObjectTest) String() string { return proto.CompactTextString(m) }
+func (*ObjectTest) ProtoMessage()    {}
+func (*ObjectTest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{37}
+}
+
+func (m *ObjectTest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ObjectTest.Unmarshal(m, b)
+}
+func (m *ObjectTest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ObjectTest.Marshal(b, m, deterministic)
+}
+func (m *ObjectTest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ObjectTest.Merge(m, src)
+}
+func (m *ObjectTest) XXX_Size() int {
+	return xxx_messageInfo_ObjectTest.Size(m)
+}
+func (m *ObjectTest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ObjectTest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ObjectTest proto.InternalMessageInfo
+
+func (m *ObjectTest) GetSchema() *ObjectSchema {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+func (m *ObjectTest) GetDocRefPath() string {
+	if m != nil {
+		return m.DocRefPath
+	}
+	return ""
+}
+
+func (m *ObjectTest) GetDecode() bool {
+	if m != nil {
+		return m.Decode
+	}
+	return false
+}
+
+func (m *ObjectTest) GetObject() *firestorepb.MapValue {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+func (m *ObjectTest) GetData() *firestorepb.MapValue {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ObjectTest) GetServerTimestamps() []*FieldPath {
+	if m != nil {
+		return m.ServerTimestamps
+	}
+	return nil
+}
+
+func (m *ObjectTest) GetIsError() bool {
+	if m != nil {
+		return m.IsError
+	}
+	return false
+}
+
+// The type of a native object: an ordered list of fields.
+type ObjectSchema struct {
+	Fields               []*ObjectField `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ObjectSchema) Reset()         { *m = ObjectSchema{} }
+func (m *ObjectSchema) String() string { return proto.CompactTextString(m) }
+func (*ObjectSchema) ProtoMessage()    {}
+func (*ObjectSchema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{38}
+}
+
+func (m *ObjectSchema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ObjectSchema.Unmarshal(m, b)
+}
+func (m *ObjectSchema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ObjectSchema.Marshal(b, m, deterministic)
+}
+func (m *ObjectSchema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ObjectSchema.Merge(m, src)
+}
+func (m *ObjectSchema) XXX_Size() int {
+	return xxx_messageInfo_ObjectSchema.Size(m)
+}
+func (m *ObjectSchema) XXX_DiscardUnknown() {
+	xxx_messageInfo_ObjectSchema.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ObjectSchema proto.InternalMessageInfo
+
+func (m *ObjectSchema) GetFields() []*ObjectField {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// A field of a native object, with the annotations that control its encoding.
+type ObjectField struct {
+	Name string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type *ObjectType `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// The name of the document field the field is stored in, if it differs
+	// from name.
+	FirestoreName string `protobuf:"bytes,3,opt,name=firestore_name,json=firestoreName,proto3" json:"firestore_name,omitempty"`
+	// If true, the field is omitted when encoding if it holds its zero value.
+	OmitEmpty bool `protobuf:"varint,4,opt,name=omit_empty,json=omitEmpty,proto3" json:"omit_empty,omitempty"`
+	// If true, the field holds the document's ID: it is never stored in the
+	// document, and decoding sets it from the document's path. Its type must be
+	// "string".
+	DocumentId bool `protobuf:"varint,5,opt,name=document_id,json=documentId,proto3" json:"document_id,omitempty"`
+	// If true, the field's type must be a nullable timestamp. When encoding, a
+	// null value is replaced by the time of the write, and a non-null value is
+	// stored as usual.
+	ServerTimestamp      bool     `protobuf:"varint,6,opt,name=server_timestamp,json=serverTimestamp,proto3" json:"server_timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ObjectField) Reset()         { *m = ObjectField{} }
+func (m *ObjectField) String() string { return proto.CompactTextString(m) }
+func (*ObjectField) ProtoMessage()    {}
+func (*ObjectField) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{39}
+}
+
+func (m *ObjectField) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ObjectField.Unmarshal(m, b)
+}
+func (m *ObjectField) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ObjectField.Marshal(b, m, deterministic)
+}
+func (m *ObjectField) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ObjectField.Merge(m, src)
+}
+func (m *ObjectField) XXX_Size() int {
+	return xxx_messageInfo_ObjectField.Size(m)
+}
+func (m *ObjectField) XXX_DiscardUnknown() {
+	xxx_messageInfo_ObjectField.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ObjectField proto.InternalMessageInfo
+
+func (m *ObjectField) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ObjectField) GetType() *ObjectType {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *ObjectField) GetFirestoreName() string {
+	if m != nil {
+		return m.FirestoreName
+	}
+	return ""
+}
+
+func (m *ObjectField) GetOmitEmpty() bool {
+	if m != nil {
+		return m.OmitEmpty
+	}
+	return false
+}
+
+func (m *ObjectField) GetDocumentId() bool {
+	if m != nil {
+		return m.DocumentId
+	}
+	return false
+}
+
+func (m *ObjectField) GetServerTimestamp() bool {
+	if m != nil {
+		return m.ServerTimestamp
+	}
+	return false
+}
+
+// The type of a field of a native object.
+type ObjectType struct {
+	// Types that are valid to be assigned to Type:
+	//	*ObjectType_Scalar
+	//	*ObjectType_Nullable
+	//	*ObjectType_Array
+	//	*ObjectType_Map
+	//	*ObjectType_Object
+	Type                 isObjectType_Type `protobuf_oneof:"type"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ObjectType) Reset()         { *m = ObjectType{} }
+func (m *ObjectType) String() string { return proto.CompactTextString(m) }
+func (*ObjectType) ProtoMessage()    {}
+func (*ObjectType) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9164e87288ef5e21, []int{40}
+}
+
+func (m *ObjectType) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ObjectType.Unmarshal(m, b)
+}
+func (m *ObjectType) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ObjectType.Marshal(b, m, deterministic)
+}
+func (m *ObjectType) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ObjectType.Merge(m, src)
+}
+func (m *ObjectType) XXX_Size() int {
+	return xxx_messageInfo_ObjectType.Size(m)
+}
+func (m *ObjectType) XXX_DiscardUnknown() {
+	xxx_messageInfo_ObjectType.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ObjectType proto.InternalMessageInfo
+
+type isObjectType_Type interface {
+	isObjectType_Type()
+}
+
+type ObjectType_Scalar struct {
+	Scalar string `protobuf:"bytes,1,opt,name=scalar,proto3,oneof"`
+}
+
+type ObjectType_Nullable struct {
+	Nullable *ObjectType `protobuf:"bytes,2,opt,name=nullable,proto3,oneof"`
+}
+
+type ObjectType_Array struct {
+	Array *ObjectType `protobuf:"bytes,3,opt,name=array,proto3,oneof"`
+}
+
+type ObjectType_Map struct {
+	Map *ObjectType `protobuf:"bytes,4,opt,name=map,proto3,oneof"`
+}
+
+type ObjectType_Object struct {
+	Object *ObjectSchema `protobuf:"bytes,5,opt,name=object,proto3,oneof"`
+}
+
+func (*ObjectType_Scalar) isObjectType_Type() {}
+
+func (*ObjectType_Nullable) isObjectType_Type() {}
+
+func (*ObjectType_Array) isObjectType_Type() {}
+
+func (*ObjectType_Map) isObjectType_Type() {}
+
+func (*ObjectType_Object) isObjectType_Type() {}
+
+func (m *ObjectType) GetType() isObjectType_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *ObjectType) GetScalar() string {
+	if x, ok := m.GetType().(*ObjectType_Scalar); ok {
+		return x.Scalar
+	}
+	return ""
+}
+
+func (m *ObjectType) GetNullable() *ObjectType {
+	if x, ok := m.GetType().(*ObjectType_Nullable); ok {
+		return x.Nullable
+	}
+	return nil
+}
+
+func (m *ObjectType) GetArray() *ObjectType {
+	if x, ok := m.GetType().(*ObjectType_Array); ok {
+		return x.Array
+	}
+	return nil
+}
+
+func (m *ObjectType) GetMap() *ObjectType {
+	if x, ok := m.GetType().(*ObjectType_Map); ok {
+		return x.Map
+	}
+	return nil
+}
+
+func (m *ObjectType) GetObject() *ObjectSchema {
+	if x, ok := m.GetType().(*ObjectType_Object); ok {
+		return x.Object
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*ObjectType) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*ObjectType_Scalar)(nil),
+		(*ObjectType_Nullable)(nil),
+		(*ObjectType_Array)(nil),
+		(*ObjectType_Map)(nil),
+		(*ObjectType_Object)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterEnum("tests.v1.DocChange_Kind", DocChange_Kind_name, DocChange_Kind_value)
+	proto.RegisterType((*TestSuite)(nil), "tests.v1.TestSuite")
+	proto.RegisterType((*Test)(nil), "tests.v1.Test")
+	proto.RegisterType((*GetTest)(nil), "tests.v1.GetTest")
+	proto.RegisterType((*GetAllTest)(nil), "tests.v1.GetAllTest")
+	proto.RegisterType((*CreateTest)(nil), "tests.v1.CreateTest")
+	proto.RegisterType((*SetTest)(nil), "tests.v1.SetTest")
+	proto.RegisterType((*UpdateTest)(nil), "tests.v1.UpdateTest")
+	proto.RegisterType((*UpdatePathsTest)(nil), "tests.v1.UpdatePathsTest")
+	proto.RegisterType((*DeleteTest)(nil), "tests.v1.DeleteTest")
+	proto.RegisterType((*SetOption)(nil), "tests.v1.SetOption")
+	proto.RegisterType((*QueryTest)(nil), "tests.v1.QueryTest")
+	proto.RegisterType((*Clause)(nil), "tests.v1.Clause")
+	proto.RegisterType((*Select)(nil), "tests.v1.Select")
+	proto.RegisterType((*Where)(nil), "tests.v1.Where")
+	proto.RegisterType((*Filter)(nil), "tests.v1.Filter")
+	proto.RegisterType((*CompositeFilter)(nil), "tests.v1.CompositeFilter")
+	proto.RegisterType((*OrderBy)(nil), "tests.v1.OrderBy")
+	proto.RegisterType((*Cursor)(nil), "tests.v1.Cursor")
+	proto.RegisterType((*DocSnapshot)(nil), "tests.v1.DocSnapshot")
+	proto.RegisterType((*FieldPath)(nil), "tests.v1.FieldPath")
+	proto.RegisterType((*PathTest)(nil), "tests.v1.PathTest")
+	proto.RegisterType((*FieldPathTest)(nil), "tests.v1.FieldPathTest")
+	proto.RegisterType((*AggregationQueryTest)(nil), "tests.v1.AggregationQueryTest")
+	proto.RegisterMapType((map[string]*firestorepb.Value)(nil), "tests.v1.AggregationQueryTest.ResultEntry")
+	proto.RegisterType((*Aggregation)(nil), "tests.v1.Aggregation")
+	proto.RegisterType((*Count)(nil), "tests.v1.Count")
+	proto.RegisterType((*QueryResultsTest)(nil), "tests.v1.QueryResultsTest")
+	proto.RegisterType((*DocumentSnapshot)(nil), "tests.v1.DocumentSnapshot")
+	proto.RegisterType((*ListenTest)(nil), "tests.v1.ListenTest")
+	proto.RegisterType((*ListenStream)(nil), "tests.v1.ListenStream")
+	proto.RegisterType((*BatchTest)(nil), "tests.v1.BatchTest")
+	proto.RegisterType((*WriteOp)(nil), "tests.v1.WriteOp")
+	proto.RegisterType((*TransactionTest)(nil), "tests.v1.TransactionTest")
+	proto.RegisterType((*TransactionOp)(nil), "tests.v1.TransactionOp")
+	proto.RegisterType((*TransactionRequest)(nil), "tests.v1.TransactionRequest")
+	proto.RegisterType((*Snapshot)(nil), "tests.v1.Snapshot")
+	proto.RegisterType((*DocChange)(nil), "tests.v1.DocChange")
+	proto.RegisterType((*DecodeTest)(nil), "tests.v1.DecodeTest")
+	proto.RegisterType((*ObjectTest)(nil), "tests.v1.ObjectTest")
+	proto.RegisterType((*ObjectSchema)(nil), "tests.v1.ObjectSchema")
+	proto.RegisterType((*ObjectField)(nil), "tests.v1.ObjectField")
+	proto.RegisterType((*ObjectType)(nil), "tests.v1.ObjectType")
+}
+
+func init() { proto.RegisterFile("v1/test.proto", fileDescriptor_9164e87288ef5e21) }
+
+var fileDescriptor_9164e87288ef5e21 = []byte{
+	// 2635 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x5a, 0x4b, 0x6f, 0x1c, 0xc7,
+	0xf1, 0xd7, 0xcc, 0xbe, 0x6b, 0x97, 0xe2, 0xb2, 0x2d, 0xcb, 0x63, 0x5a, 0xb2, 0xa9, 0x91, 0x04,
+	0x51, 0xaf, 0xa5, 0x48, 0xff, 0x8d, 0xbf, 0x6c, 0x58, 0x46, 0xb8, 0x24, 0xf5, 0x88, 0x45, 0x51,
+	0x19, 0x52, 0x32, 0xe0, 0x08, 0x18, 0xcc, 0xce, 0xf4, 0x2e, 0xc7, 0x9c, 0x9d, 0x5e, 0xcd, 0xf4,
+	0x52, 0xe1, 0x17, 0x08, 0x1c, 0x20, 0xe7, 0x1c, 0x73, 0x4a, 0x2e, 0x06, 0xf2, 0x01, 0x72, 0xc8,
+	0x17, 0xc8, 0x21, 0xc7, 0x1c, 0x02, 0x18, 0x39, 0x26, 0x40, 0x72, 0x0a, 0x90, 0x7b, 0xd0, 0xaf,
+	0x99, 0xd9, 0xd9, 0xe1, 0x92, 0x96, 0x1c, 0xe7, 0xb6, 0x5d, 0xfd, 0xab, 0xea, 0xae, 0xea, 0xea,
+	0xaa, 0xea, 0x9a, 0x85, 0xb9, 0xc3, 0xd5, 0x15, 0x8a, 0x63, 0xda, 0x19, 0x45, 0x84, 0x12, 0x54,
+	0x67, 0xbf, 0xe3, 0xce, 0xe1, 0xea, 0xe2, 0xd2, 0x80, 0x90, 0x41, 0x80, 0x57, 0xfa, 0x7e, 0x84,
+	0x63, 0x4a, 0x22, 0xbc, 0x72, 0xb8, 0xba, 0xe2, 0x92, 0xe1, 0x90, 0x84, 0x02, 0xbb, 0x68, 0x16,
+	0x21, 0x3c, 0xe2, 0x8e, 0x87, 0x38, 0x94, 0xf2, 0x16, 0x2f, 0x17, 0x61, 0x92, 0x81, 0x04, 0x7d,
+	0x50, 0x04, 0x7a, 0x39, 0xc6, 0xd1, 0x51, 0x0e, 0xc0, 0x47, 0xbd, 0x71, 0x7f, 0x85, 0xfa, 0x43,
+	0x1c, 0x53, 0x67, 0x38, 0x12, 0x00, 0x73, 0x15, 0x1a, 0x7b, 0x38, 0xa6, 0xbb, 0x63, 0x9f, 0x62,
+	0x74, 0x05, 0x2a, 0x5c, 0x0b, 0x43, 0x5b, 0x2a, 0x2d, 0x37, 0xd7, 0xce, 0x76, 0x94, 0x4e, 0x1d,
+	0x86, 0xb1, 0xc4, 0xa4, 0xf9, 0xeb, 0x1a, 0x94, 0xd9, 0x18, 0x2d, 0x41, 0xd3, 0xc3, 0xb1, 0x1b,
+	0xf9, 0x23, 0xea, 0x93, 0xd0, 0xd0, 0x96, 0xb4, 0xe5, 0x86, 0x95, 0x25, 0xa1, 0xab, 0x50, 0x1a,
+	0x60, 0x6a, 0xe8, 0x4b, 0xda, 0x72, 0x73, 0x6d, 0x21, 0x15, 0xf7, 0x00, 0x53, 0x26, 0xe1, 0xe1,
+	0x19, 0x8b, 0xcd, 0xa3, 0x0e, 0x54, 0xdd, 0x08, 0x3b, 0x14, 0x1b, 0x25, 0x8e, 0x3c, 0x97, 0x22,
+	0x37, 0x38, 0x5d, 0x82, 0x25, 0x8a, 0x89, 0x8d, 0x31, 0x35, 0xca, 0x79, 0xb1, 0xbb, 0xa9, 0xd8,
+	0x58, 0x88, 0x1d, 0x8f, 0x3c, 0x26, 0xb6, 0x92, 0x17, 0xfb, 0x8c, 0xd3, 0x95, 0x58, 0x81, 0x42,
+	0x9f, 0x41, 0x4b, 0xfc, 0xb2, 0x47, 0x0e, 0xdd, 0x8f, 0x8d, 0x2a, 0xe7, 0x7a, 0x37, 0xcf, 0xf5,
+	0x94, 0x4d, 0x4a, 0xd6, 0xe6, 0x38, 0x25, 0xb1, 0xf5, 0x3c, 0x1c, 0x60, 0x8a, 0x8d, 0x5a, 0x7e,
+	0xbd, 0x4d, 0x4e, 0x57, 0xeb, 0x09, 0x14, 0xba, 0x09, 0x15, 0x7e, 0x56, 0x46, 0x9d, 0xc3, 0xdf,
+	0x4a, 0xe1, 0x3f, 0x61, 0x64, 0x89, 0x16, 0x18, 0x26, 0x3c, 0xf0, 0x63, 0x8a, 0x43, 0xa3, 0x91,
+	0x17, 0xfe, 0x98, 0xd3, 0x95, 0x70, 0x81, 0x62, 0xc2, 0x7b, 0x0e, 0x75, 0xf7, 0x0d, 0xc8, 0x0b,
+	0xef, 0x32, 0xb2, 0x12, 0xce, 0x31, 0xe8, 0x1e, 0x34, 0x69, 0xe4, 0x84, 0xb1, 0xe3, 0xf2, 0x93,
+	0x6c, 0xe6, 0x15, 0xdf, 0x4b, 0x27, 0x95, 0xe2, 0x19, 0x3c, 0x5a, 0x87, 0x39, 0xbe, 0x49, 0x3b,
+	0xc2, 0xf1, 0x38, 0xa0, 0xb1, 0xd1, 0xe2, 0x02, 0x16, 0x73, 0x0a, 0x59, 0x62, 0x56, 0x4a, 0x68,
+	0xbd, 0xcc, 0xd0, 0xd0, 0x0a, 0xd4, 0x06, 0x98, 0xda, 0x4e, 0x10, 0x18, 0x73, 0x79, 0xfd, 0x1e,
+	0x60, 0xba, 0x1e, 0x04, 0x4a, 0xbf, 0x01, 0x1f, 0xa1, 0x6d, 0x58, 0x70, 0x06, 0x83, 0x08, 0x0f,
+	0x1c, 0xb6, 0x05, 0x5b, 0x18, 0xf2, 0x2c, 0x67, 0x7d, 0x3f, 0x65, 0x5d, 0x4f, 0x21, 0x59, 0x9b,
+	0xb6, 0x9d, 0x1c, 0x9d, 0x99, 0x97, 0xf4, 0xbe, 0xc2, 0x2e, 0x35, 0xe6, 0xf3, 0xcb, 0xef, 0x70,
+	0xba, 0x5a, 0x5e, 0xa0, 0xc4, 0x59, 0xbb, 0xc4, 0xc3, 0x46, 0x7b, 0xfa, 0xac, 0x19, 0x3d, 0x3d,
+	0x6b, 0x36, 0x42, 0x77, 0x01, 0xfa, 0x3e, 0x0e, 0x3c, 0xee, 0x5a, 0xc6, 0x02, 0xe7, 0x79, 0x27,
+	0xe5, 0xb9, 0xcf, 0xe6, 0x98, 0x17, 0x49, 0xb6, 0x46, 0x5f, 0x11, 0xd0, 0x32, 0x94, 0x39, 0x0f,
+	0xe2, 0x3c, 0x28, 0xe5, 0xc9, 0xc0, 0x39, 0xa2, 0x5b, 0x85, 0x32, 0x9b, 0x34, 0x43, 0xa8, 0xc9,
+	0x0b, 0x86, 0x96, 0xa0, 0xe5, 0x11, 0xd7, 0x8e, 0x70, 0x5f, 0x2c, 0x2c, 0xee, 0x28, 0x78, 0xc4,
+	0xb5, 0x70, 0x9f, 0x8b, 0x5f, 0x87, 0x5a, 0x84, 0x5f, 0x8e, 0x71, 0xac, 0xae, 0xe9, 0xb5, 0x8e,
+	0x88, 0x19, 0x9d, 0x34, 0xd8, 0x88, 0x33, 0xd8, 0x94, 0x01, 0xca, 0x12, 0x70, 0x4b, 0xf1, 0x99,
+	0xff, 0xd2, 0x01, 0xd2, 0x33, 0x42, 0x26, 0xcc, 0x65, 0xd7, 0x14, 0xd1, 0x84, 0x05, 0x86, 0x64,
+	0xd1, 0x18, 0xad, 0x29, 0x73, 0x0c, 0x9d, 0xf8, 0xc0, 0xd0, 0x97, 0x4a, 0x93, 0x2e, 0x9a, 0x98,
+	0x43, 0x1a, 0x62, 0xdb, 0x89, 0x0f, 0x58, 0xb8, 0xc9, 0x3a, 0x29, 0x0b, 0x15, 0xad, 0x49, 0x3f,
+	0x7c, 0x90, 0xea, 0x22, 0x62, 0xc3, 0xed, 0x42, 0x5d, 0xf8, 0x05, 0xc8, 0x28, 0x14, 0xe7, 0x35,
+	0x42, 0x8f, 0xa1, 0x11, 0xe1, 0x78, 0x44, 0xc2, 0x18, 0xc7, 0x46, 0x85, 0xef, 0xae, 0x73, 0x5a,
+	0x51, 0x82, 0xcd, 0x4a, 0x05, 0xa0, 0xbb, 0xd0, 0x88, 0x43, 0x67, 0x14, 0xef, 0x13, 0xca, 0x82,
+	0x4a, 0x69, 0xf2, 0x6a, 0x28, 0xd6, 0x5d, 0x09, 0xb1, 0x52, 0x30, 0x7a, 0x17, 0xea, 0x7e, 0x6c,
+	0xe3, 0x28, 0x22, 0x11, 0x8f, 0x29, 0x75, 0xab, 0xe6, 0xc7, 0x5b, 0x6c, 0x68, 0xfe, 0x46, 0x03,
+	0x48, 0x83, 0xe3, 0x29, 0x0e, 0xfa, 0x3d, 0x68, 0x7c, 0x15, 0x93, 0xd0, 0xf6, 0x1c, 0xea, 0xf0,
+	0xa3, 0x6e, 0x58, 0x75, 0x46, 0xd8, 0x74, 0xa8, 0x83, 0x3e, 0x4d, 0x2d, 0x27, 0x42, 0xb0, 0x59,
+	0xa8, 0xee, 0x06, 0x19, 0x0e, 0xfd, 0x29, 0x07, 0x98, 0xd8, 0x66, 0x79, 0x72, 0x9b, 0x7f, 0xd2,
+	0xa0, 0xb6, 0x7b, 0x6a, 0x67, 0xbc, 0x09, 0x55, 0x22, 0x92, 0x89, 0x9e, 0x8f, 0x5a, 0xbb, 0x98,
+	0xee, 0xf0, 0x29, 0x4b, 0x42, 0x26, 0x15, 0x2a, 0x1d, 0xaf, 0x50, 0xf9, 0xcd, 0x14, 0xaa, 0x4c,
+	0x2a, 0xf4, 0x0f, 0x0d, 0x20, 0xcd, 0x1e, 0xa7, 0xd0, 0x69, 0x0b, 0x5a, 0xa3, 0x08, 0xbb, 0x24,
+	0xf4, 0xfc, 0x8c, 0x66, 0x97, 0x0a, 0xb7, 0xf3, 0x34, 0x03, 0xb4, 0x26, 0xd8, 0xfe, 0x47, 0xda,
+	0x7e, 0xa3, 0xc3, 0x7c, 0x2e, 0xeb, 0xfd, 0x70, 0x2a, 0xff, 0x1f, 0x34, 0xd3, 0x98, 0x19, 0x1b,
+	0xa5, 0xe3, 0xa3, 0x04, 0x24, 0xe1, 0x32, 0x46, 0x1f, 0x40, 0x93, 0x1b, 0xea, 0xd0, 0x09, 0xc6,
+	0x38, 0x36, 0xca, 0x3c, 0xf8, 0x00, 0x23, 0x3d, 0xe7, 0x94, 0xac, 0xb1, 0x2a, 0x6f, 0x66, 0xac,
+	0xea, 0x94, 0xaf, 0x43, 0x9a, 0xe8, 0x7f, 0x38, 0x3b, 0xfd, 0xd7, 0x2e, 0xef, 0x8f, 0xa1, 0x91,
+	0x5c, 0x3b, 0xd4, 0x86, 0x12, 0xcb, 0xce, 0x1a, 0x87, 0xb0, 0x9f, 0xec, 0xb6, 0x72, 0xbb, 0xc7,
+	0xb3, 0x02, 0xb8, 0x84, 0x98, 0x7f, 0xd6, 0xa0, 0x91, 0xa4, 0x60, 0xe6, 0xcd, 0x2e, 0x09, 0x82,
+	0xac, 0x61, 0xea, 0x8c, 0xc0, 0xcd, 0x72, 0x03, 0x6a, 0x6e, 0xe0, 0x8c, 0x63, 0xac, 0x04, 0xb7,
+	0x33, 0xf5, 0x20, 0x9f, 0xb0, 0x14, 0x00, 0x7d, 0xa2, 0x6a, 0x28, 0xa1, 0xf9, 0x95, 0x42, 0xcd,
+	0x77, 0x69, 0x34, 0x76, 0xe9, 0x38, 0xc2, 0x9e, 0xa8, 0x43, 0x04, 0xcb, 0x0c, 0xcd, 0xd1, 0x75,
+	0x68, 0xb3, 0xed, 0x60, 0x9e, 0x57, 0xec, 0x41, 0x44, 0xc6, 0x23, 0x79, 0x35, 0xe6, 0x53, 0xfa,
+	0x03, 0x46, 0x36, 0xbf, 0x2d, 0x41, 0x55, 0xec, 0x0a, 0xdd, 0x80, 0x6a, 0x8c, 0xd9, 0x24, 0x57,
+	0x69, 0x62, 0xdf, 0xbb, 0x9c, 0xce, 0x0a, 0x02, 0x81, 0x40, 0xd7, 0xa0, 0xf2, 0x6a, 0x1f, 0x47,
+	0x58, 0x1e, 0xfa, 0x7c, 0x0a, 0xfd, 0x82, 0x91, 0x59, 0x6d, 0xc6, 0xe7, 0x51, 0x07, 0xea, 0x24,
+	0xf2, 0x70, 0x64, 0xf7, 0x94, 0x92, 0x99, 0x8a, 0x77, 0x87, 0xcd, 0x74, 0x8f, 0x1e, 0x9e, 0xb1,
+	0x6a, 0x44, 0xfc, 0x44, 0x06, 0x54, 0x49, 0xbf, 0xaf, 0xea, 0xe3, 0x0a, 0x5b, 0x52, 0x8c, 0xd1,
+	0x79, 0xa8, 0x04, 0xfe, 0xd0, 0x17, 0x6e, 0xcf, 0x26, 0xc4, 0x10, 0xdd, 0x86, 0x7a, 0x4c, 0x9d,
+	0x88, 0xda, 0x0e, 0x35, 0xaa, 0xf9, 0x8d, 0x6f, 0x8c, 0xa3, 0x98, 0x44, 0x6c, 0x01, 0x8e, 0x59,
+	0xa7, 0xe8, 0x43, 0x68, 0x4a, 0x78, 0x9f, 0xe2, 0xc8, 0xa8, 0x1d, 0xcb, 0x01, 0x82, 0x83, 0xa1,
+	0xd0, 0x75, 0xa8, 0xe2, 0xd0, 0x63, 0x2b, 0xd4, 0x8f, 0xc5, 0x57, 0x70, 0xe8, 0xad, 0x53, 0xb4,
+	0x0a, 0xc0, 0xa0, 0x3d, 0xdc, 0x27, 0x11, 0x36, 0x1a, 0xc7, 0xc2, 0x1b, 0x38, 0xf4, 0xba, 0x1c,
+	0xc4, 0x0c, 0xdf, 0xf7, 0x03, 0xb6, 0x1b, 0xc8, 0xc3, 0xef, 0x73, 0x3a, 0xb3, 0x82, 0x40, 0xa0,
+	0x2b, 0x30, 0xc7, 0xd5, 0xb6, 0x29, 0xb1, 0x03, 0x27, 0xa6, 0x46, 0x53, 0x5a, 0xa3, 0xc9, 0xc9,
+	0x7b, 0xe4, 0xb1, 0x13, 0xd3, 0x6e, 0x1d, 0xaa, 0xc2, 0xc5, 0xcc, 0x8f, 0xa0, 0x2a, 0x0e, 0x2f,
+	0xe3, 0xef, 0xda, 0xc9, 0xfe, 0x6e, 0x43, 0x85, 0x1f, 0x24, 0xba, 0x26, 0xeb, 0x37, 0x6d, 0x49,
+	0x3b, 0x8e, 0x87, 0x03, 0xd0, 0x59, 0xd0, 0xc9, 0x48, 0x66, 0x66, 0x9d, 0x8c, 0xd0, 0x45, 0x80,
+	0x34, 0x90, 0xc9, 0x90, 0xdf, 0x48, 0xe2, 0x98, 0x79, 0x08, 0x55, 0xa1, 0x5b, 0xea, 0x4a, 0xda,
+	0x09, 0xae, 0xf4, 0x31, 0xbb, 0x75, 0xc3, 0x11, 0x89, 0x7d, 0xaa, 0xfc, 0x2e, 0x53, 0xe4, 0x6f,
+	0xa8, 0xa9, 0xc4, 0x64, 0x29, 0x9a, 0xd9, 0x43, 0xd8, 0xcf, 0xdc, 0x86, 0xf9, 0x1c, 0x52, 0xee,
+	0x5c, 0x4b, 0x76, 0x7e, 0x03, 0x6a, 0x02, 0x5c, 0x70, 0x81, 0x05, 0x8b, 0xa5, 0x00, 0xe6, 0x53,
+	0xa8, 0x49, 0x27, 0x3e, 0xbd, 0xa5, 0x2e, 0x40, 0xc3, 0xf3, 0x23, 0x71, 0x09, 0xa5, 0xc1, 0x52,
+	0x82, 0xe9, 0x42, 0x55, 0xf8, 0x08, 0xba, 0x2b, 0x22, 0xb0, 0xaa, 0xa7, 0xa4, 0xe0, 0xb7, 0x27,
+	0x6a, 0xaf, 0xa4, 0xec, 0x6a, 0x7a, 0xe9, 0x20, 0x9f, 0x44, 0xf4, 0x7c, 0x12, 0x31, 0x3f, 0x83,
+	0x66, 0x86, 0x19, 0xa1, 0xcc, 0xd6, 0x1b, 0x72, 0x97, 0xb3, 0x0a, 0x2e, 0xf3, 0x12, 0x34, 0x12,
+	0xad, 0xd0, 0x39, 0xa8, 0x70, 0xaf, 0x91, 0x95, 0xb2, 0x18, 0x98, 0xbf, 0xd0, 0xa0, 0xae, 0x6a,
+	0x7c, 0xb4, 0x08, 0x75, 0x26, 0xa7, 0xe7, 0xc4, 0x58, 0xc5, 0x4b, 0x35, 0x4e, 0x16, 0xd7, 0x33,
+	0x8b, 0xbf, 0x0f, 0x90, 0x06, 0x2a, 0xee, 0x3c, 0x75, 0x2b, 0x43, 0x61, 0x3c, 0xa1, 0x33, 0xc4,
+	0x3c, 0x46, 0x34, 0x2c, 0xfe, 0x7b, 0x56, 0x1d, 0xf0, 0x4b, 0x0d, 0xe6, 0x26, 0xde, 0x28, 0x85,
+	0x1a, 0xaf, 0x4d, 0x3c, 0x72, 0xf4, 0x25, 0x6d, 0x76, 0x55, 0xcf, 0x75, 0x37, 0xa0, 0x86, 0x43,
+	0xf6, 0x44, 0xf2, 0xa4, 0x8b, 0xab, 0xe1, 0xac, 0xc4, 0xf4, 0xb7, 0x12, 0x9c, 0x2b, 0x7a, 0xda,
+	0x7d, 0x7f, 0x79, 0xe5, 0x63, 0x68, 0x65, 0xde, 0x88, 0xaa, 0xf8, 0x78, 0xbb, 0xf0, 0x65, 0x69,
+	0x4d, 0x40, 0xd1, 0x96, 0x4a, 0x49, 0xa2, 0x14, 0x5b, 0x39, 0x21, 0x25, 0xe5, 0xf5, 0x50, 0xd9,
+	0xe9, 0xc9, 0xf4, 0x1b, 0xe4, 0x4e, 0xa1, 0x28, 0x6b, 0x1c, 0x4e, 0xc9, 0x28, 0x78, 0x85, 0x74,
+	0xa1, 0x2a, 0x9e, 0xe7, 0xf2, 0x09, 0x72, 0x63, 0xf6, 0x2b, 0xb9, 0x23, 0x5e, 0xe6, 0x5b, 0x21,
+	0x8d, 0x8e, 0x2c, 0xc9, 0x39, 0xe3, 0x3d, 0xb2, 0xf8, 0x0c, 0x9a, 0x19, 0x0e, 0x56, 0x2d, 0x1c,
+	0xe0, 0x23, 0x79, 0x04, 0xec, 0x27, 0xba, 0x03, 0x15, 0x11, 0xc9, 0x74, 0xd9, 0x1c, 0x28, 0xd2,
+	0x85, 0xdf, 0x2e, 0x4b, 0x00, 0x3f, 0xd1, 0xef, 0x6a, 0xe6, 0xef, 0x34, 0x68, 0x66, 0xb6, 0xc7,
+	0xae, 0x8a, 0x13, 0xf8, 0x4e, 0x2c, 0x25, 0x8b, 0x01, 0x8b, 0x80, 0x2e, 0x19, 0x87, 0x74, 0x3a,
+	0x99, 0x6e, 0x30, 0x32, 0x8b, 0x80, 0x7c, 0x1e, 0x5d, 0x83, 0x52, 0x3c, 0x1e, 0x1a, 0xa5, 0x63,
+	0x5d, 0x93, 0xf7, 0x8e, 0xc6, 0x43, 0x06, 0x74, 0x0e, 0x07, 0x46, 0x79, 0x26, 0xd0, 0x39, 0x1c,
+	0x74, 0xe7, 0xa0, 0x99, 0x39, 0x7d, 0xf3, 0x02, 0x54, 0xf8, 0x92, 0xe8, 0x2d, 0xa8, 0x8c, 0x47,
+	0x36, 0x25, 0x7c, 0xa3, 0x25, 0xab, 0x3c, 0x1e, 0xed, 0x11, 0xf3, 0xdf, 0x1a, 0xb4, 0xf3, 0xad,
+	0x90, 0xef, 0xcf, 0x67, 0x37, 0xb2, 0x1e, 0x23, 0x1c, 0xf6, 0xea, 0x71, 0x1e, 0x73, 0xac, 0x9b,
+	0x4c, 0x3c, 0x56, 0xcb, 0xaf, 0xfb, 0x58, 0xcd, 0x85, 0x8f, 0x5f, 0x69, 0xd0, 0xce, 0xb3, 0xa2,
+	0x15, 0x28, 0x79, 0xc4, 0x95, 0x41, 0xf9, 0x62, 0xe1, 0x46, 0x15, 0x8f, 0xc5, 0x90, 0xe8, 0xff,
+	0x99, 0x7e, 0x8e, 0x67, 0xb3, 0x1e, 0x66, 0xde, 0x8b, 0x54, 0x83, 0xb3, 0xb3, 0xa7, 0x1a, 0x9c,
+	0x56, 0x9d, 0x81, 0xd9, 0x90, 0xc5, 0x98, 0xa1, 0x1f, 0xc7, 0x7e, 0x38, 0x90, 0x91, 0x50, 0x0d,
+	0xcd, 0xdf, 0xeb, 0x00, 0x69, 0xfb, 0x0c, 0xad, 0x67, 0x2d, 0x28, 0x92, 0xfc, 0xe5, 0xc2, 0x8d,
+	0x09, 0x9e, 0x22, 0xfb, 0xdd, 0xc9, 0xda, 0x4f, 0x1c, 0x59, 0xa6, 0x67, 0x73, 0x92, 0xdd, 0x4a,
+	0x93, 0x65, 0xe8, 0x1d, 0xa8, 0xc5, 0x34, 0xc2, 0xce, 0x50, 0x1d, 0xc5, 0xf9, 0x7c, 0xd7, 0x6f,
+	0x97, 0x4f, 0x5b, 0x0a, 0x96, 0xf5, 0x97, 0xca, 0x49, 0xfe, 0x72, 0x4f, 0x05, 0xaa, 0xea, 0x8c,
+	0xc6, 0xcf, 0x9e, 0x13, 0x0d, 0x30, 0x95, 0x1d, 0x49, 0xfe, 0x5b, 0x06, 0x28, 0xf3, 0x6b, 0x0d,
+	0x5a, 0xd9, 0x4d, 0xa0, 0x4b, 0xd0, 0x62, 0x71, 0x62, 0x88, 0x6d, 0x4a, 0x0e, 0xb0, 0x68, 0x08,
+	0xb7, 0xac, 0xa6, 0xa0, 0xed, 0x31, 0xd2, 0xa4, 0x81, 0xf5, 0xd7, 0x32, 0x30, 0x82, 0x32, 0xef,
+	0xbb, 0x31, 0x53, 0x55, 0x2c, 0xfe, 0x9b, 0xa5, 0xca, 0x46, 0xd2, 0xd6, 0x44, 0x97, 0xa1, 0x44,
+	0x46, 0xea, 0xfc, 0x32, 0xc5, 0xf2, 0x17, 0x91, 0x4f, 0xf1, 0xce, 0xc8, 0x62, 0xb3, 0xd9, 0x47,
+	0x93, 0xfe, 0x66, 0x8f, 0xa6, 0xc9, 0x33, 0x33, 0x7f, 0xae, 0x43, 0x4d, 0xae, 0x94, 0x69, 0x6c,
+	0x6b, 0xdf, 0xa5, 0xb1, 0xad, 0x9f, 0xba, 0xb1, 0x5d, 0x7a, 0xad, 0xc6, 0x76, 0xf9, 0xb5, 0x1b,
+	0xdb, 0x95, 0xd3, 0x34, 0xb6, 0xbb, 0x65, 0x56, 0x0f, 0x9a, 0x7f, 0xd1, 0x60, 0x3e, 0xd7, 0x38,
+	0x46, 0xd7, 0xb3, 0x47, 0xf3, 0x4e, 0x61, 0x83, 0x59, 0x1d, 0xd0, 0x55, 0x38, 0xdb, 0x1f, 0x87,
+	0x9c, 0x24, 0x0d, 0xad, 0x73, 0x43, 0xcf, 0x29, 0xaa, 0xb8, 0x22, 0x27, 0x77, 0x05, 0xef, 0x42,
+	0x5d, 0x1e, 0x9b, 0xba, 0x45, 0x17, 0x0a, 0x17, 0x56, 0x87, 0x9c, 0xa0, 0x67, 0x45, 0xb4, 0x3d,
+	0x98, 0x9b, 0xd8, 0x33, 0x42, 0xe2, 0x53, 0x07, 0x8f, 0xdf, 0xea, 0xbb, 0xc6, 0x75, 0xa8, 0xbc,
+	0x8a, 0xd2, 0x5a, 0x7b, 0xda, 0x15, 0x79, 0x69, 0x1e, 0xf9, 0x89, 0xc9, 0xfe, 0xaa, 0x03, 0x9a,
+	0xde, 0x11, 0xfa, 0x29, 0x2c, 0xf4, 0xf0, 0xc0, 0x0f, 0xed, 0xac, 0xa6, 0xc2, 0xa3, 0x6e, 0x15,
+	0xb7, 0x25, 0x19, 0x7a, 0x5a, 0x10, 0xeb, 0x7c, 0xf7, 0x72, 0x53, 0xc8, 0x86, 0xb7, 0xf8, 0x47,
+	0x00, 0x9b, 0xf5, 0xdf, 0xd5, 0x47, 0xa8, 0xd8, 0xd0, 0x5f, 0xa3, 0x81, 0xfa, 0xf0, 0x8c, 0xb5,
+	0xd0, 0xcb, 0xcf, 0xa1, 0x4f, 0xa1, 0xea, 0xf2, 0x5b, 0x74, 0xfa, 0xee, 0x04, 0xbf, 0x12, 0x9c,
+	0x80, 0xba, 0x50, 0x8f, 0x48, 0x10, 0xf4, 0x1c, 0xf7, 0xc0, 0x28, 0xcf, 0x78, 0xe3, 0x5b, 0x12,
+	0x94, 0x4a, 0x48, 0xf8, 0xba, 0x8d, 0xe4, 0xae, 0x9b, 0xbf, 0xd5, 0xa0, 0x9e, 0x64, 0xa0, 0x55,
+	0x28, 0x7b, 0xc4, 0x55, 0xee, 0x78, 0x42, 0x0a, 0xe2, 0x50, 0x74, 0x1b, 0x6a, 0xee, 0xbe, 0x13,
+	0x0e, 0x70, 0x41, 0xd3, 0x63, 0x93, 0xb8, 0x1b, 0x7c, 0xce, 0x52, 0x98, 0xc9, 0x94, 0x55, 0x3a,
+	0x7d, 0xca, 0x32, 0xff, 0xae, 0x41, 0x23, 0x91, 0x87, 0x6e, 0x41, 0xf9, 0xc0, 0x0f, 0x3d, 0x7e,
+	0xe6, 0x67, 0xd7, 0x8c, 0x82, 0x25, 0x3b, 0x9f, 0xfb, 0xa1, 0x67, 0x71, 0x94, 0x4a, 0xac, 0xfa,
+	0xa9, 0x13, 0xeb, 0x7b, 0xd0, 0x20, 0x81, 0x67, 0xfb, 0xa1, 0x87, 0x7f, 0x26, 0xe3, 0x6a, 0x9d,
+	0x04, 0xde, 0x23, 0x36, 0x66, 0x93, 0x21, 0x7e, 0x25, 0x27, 0xcb, 0x62, 0x32, 0xc4, 0xaf, 0xf8,
+	0xa4, 0xd9, 0x85, 0x32, 0x5b, 0x18, 0x9d, 0x83, 0xf6, 0xe7, 0x8f, 0x9e, 0x6c, 0xda, 0xcf, 0x9e,
+	0xec, 0x3e, 0xdd, 0xda, 0x78, 0x74, 0xff, 0xd1, 0xd6, 0x66, 0xfb, 0x0c, 0x6a, 0x40, 0x65, 0x7d,
+	0x73, 0x73, 0x6b, 0xb3, 0xad, 0xa1, 0x26, 0xd4, 0xac, 0xad, 0xed, 0x9d, 0xe7, 0x5b, 0x9b, 0x6d,
+	0x1d, 0xb5, 0xa0, 0xbe, 0xbd, 0xb3, 0x29, 0x50, 0x25, 0xf3, 0x4b, 0xd6, 0x35, 0x53, 0x9f, 0x4c,
+	0xbe, 0x7b, 0x55, 0x30, 0xf3, 0x99, 0xf5, 0x07, 0x1d, 0x20, 0xfd, 0x7e, 0xc3, 0x02, 0x59, 0xec,
+	0xee, 0xe3, 0xa1, 0x23, 0xe5, 0x9f, 0xcf, 0x7f, 0xe5, 0xd9, 0xe5, 0xb3, 0x96, 0x44, 0x4d, 0xb5,
+	0xf0, 0xf4, 0xa9, 0x16, 0xde, 0xf9, 0xe4, 0x3b, 0x90, 0x48, 0x03, 0x72, 0x84, 0x3e, 0x4a, 0xbe,
+	0x27, 0x95, 0x67, 0x68, 0xb2, 0xed, 0x8c, 0x44, 0xc5, 0x2b, 0xc1, 0xdc, 0x23, 0x99, 0x1e, 0x95,
+	0xd3, 0x30, 0x71, 0x28, 0xfa, 0x11, 0x2c, 0xc4, 0x38, 0x3a, 0xc4, 0x91, 0x9d, 0x7c, 0xdb, 0x55,
+	0x5f, 0x19, 0x0a, 0xdf, 0x5e, 0x6d, 0x81, 0x4e, 0x9c, 0x6e, 0xe6, 0x57, 0x86, 0x7b, 0xd0, 0xca,
+	0x1a, 0x06, 0xdd, 0xce, 0xb5, 0x40, 0xde, 0xce, 0x1b, 0x90, 0xaf, 0x93, 0x34, 0x41, 0xbe, 0xd5,
+	0xa0, 0x99, 0xa1, 0x27, 0xaf, 0x4e, 0x2d, 0xf3, 0xea, 0x5c, 0x86, 0x32, 0x3d, 0x1a, 0xa9, 0x18,
+	0x39, 0xfd, 0xdd, 0xed, 0x68, 0x84, 0x2d, 0x8e, 0xe0, 0x19, 0x41, 0x19, 0xc2, 0xe6, 0x72, 0xc4,
+	0x8b, 0x71, 0x2e, 0xa1, 0x3e, 0x61, 0x02, 0x2f, 0x02, 0x10, 0xd6, 0xdf, 0xc1, 0xc3, 0x11, 0x3d,
+	0x92, 0x2f, 0xc7, 0x06, 0xa3, 0x6c, 0x31, 0x02, 0x7b, 0xda, 0xab, 0x28, 0x67, 0xfb, 0x9e, 0x8c,
+	0xeb, 0xa0, 0x48, 0x8f, 0x3c, 0xd6, 0xfb, 0xcb, 0x1b, 0x54, 0x76, 0x7a, 0xe7, 0x73, 0xa6, 0x33,
+	0xff, 0xa9, 0x25, 0xee, 0xc5, 0x36, 0x68, 0x30, 0xf7, 0x72, 0x02, 0x27, 0x4a, 0xd2, 0x80, 0x1c,
+	0xa3, 0x35, 0xa8, 0x87, 0xe3, 0x20, 0x70, 0x7a, 0xc1, 0x4c, 0x45, 0x59, 0xd4, 0x52, 0x38, 0x74,
+	0x0b, 0x2a, 0x4e, 0x14, 0x39, 0x47, 0x46, 0x69, 0x26, 0x83, 0x00, 0xa1, 0x65, 0x28, 0x0d, 0x9d,
+	0x91, 0x51, 0x9e, 0x89, 0x65, 0x10, 0x74, 0x27, 0x71, 0xcd, 0xca, 0xac, 0x4b, 0x90, 0x7e, 0xec,
+	0xe4, 0x1f, 0x16, 0x8f, 0x46, 0xb8, 0xfb, 0xb5, 0x06, 0xd7, 0x5d, 0x32, 0x54, 0x5e, 0xe9, 0x06,
+	0x64, 0xec, 0x65, 0x7c, 0xd3, 0x25, 0x61, 0x9f, 0x44, 0x43, 0x27, 0x74, 0x99, 0x9f, 0x7e, 0x29,
+	0xfe, 0x2e, 0xf0, 0x8d, 0x7e, 0xf5, 0x81, 0x80, 0x6f, 0x70, 0xf8, 0xfd, 0x04, 0xbe, 0xc7, 0x57,
+	0x7d, 0x1a, 0x11, 0x4a, 0x3a, 0xcf, 0x57, 0xff, 0xa8, 0xdf, 0x14, 0xb8, 0x17, 0x1c, 0xf7, 0x22,
+	0xc1, 0xbd, 0xe0, 0xb8, 0x17, 0x1b, 0xa9, 0xf0, 0x17, 0xcf, 0x57, 0x7b, 0x55, 0x1e, 0x3d, 0x3f,
+	0xfc, 0xcf, 0x00, 0x3e, 0x42, 0xd2, 0x20, 0x86, 0x21, 0x00, 0x00,
+}
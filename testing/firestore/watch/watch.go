@@ -0,0 +1,643 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watch is a reference model of how a client turns the responses on a
+// Listen stream into query snapshots.
+//
+// It implements the rules that the ListenTests check: a snapshot is produced
+// at a global NO_CHANGE response once the target is CURRENT, if the results
+// changed or no snapshot has been produced yet; RESET and a mismatched
+// ExistenceFilter discard the client's view of the results, and the latter
+// also makes the client close its stream and open a new one; the changes in a
+// snapshot are ordered removals first, then additions, then modifications,
+// each in query order; and a client whose stream ends with a retryable error
+// reopens it, resuming from its last consistent point. The generator uses it to
+// check the snapshots it writes, and client authors can read it as an
+// executable specification.
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/golang/protobuf/proto"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc/codes"
+)
+
+// TargetID is the target ID that the ListenTests assume the client uses.
+const TargetID = 1
+
+// A ListenTest without clauses listens to a query on the collection "C",
+// ordered by "a".
+var defaultOrders = []*fspb.StructuredQuery_Order{{
+	Field:     &fspb.StructuredQuery_FieldReference{FieldPath: "a"},
+	Direction: fspb.StructuredQuery_ASCENDING,
+}}
+
+// Snapshots returns the snapshots that a client listening to the query of t
+// produces from the responses on its streams. If the responses or the end of a
+// stream make the client signal an error, Snapshots returns the snapshots
+// produced before it, along with the error.
+func Snapshots(t *tpb.ListenTest) ([]*tpb.Snapshot, error) {
+	snaps, _, err := run(t)
+	return snaps, err
+}
+
+// ResumeTokens returns the resume token that a client listening to the query
+// of t sends when it opens each of the streams it gets to, or nil if it sends
+// none.
+func ResumeTokens(t *tpb.ListenTest) [][]byte {
+	_, tokens, _ := run(t)
+	return tokens
+}
+
+// Streams returns the Listen streams of t. A test without t.Streams has a
+// single stream holding t.Responses.
+func Streams(t *tpb.ListenTest) []*tpb.ListenStream {
+	if len(t.Streams) > 0 {
+		return t.Streams
+	}
+	return []*tpb.ListenStream{{Responses: t.Responses}}
+}
+
+func run(t *tpb.ListenTest) (snaps []*tpb.Snapshot, tokens [][]byte, err error) {
+	w := newWatcher(queryOrders(t.Clauses))
+	streams := Streams(t)
+	for i, s := range streams {
+		tokens = append(tokens, w.resumeToken)
+		w.closed = false
+		for j, res := range s.Responses {
+			if w.closed {
+				return snaps, tokens, fmt.Errorf("stream #%d: response #%d arrives after the client closed the stream", i, j)
+			}
+			snap, err := w.handle(res)
+			if err != nil {
+				return snaps, tokens, err
+			}
+			if snap != nil {
+				snaps = append(snaps, snap)
+			}
+		}
+		switch code := codes.Code(s.Code); {
+		case w.closed:
+			// The client reopens the stream it closed.
+		case i == len(streams)-1 && code == codes.OK:
+			return snaps, tokens, nil // the stream stays open
+		case !retryable(code):
+			return snaps, tokens, fmt.Errorf("stream ended with %s", code)
+		}
+		// Changes since the last consistent point will be sent again. Without
+		// a resume token, the new stream starts from scratch.
+		w.changes = map[string]*fspb.Document{}
+		if len(w.resumeToken) == 0 {
+			w.reset()
+		}
+	}
+	return snaps, tokens, nil
+}
+
+// retryable reports whether a client reopens a stream that ends with code.
+func retryable(code codes.Code) bool {
+	switch code {
+	case codes.OK, codes.Unknown, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Internal, codes.Unavailable, codes.Unauthenticated:
+		return true
+	default:
+		return false
+	}
+}
+
+// queryOrders returns the order of the results of a query with the given
+// clauses, before the final order by document name. The fields of inequality
+// filters that no OrderBy clause names are ordered implicitly after the
+// explicit orders, in lexicographic order and in the direction of the last
+// explicit order. Filters do not otherwise matter: the service sends only the
+// documents that match them.
+func queryOrders(clauses []*tpb.Clause) []*fspb.StructuredQuery_Order {
+	if len(clauses) == 0 {
+		return defaultOrders
+	}
+	var orders []*fspb.StructuredQuery_Order
+	ordered := map[string]bool{}
+	inequalities := map[string]*tpb.FieldPath{}
+	for _, c := range clauses {
+		switch c := c.Clause.(type) {
+		case *tpb.Clause_OrderBy:
+			dir := fspb.StructuredQuery_ASCENDING
+			if c.OrderBy.Direction == "desc" {
+				dir = fspb.StructuredQuery_DESCENDING
+			}
+			o := order(c.OrderBy.Path, dir)
+			ordered[o.Field.FieldPath] = true
+			orders = append(orders, o)
+		case *tpb.Clause_Where:
+			if isInequality(c.Where.Op) {
+				inequalities[encodeFieldPath(c.Where.Path.Field)] = c.Where.Path
+			}
+		case *tpb.Clause_Filter:
+			filterInequalities(c.Filter, inequalities)
+		}
+	}
+	var implicit []string
+	for f := range inequalities {
+		if !ordered[f] {
+			implicit = append(implicit, f)
+		}
+	}
+	sort.Strings(implicit)
+	dir := fspb.StructuredQuery_ASCENDING
+	if len(orders) > 0 {
+		dir = orders[len(orders)-1].Direction
+	}
+	for _, f := range implicit {
+		orders = append(orders, order(inequalities[f], dir))
+	}
+	return orders
+}
+
+// filterInequalities adds the fields of the inequalities in f to m, keyed by
+// their encoded paths.
+func filterInequalities(f *tpb.Filter, m map[string]*tpb.FieldPath) {
+	switch f := f.Filter.(type) {
+	case *tpb.Filter_Where:
+		if isInequality(f.Where.Op) {
+			m[encodeFieldPath(f.Where.Path.Field)] = f.Where.Path
+		}
+	case *tpb.Filter_Composite:
+		for _, g := range f.Composite.Filters {
+			filterInequalities(g, m)
+		}
+	}
+}
+
+func isInequality(op string) bool {
+	switch op {
+	case "<", "<=", ">", ">=", "!=", "not-in":
+		return true
+	default:
+		return false
+	}
+}
+
+func order(fp *tpb.FieldPath, dir fspb.StructuredQuery_Direction) *fspb.StructuredQuery_Order {
+	return &fspb.StructuredQuery_Order{
+		Field:     &fspb.StructuredQuery_FieldReference{FieldPath: encodeFieldPath(fp.Field)},
+		Direction: dir,
+	}
+}
+
+// A watcher holds the state of a client listening to a query.
+type watcher struct {
+	orders      []*fspb.StructuredQuery_Order // the query's order, ending with the document name
+	docs        []*fspb.Document              // the results in the last snapshot, in query order
+	docMap      map[string]*fspb.Document     // the same documents, by name
+	changes     map[string]*fspb.Document     // changes since then, by name; nil means deleted
+	current     bool                          // whether the target is CURRENT
+	hasReturned bool                          // whether a snapshot has been produced
+	resumeToken []byte                        // the token of the last consistent point
+	closed      bool                          // whether the client closed the stream
+}
+
+func newWatcher(orders []*fspb.StructuredQuery_Order) *watcher {
+	// Documents with equal fields are ordered by name, in the direction of the
+	// last explicit order.
+	dir := fspb.StructuredQuery_ASCENDING
+	if len(orders) > 0 {
+		dir = orders[len(orders)-1].Direction
+	}
+	orders = append(orders[:len(orders):len(orders)], &fspb.StructuredQuery_Order{
+		Field:     &fspb.StructuredQuery_FieldReference{FieldPath: "__name__"},
+		Direction: dir,
+	})
+	return &watcher{
+		orders:  orders,
+		docMap:  map[string]*fspb.Document{},
+		changes: map[string]*fspb.Document{},
+	}
+}
+
+// handle processes a single response, and returns the snapshot it produces,
+// if any.
+func (w *watcher) handle(res *fspb.ListenResponse) (*tpb.Snapshot, error) {
+	switch r := res.ResponseType.(type) {
+	case *fspb.ListenResponse_TargetChange:
+		return w.handleTargetChange(r.TargetChange)
+	case *fspb.ListenResponse_DocumentChange:
+		dc := r.DocumentChange
+		switch {
+		case hasTargetID(dc.TargetIds):
+			w.changes[dc.Document.Name] = dc.Document
+		case hasTargetID(dc.RemovedTargetIds):
+			w.changes[dc.Document.Name] = nil
+		}
+	case *fspb.ListenResponse_DocumentDelete:
+		w.changes[r.DocumentDelete.Document] = nil
+	case *fspb.ListenResponse_DocumentRemove:
+		w.changes[r.DocumentRemove.Document] = nil
+	case *fspb.ListenResponse_Filter:
+		if int(r.Filter.Count) != w.currentSize() {
+			// The client's view of the results is wrong. It starts over, as
+			// if the target had been reset, on a new stream.
+			w.reset()
+			w.closed = true
+		}
+	default:
+		return nil, fmt.Errorf("unknown response type %T", res.ResponseType)
+	}
+	return nil, nil
+}
+
+func (w *watcher) handleTargetChange(tc *fspb.TargetChange) (*tpb.Snapshot, error) {
+	switch tc.TargetChangeType {
+	case fspb.TargetChange_NO_CHANGE:
+		if len(tc.TargetIds) == 0 && tc.ReadTime != nil && w.current {
+			w.resumeToken = tc.ResumeToken
+			return w.snapshot(tc.ReadTime), nil
+		}
+	case fspb.TargetChange_ADD:
+		if len(tc.TargetIds) != 1 || tc.TargetIds[0] != TargetID {
+			return nil, fmt.Errorf("ADD for unexpected target IDs %v", tc.TargetIds)
+		}
+	case fspb.TargetChange_REMOVE:
+		if tc.Cause != nil {
+			return nil, fmt.Errorf("target removed: %s", tc.Cause.Message)
+		}
+		return nil, errors.New("target removed")
+	case fspb.TargetChange_CURRENT:
+		w.current = true
+	case fspb.TargetChange_RESET:
+		w.reset()
+	default:
+		return nil, fmt.Errorf("unknown target change type %s", tc.TargetChangeType)
+	}
+	return nil, nil
+}
+
+// reset discards the pending changes and the resume token, and marks every
+// document as deleted. Documents that are still results will be sent again.
+func (w *watcher) reset() {
+	w.changes = map[string]*fspb.Document{}
+	for _, d := range w.docs {
+		w.changes[d.Name] = nil
+	}
+	w.current = false
+	w.resumeToken = nil
+}
+
+// currentSize returns the number of results after the pending changes.
+func (w *watcher) currentSize() int {
+	deletes, adds, _ := w.extractChanges()
+	return len(w.docs) + len(adds) - len(deletes)
+}
+
+// extractChanges sorts the pending changes into deleted documents (their old
+// versions), added documents and modified documents (their new versions). A
+// document is modified only if its update time changed.
+func (w *watcher) extractChanges() (deletes, adds, mods []*fspb.Document) {
+	for name, doc := range w.changes {
+		old, ok := w.docMap[name]
+		switch {
+		case doc == nil && ok:
+			deletes = append(deletes, old)
+		case doc == nil:
+		case !ok:
+			adds = append(adds, doc)
+		case !proto.Equal(doc.UpdateTime, old.UpdateTime):
+			mods = append(mods, doc)
+		}
+	}
+	return deletes, adds, mods
+}
+
+// snapshot applies the pending changes. It returns a snapshot if they changed
+// the results or no snapshot has been produced yet, and nil otherwise.
+func (w *watcher) snapshot(readTime *tspb.Timestamp) *tpb.Snapshot {
+	deletes, adds, mods := w.extractChanges()
+	w.changes = map[string]*fspb.Document{}
+	var changes []*tpb.DocChange
+	// Each kind of change is applied in query order, so that the indexes of
+	// successive changes increase.
+	w.sort(deletes)
+	for _, d := range deletes {
+		changes = append(changes, &tpb.DocChange{
+			Kind:     tpb.DocChange_REMOVED,
+			Doc:      d,
+			OldIndex: int32(w.remove(d)),
+			NewIndex: -1,
+		})
+	}
+	w.sort(adds)
+	for _, d := range adds {
+		changes = append(changes, &tpb.DocChange{
+			Kind:     tpb.DocChange_ADDED,
+			Doc:      d,
+			OldIndex: -1,
+			NewIndex: int32(w.insert(d)),
+		})
+	}
+	w.sort(mods)
+	for _, d := range mods {
+		oldIndex := w.remove(w.docMap[d.Name])
+		changes = append(changes, &tpb.DocChange{
+			Kind:     tpb.DocChange_MODIFIED,
+			Doc:      d,
+			OldIndex: int32(oldIndex),
+			NewIndex: int32(w.insert(d)),
+		})
+	}
+	if len(changes) == 0 && w.hasReturned {
+		return nil
+	}
+	w.hasReturned = true
+	return &tpb.Snapshot{
+		Docs:     append([]*fspb.Document(nil), w.docs...),
+		Changes:  changes,
+		ReadTime: readTime,
+	}
+}
+
+// remove removes d from the results and returns its index.
+func (w *watcher) remove(d *fspb.Document) int {
+	i := w.search(d)
+	w.docs = append(w.docs[:i], w.docs[i+1:]...)
+	delete(w.docMap, d.Name)
+	return i
+}
+
+// insert adds d to the results and returns its index.
+func (w *watcher) insert(d *fspb.Document) int {
+	i := w.search(d)
+	w.docs = append(w.docs, nil)
+	copy(w.docs[i+1:], w.docs[i:])
+	w.docs[i] = d
+	w.docMap[d.Name] = d
+	return i
+}
+
+// search returns the index of the first result that is not before d.
+func (w *watcher) search(d *fspb.Document) int {
+	return sort.Search(len(w.docs), func(i int) bool { return w.compare(w.docs[i], d) >= 0 })
+}
+
+func (w *watcher) sort(docs []*fspb.Document) {
+	sort.Slice(docs, func(i, j int) bool { return w.compare(docs[i], docs[j]) < 0 })
+}
+
+// compare orders two documents as the query does.
+func (w *watcher) compare(a, b *fspb.Document) int {
+	for _, o := range w.orders {
+		c := compareValues(field(a, o.Field.FieldPath), field(b, o.Field.FieldPath))
+		if o.Direction == fspb.StructuredQuery_DESCENDING {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func hasTargetID(ids []int32) bool {
+	for _, id := range ids {
+		if id == TargetID {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the value at the encoded field path fp in d, or nil if there
+// is none. The path "__name__" refers to the document's name.
+func field(d *fspb.Document, fp string) *fspb.Value {
+	if fp == "__name__" {
+		return &fspb.Value{ValueType: &fspb.Value_ReferenceValue{ReferenceValue: d.Name}}
+	}
+	fields := d.Fields
+	var v *fspb.Value
+	for _, name := range splitFieldPath(fp) {
+		if fields == nil {
+			return nil
+		}
+		v = fields[name]
+		fields = v.GetMapValue().GetFields()
+	}
+	return v
+}
+
+// encodeFieldPath returns the string form of a field path, quoting components
+// that are not simple names with backquotes. It is the inverse of
+// splitFieldPath.
+func encodeFieldPath(p []string) string {
+	var parts []string
+	for _, c := range p {
+		if !isSimpleFieldName(c) {
+			c = strings.Replace(c, `\`, `\\`, -1)
+			c = "`" + strings.Replace(c, "`", "\\`", -1) + "`"
+		}
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, ".")
+}
+
+func isSimpleFieldName(s string) bool {
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// splitFieldPath splits an encoded field path into its components, removing
+// backquotes and backslash escapes.
+func splitFieldPath(fp string) []string {
+	var parts []string
+	var buf strings.Builder
+	quoted := false
+	for i := 0; i < len(fp); i++ {
+		switch c := fp[i]; {
+		case c == '\\' && quoted && i+1 < len(fp):
+			i++
+			buf.WriteByte(fp[i])
+		case c == '`':
+			quoted = !quoted
+		case c == '.' && !quoted:
+			parts = append(parts, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return append(parts, buf.String())
+}
+
+// typeOrder returns the position of v's type in Firestore's ordering of
+// values. A missing value comes first.
+func typeOrder(v *fspb.Value) int {
+	switch v.GetValueType().(type) {
+	case nil:
+		return 0
+	case *fspb.Value_NullValue:
+		return 1
+	case *fspb.Value_BooleanValue:
+		return 2
+	case *fspb.Value_IntegerValue, *fspb.Value_DoubleValue:
+		return 3
+	case *fspb.Value_TimestampValue:
+		return 4
+	case *fspb.Value_StringValue:
+		return 5
+	case *fspb.Value_BytesValue:
+		return 6
+	case *fspb.Value_ReferenceValue:
+		return 7
+	case *fspb.Value_GeoPointValue:
+		return 8
+	case *fspb.Value_ArrayValue:
+		return 9
+	case *fspb.Value_MapValue:
+		return 10
+	default:
+		panic(fmt.Sprintf("unknown value type %T", v.ValueType))
+	}
+}
+
+// compareValues orders two values as Firestore does.
+func compareValues(a, b *fspb.Value) int {
+	if c := compareInts(int64(typeOrder(a)), int64(typeOrder(b))); c != 0 {
+		return c
+	}
+	switch av := a.GetValueType().(type) {
+	case nil, *fspb.Value_NullValue:
+		return 0
+	case *fspb.Value_BooleanValue:
+		return compareBools(av.BooleanValue, b.GetBooleanValue())
+	case *fspb.Value_IntegerValue:
+		if bi, ok := b.ValueType.(*fspb.Value_IntegerValue); ok {
+			return compareInts(av.IntegerValue, bi.IntegerValue)
+		}
+		return compareFloats(float64(av.IntegerValue), b.GetDoubleValue())
+	case *fspb.Value_DoubleValue:
+		if bi, ok := b.ValueType.(*fspb.Value_IntegerValue); ok {
+			return compareFloats(av.DoubleValue, float64(bi.IntegerValue))
+		}
+		return compareFloats(av.DoubleValue, b.GetDoubleValue())
+	case *fspb.Value_TimestampValue:
+		at, bt := av.TimestampValue, b.GetTimestampValue()
+		if c := compareInts(at.Seconds, bt.Seconds); c != 0 {
+			return c
+		}
+		return compareInts(int64(at.Nanos), int64(bt.Nanos))
+	case *fspb.Value_StringValue:
+		return strings.Compare(av.StringValue, b.GetStringValue())
+	case *fspb.Value_BytesValue:
+		return bytes.Compare(av.BytesValue, b.GetBytesValue())
+	case *fspb.Value_ReferenceValue:
+		as, bs := strings.Split(av.ReferenceValue, "/"), strings.Split(b.GetReferenceValue(), "/")
+		for i := 0; i < len(as) && i < len(bs); i++ {
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(as)), int64(len(bs)))
+	case *fspb.Value_GeoPointValue:
+		ag, bg := av.GeoPointValue, b.GetGeoPointValue()
+		if c := compareFloats(ag.Latitude, bg.Latitude); c != 0 {
+			return c
+		}
+		return compareFloats(ag.Longitude, bg.Longitude)
+	case *fspb.Value_ArrayValue:
+		as, bs := av.ArrayValue.Values, b.GetArrayValue().GetValues()
+		for i := 0; i < len(as) && i < len(bs); i++ {
+			if c := compareValues(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(as)), int64(len(bs)))
+	case *fspb.Value_MapValue:
+		// Maps are compared key by key, in key order.
+		am, bm := av.MapValue.Fields, b.GetMapValue().GetFields()
+		ak, bk := sortedKeys(am), sortedKeys(bm)
+		for i := 0; i < len(ak) && i < len(bk); i++ {
+			if c := strings.Compare(ak[i], bk[i]); c != 0 {
+				return c
+			}
+			if c := compareValues(am[ak[i]], bm[bk[i]]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(ak)), int64(len(bk)))
+	default:
+		panic(fmt.Sprintf("unknown value type %T", a.ValueType))
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareFloats orders NaN before all other numbers.
+func compareFloats(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return -1
+	case math.IsNaN(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func sortedKeys(m map[string]*fspb.Value) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
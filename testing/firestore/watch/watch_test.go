@@ -0,0 +1,333 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc/codes"
+)
+
+const docPrefix = "projects/projectID/databases/(default)/documents/C/"
+
+func doc(name string, a int64, updated int64) *fspb.Document {
+	return &fspb.Document{
+		Name:       docPrefix + name,
+		Fields:     map[string]*fspb.Value{"a": {ValueType: &fspb.Value_IntegerValue{IntegerValue: a}}},
+		CreateTime: &tspb.Timestamp{Seconds: 1},
+		UpdateTime: &tspb.Timestamp{Seconds: updated},
+	}
+}
+
+func change(d *fspb.Document) *fspb.ListenResponse {
+	return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_DocumentChange{
+		DocumentChange: &fspb.DocumentChange{Document: d, TargetIds: []int32{TargetID}},
+	}}
+}
+
+func targetChange(typ fspb.TargetChange_TargetChangeType, ids ...int32) *fspb.ListenResponse {
+	return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{
+		TargetChange: &fspb.TargetChange{TargetChangeType: typ, TargetIds: ids},
+	}}
+}
+
+var current = targetChange(fspb.TargetChange_CURRENT, TargetID)
+
+// noChange returns a global NO_CHANGE at the given second, with a resume token
+// naming it.
+func noChange(sec int64) *fspb.ListenResponse {
+	return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_TargetChange{
+		TargetChange: &fspb.TargetChange{
+			TargetChangeType: fspb.TargetChange_NO_CHANGE,
+			ReadTime:         &tspb.Timestamp{Seconds: sec},
+			ResumeToken:      []byte(fmt.Sprint(sec)),
+		},
+	}}
+}
+
+func existence(count int32) *fspb.ListenResponse {
+	return &fspb.ListenResponse{ResponseType: &fspb.ListenResponse_Filter{
+		Filter: &fspb.ExistenceFilter{TargetId: TargetID, Count: count},
+	}}
+}
+
+// describe summarizes snapshots as strings like "@1 [d1 d2] +d1@0 +d2@1",
+// where a change is written "-name@old", "+name@new" or "~name@old>new".
+func describe(snaps []*tpb.Snapshot) []string {
+	var ds []string
+	for _, s := range snaps {
+		var names []string
+		for _, d := range s.Docs {
+			names = append(names, strings.TrimPrefix(d.Name, docPrefix))
+		}
+		parts := []string{fmt.Sprintf("@%d [%s]", s.ReadTime.Seconds, strings.Join(names, " "))}
+		for _, c := range s.Changes {
+			name := strings.TrimPrefix(c.Doc.Name, docPrefix)
+			switch c.Kind {
+			case tpb.DocChange_REMOVED:
+				parts = append(parts, fmt.Sprintf("-%s@%d", name, c.OldIndex))
+			case tpb.DocChange_ADDED:
+				parts = append(parts, fmt.Sprintf("+%s@%d", name, c.NewIndex))
+			case tpb.DocChange_MODIFIED:
+				parts = append(parts, fmt.Sprintf("~%s@%d>%d", name, c.OldIndex, c.NewIndex))
+			}
+		}
+		ds = append(ds, strings.Join(parts, " "))
+	}
+	return ds
+}
+
+func TestSnapshots(t *testing.T) {
+	d1, d2, d3 := doc("d1", 1, 1), doc("d2", 2, 1), doc("d3", 3, 1)
+	for _, test := range []struct {
+		desc      string
+		responses []*fspb.ListenResponse
+		want      []string
+	}{
+		{
+			desc:      "no snapshot before CURRENT",
+			responses: []*fspb.ListenResponse{change(d1), noChange(1), current, noChange(2)},
+			want:      []string{"@2 [d1] +d1@0"},
+		},
+		{
+			desc:      "an empty first snapshot",
+			responses: []*fspb.ListenResponse{current, noChange(1)},
+			want:      []string{"@1 []"},
+		},
+		{
+			desc: "only global NO_CHANGEs with a read time",
+			responses: []*fspb.ListenResponse{
+				change(d1), current,
+				targetChange(fspb.TargetChange_NO_CHANGE, TargetID),
+				targetChange(fspb.TargetChange_NO_CHANGE),
+				noChange(1),
+			},
+			want: []string{"@1 [d1] +d1@0"},
+		},
+		{
+			desc: "no snapshot without changes",
+			responses: []*fspb.ListenResponse{
+				change(d1), current, noChange(1), noChange(2), change(d1), noChange(3),
+			},
+			want: []string{"@1 [d1] +d1@0"},
+		},
+		{
+			desc: "removed from the target",
+			responses: []*fspb.ListenResponse{
+				change(d1), change(d2), change(d3), current, noChange(1),
+				{ResponseType: &fspb.ListenResponse_DocumentChange{
+					DocumentChange: &fspb.DocumentChange{Document: d2, RemovedTargetIds: []int32{TargetID}},
+				}},
+				{ResponseType: &fspb.ListenResponse_DocumentRemove{
+					DocumentRemove: &fspb.DocumentRemove{Document: d3.Name, RemovedTargetIds: []int32{TargetID}},
+				}},
+				noChange(2),
+			},
+			want: []string{
+				"@1 [d1 d2 d3] +d1@0 +d2@1 +d3@2",
+				"@2 [d1] -d2@1 -d3@1",
+			},
+		},
+		{
+			desc: "removals, additions, then modifications",
+			responses: []*fspb.ListenResponse{
+				change(d1), change(d2), current, noChange(1),
+				change(doc("d1", 4, 2)), change(d3),
+				{ResponseType: &fspb.ListenResponse_DocumentDelete{
+					DocumentDelete: &fspb.DocumentDelete{Document: d2.Name},
+				}},
+				noChange(2),
+			},
+			want: []string{
+				"@1 [d1 d2] +d1@0 +d2@1",
+				"@2 [d3 d1] -d2@1 +d3@1 ~d1@0>1",
+			},
+		},
+		{
+			desc: "RESET",
+			responses: []*fspb.ListenResponse{
+				change(d1), change(d2), current, noChange(1),
+				targetChange(fspb.TargetChange_RESET, TargetID),
+				change(d2), current, noChange(2),
+			},
+			want: []string{"@1 [d1 d2] +d1@0 +d2@1", "@2 [d2] -d1@0"},
+		},
+		{
+			desc: "a matching filter",
+			responses: []*fspb.ListenResponse{
+				change(d1), change(d2), current, noChange(1), change(d3), existence(3), noChange(2),
+			},
+			want: []string{"@1 [d1 d2] +d1@0 +d2@1", "@2 [d1 d2 d3] +d3@2"},
+		},
+	} {
+		snaps, err := Snapshots(&tpb.ListenTest{Responses: test.responses})
+		if err != nil {
+			t.Errorf("%s: %v", test.desc, err)
+			continue
+		}
+		if got := describe(snaps); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\ngot  %q\nwant %q", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestFilterMismatch(t *testing.T) {
+	d1, d2 := doc("d1", 1, 1), doc("d2", 2, 1)
+	lt := &tpb.ListenTest{Streams: []*tpb.ListenStream{
+		{Responses: []*fspb.ListenResponse{
+			change(d1), change(d2), current, noChange(1),
+			// The count does not match, so the client closes the stream.
+			existence(1),
+		}},
+		// The client starts over without a resume token.
+		{Responses: []*fspb.ListenResponse{change(d1), current, noChange(2)}},
+	}}
+	snaps, err := Snapshots(lt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"@1 [d1 d2] +d1@0 +d2@1", "@2 [d1] -d2@1"}
+	if got := describe(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if got, want := ResumeTokens(lt), [][]byte{nil, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resume tokens: got %q, want %q", got, want)
+	}
+
+	// A response after the filter is an error in the test.
+	lt.Streams[0].Responses = append(lt.Streams[0].Responses, noChange(2))
+	if _, err := Snapshots(lt); err == nil {
+		t.Error("response after a mismatched filter: got nil, want error")
+	}
+}
+
+func TestStreamEnds(t *testing.T) {
+	d1, d2 := doc("d1", 1, 1), doc("d2", 2, 1)
+	lt := &tpb.ListenTest{Streams: []*tpb.ListenStream{
+		{
+			Responses: []*fspb.ListenResponse{change(d1), current, noChange(1), change(d2)},
+			Code:      int32(codes.Unavailable),
+		},
+		// The change to d2 is discarded; the client resumes from read time 1.
+		{Responses: []*fspb.ListenResponse{current, noChange(2)}},
+		{Code: int32(codes.PermissionDenied)},
+	}}
+	snaps, err := Snapshots(lt)
+	if err == nil {
+		t.Error("non-retryable code: got nil, want error")
+	}
+	want := []string{"@1 [d1] +d1@0"}
+	if got := describe(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if got, want := ResumeTokens(lt), [][]byte{nil, []byte("1"), []byte("2")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resume tokens: got %q, want %q", got, want)
+	}
+}
+
+func TestQueryOrders(t *testing.T) {
+	path := func(p ...string) *tpb.FieldPath { return &tpb.FieldPath{Field: p} }
+	orderBy := func(p *tpb.FieldPath, dir string) *tpb.Clause {
+		return &tpb.Clause{Clause: &tpb.Clause_OrderBy{OrderBy: &tpb.OrderBy{Path: p, Direction: dir}}}
+	}
+	where := func(p *tpb.FieldPath, op string) *tpb.Clause {
+		return &tpb.Clause{Clause: &tpb.Clause_Where{Where: &tpb.Where{Path: p, Op: op, JsonValue: "1"}}}
+	}
+	whereFilter := func(p *tpb.FieldPath, op string) *tpb.Filter {
+		return &tpb.Filter{Filter: &tpb.Filter_Where{Where: &tpb.Where{Path: p, Op: op, JsonValue: "1"}}}
+	}
+	for _, test := range []struct {
+		clauses []*tpb.Clause
+		want    []string
+	}{
+		{nil, []string{"a asc"}},
+		{[]*tpb.Clause{where(path("b"), "==")}, nil},
+		{[]*tpb.Clause{where(path("b"), ">")}, []string{"b asc"}},
+		{[]*tpb.Clause{where(path("b"), "not-in")}, []string{"b asc"}},
+		{[]*tpb.Clause{where(path("c"), "<"), where(path("b"), "!=")}, []string{"b asc", "c asc"}},
+		{[]*tpb.Clause{where(path("b"), ">"), orderBy(path("a"), "desc")}, []string{"a desc", "b desc"}},
+		{[]*tpb.Clause{where(path("b"), ">"), orderBy(path("b"), "desc")}, []string{"b desc"}},
+		{
+			[]*tpb.Clause{where(path("c"), ">"), orderBy(path("a"), "asc"), orderBy(path("b"), "desc")},
+			[]string{"a asc", "b desc", "c desc"},
+		},
+		{[]*tpb.Clause{where(path("a", "b c"), "<")}, []string{"a.`b c` asc"}},
+		{
+			[]*tpb.Clause{{Clause: &tpb.Clause_Filter{Filter: &tpb.Filter{Filter: &tpb.Filter_Composite{
+				Composite: &tpb.CompositeFilter{Op: "OR", Filters: []*tpb.Filter{
+					whereFilter(path("b"), "=="),
+					whereFilter(path("c"), ">="),
+				}},
+			}}}}},
+			[]string{"c asc"},
+		},
+	} {
+		if got := orderStrings(queryOrders(test.clauses)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %q, want %q", test.clauses, got, test.want)
+		}
+	}
+}
+
+func TestNewWatcherOrders(t *testing.T) {
+	desc := fspb.StructuredQuery_DESCENDING
+	for _, test := range []struct {
+		orders []*fspb.StructuredQuery_Order
+		want   []string
+	}{
+		{nil, []string{"__name__ asc"}},
+		{defaultOrders, []string{"a asc", "__name__ asc"}},
+		{
+			[]*fspb.StructuredQuery_Order{order(&tpb.FieldPath{Field: []string{"a"}}, desc)},
+			[]string{"a desc", "__name__ desc"},
+		},
+	} {
+		if got := orderStrings(newWatcher(test.orders).orders); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %q, want %q", orderStrings(test.orders), got, test.want)
+		}
+	}
+
+	// The watcher does not write into the caller's slice.
+	orders := make([]*fspb.StructuredQuery_Order, 1, 2)
+	orders[0] = defaultOrders[0]
+	newWatcher(orders)
+	if extra := orders[:2][1]; extra != nil {
+		t.Errorf("newWatcher wrote %s past the end of its argument", extra)
+	}
+
+	// Documents that tie on every field are ordered by name.
+	w := newWatcher(defaultOrders)
+	d1, d2 := doc("d1", 1, 1), doc("d2", 1, 1)
+	if got := w.compare(d1, d2); got >= 0 {
+		t.Errorf("compare(d1, d2) = %d, want < 0", got)
+	}
+}
+
+func orderStrings(orders []*fspb.StructuredQuery_Order) []string {
+	var ss []string
+	for _, o := range orders {
+		dir := "asc"
+		if o.Direction == fspb.StructuredQuery_DESCENDING {
+			dir = "desc"
+		}
+		ss = append(ss, o.Field.FieldPath+" "+dir)
+	}
+	return ss
+}
@@ -0,0 +1,877 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package writes is a reference model of how a client encodes the write
+// methods of a DocumentRef as a CommitRequest.
+//
+// Each function takes a test and returns the request that the test's call
+// should send, or an error if the client should reject the call. Input data
+// is JSON, with sentinel values encoded as in the tests themselves: the
+// strings "Delete" and "ServerTimestamp", and arrays whose first element is
+// "ArrayUnion", "ArrayRemove" or "Increment". Values without a JSON
+// equivalent use the extended encoding described in test.proto, like
+// {"$timestamp": "2016-01-02T03:04:05Z"}. The generator checks the expected
+// request of every write test against this package, and client authors can
+// read it as an executable specification.
+//
+// FieldPath models how a client parses a field path written as a string,
+// which field path tests check in the same way.
+package writes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+// Create returns the request that DocumentRef.Create sends.
+func Create(t *tpb.CreateTest) (*fspb.CommitRequest, error) {
+	data, err := parseData(t.JsonData)
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	fields, err := e.stripFields(data, false)
+	if err != nil {
+		return nil, err
+	}
+	// A document holding only transforms is created by applying them.
+	write := len(fields) > 0 || len(e.transforms) == 0
+	precond := &fspb.Precondition{ConditionType: &fspb.Precondition_Exists{Exists: false}}
+	return e.request(t.DocRefPath, write, fields, nil, precond)
+}
+
+// Set returns the request that DocumentRef.Set sends.
+func Set(t *tpb.SetTest) (*fspb.CommitRequest, error) {
+	data, err := parseData(t.JsonData)
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	switch {
+	case t.Option == nil:
+		fields, err := e.stripFields(data, false)
+		if err != nil {
+			return nil, err
+		}
+		return e.request(t.DocRefPath, true, fields, nil, nil)
+	case t.Option.All:
+		return e.setMergeAll(t.DocRefPath, data)
+	default:
+		return e.setMerge(t.DocRefPath, data, t.Option.Fields)
+	}
+}
+
+// setMergeAll encodes a Set with the MergeAll option. Every leaf field of
+// data is merged, and the Delete sentinel may appear anywhere outside arrays.
+func (e *encoder) setMergeAll(docPath string, data map[string]interface{}) (*fspb.CommitRequest, error) {
+	fields, err := e.stripFields(data, true)
+	if err != nil {
+		return nil, err
+	}
+	mask := []string{}
+	for _, p := range leafPaths(nil, data) {
+		mask = append(mask, encodeFieldPath(p))
+	}
+	sort.Strings(mask)
+	write := len(mask) > 0 || len(e.transforms) == 0
+	return e.request(docPath, write, fields, mask, nil)
+}
+
+// setMerge encodes a Set with a list of fields to merge. Only those fields
+// are written; a ServerTimestamp elsewhere is ignored, but a Delete elsewhere
+// is an error.
+func (e *encoder) setMerge(docPath string, data map[string]interface{}, fps []*tpb.FieldPath) (*fspb.CommitRequest, error) {
+	var paths [][]string
+	for _, fp := range fps {
+		paths = append(paths, fp.Field)
+	}
+	if err := checkPaths(paths); err != nil {
+		return nil, err
+	}
+	for _, p := range deletePaths(nil, data) {
+		if !containsPath(paths, p) {
+			return nil, fmt.Errorf("Delete at %s, which is not a merge field", encodeFieldPath(p))
+		}
+	}
+	fields := map[string]interface{}{}
+	mask := []string{}
+	for _, p := range paths {
+		v, ok := lookup(data, p)
+		if !ok {
+			return nil, fmt.Errorf("merge field %s is not in the data", encodeFieldPath(p))
+		}
+		if err := e.addField(fields, &mask, p, v); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(mask)
+	write := len(mask) > 0 || len(e.transforms) == 0
+	return e.request(docPath, write, fields, mask, nil)
+}
+
+// Update returns the request that DocumentRef.Update sends when given a map.
+// Its top-level keys are field paths separated by dots.
+func Update(t *tpb.UpdateTest) (*fspb.CommitRequest, error) {
+	data, err := parseData(t.JsonData)
+	if err != nil {
+		return nil, err
+	}
+	var paths [][]string
+	var values []interface{}
+	for k, v := range data {
+		if strings.ContainsAny(k, "~*/[]") {
+			return nil, fmt.Errorf("invalid character in field path %q", k)
+		}
+		paths = append(paths, strings.Split(k, "."))
+		values = append(values, v)
+	}
+	return update(t.DocRefPath, paths, values, t.Precondition)
+}
+
+// UpdatePaths returns the request that DocumentRef.Update sends when given
+// a list of field paths and values.
+func UpdatePaths(t *tpb.UpdatePathsTest) (*fspb.CommitRequest, error) {
+	if len(t.FieldPaths) != len(t.JsonValues) {
+		return nil, errors.New("mismatched field paths and values")
+	}
+	var paths [][]string
+	var values []interface{}
+	for i, fp := range t.FieldPaths {
+		v, err := parseJSON(t.JsonValues[i])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, fp.Field)
+		values = append(values, v)
+	}
+	return update(t.DocRefPath, paths, values, t.Precondition)
+}
+
+func update(docPath string, paths [][]string, values []interface{}, precond *fspb.Precondition) (*fspb.CommitRequest, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+	if err := checkPaths(paths); err != nil {
+		return nil, err
+	}
+	switch precond.GetConditionType().(type) {
+	case nil:
+		precond = &fspb.Precondition{ConditionType: &fspb.Precondition_Exists{Exists: true}}
+	case *fspb.Precondition_Exists:
+		return nil, errors.New("Update does not accept an exists precondition")
+	}
+	var e encoder
+	fields := map[string]interface{}{}
+	var mask []string
+	for i, p := range paths {
+		if err := e.addField(fields, &mask, p, values[i]); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(mask)
+	return e.request(docPath, len(mask) > 0, fields, mask, precond)
+}
+
+// Delete returns the request that DocumentRef.Delete sends.
+func Delete(t *tpb.DeleteTest) (*fspb.CommitRequest, error) {
+	return &fspb.CommitRequest{
+		Database: database(t.DocRefPath),
+		Writes: []*fspb.Write{{
+			Operation:       &fspb.Write_Delete{Delete: t.DocRefPath},
+			CurrentDocument: t.Precondition,
+		}},
+	}, nil
+}
+
+// Batch returns the request that WriteBatch.Commit sends after the test's
+// operations. It holds the writes of every operation, in order.
+func Batch(t *tpb.BatchTest) (*fspb.CommitRequest, error) {
+	if len(t.Ops) == 0 {
+		return nil, errors.New("empty batch")
+	}
+	req := &fspb.CommitRequest{}
+	for _, op := range t.Ops {
+		r, err := writeOp(op)
+		if err != nil {
+			return nil, err
+		}
+		req.Database = r.Database
+		req.Writes = append(req.Writes, r.Writes...)
+	}
+	return req, nil
+}
+
+// Transaction returns the requests that RunTransaction sends for the test's
+// transaction function, and the error that RunTransaction returns, if any.
+func Transaction(t *tpb.TransactionTest) ([]*tpb.TransactionRequest, error) {
+	if len(t.Ops) == 0 {
+		return nil, errors.New("empty transaction")
+	}
+	var db string
+	switch op := t.Ops[0].Op.(type) {
+	case *tpb.TransactionOp_Get:
+		db = database(op.Get)
+	case *tpb.TransactionOp_Write:
+		db = database(writeOpPath(op.Write))
+	}
+	reqs := []*tpb.TransactionRequest{{
+		Request: &tpb.TransactionRequest_BeginTransaction{
+			BeginTransaction: &fspb.BeginTransactionRequest{Database: db},
+		},
+	}}
+	var writes []*fspb.Write
+	var ferr error
+	for _, op := range t.Ops {
+		switch op := op.Op.(type) {
+		case *tpb.TransactionOp_Get:
+			if len(writes) > 0 {
+				ferr = errors.New("read after write in transaction")
+				break
+			}
+			reqs = append(reqs, &tpb.TransactionRequest{
+				Request: &tpb.TransactionRequest_BatchGetDocuments{BatchGetDocuments: &fspb.BatchGetDocumentsRequest{
+					Database:            db,
+					Documents:           []string{op.Get},
+					ConsistencySelector: &fspb.BatchGetDocumentsRequest_Transaction{Transaction: t.Transaction},
+				}},
+			})
+		case *tpb.TransactionOp_Write:
+			r, err := writeOp(op.Write)
+			if err != nil {
+				ferr = err
+				break
+			}
+			writes = append(writes, r.Writes...)
+		}
+		if ferr != nil {
+			break
+		}
+	}
+	if ferr == nil && t.FunctionError {
+		ferr = errors.New("transaction function failed")
+	}
+	if ferr != nil {
+		return append(reqs, &tpb.TransactionRequest{
+			Request: &tpb.TransactionRequest_Rollback{
+				Rollback: &fspb.RollbackRequest{Database: db, Transaction: t.Transaction},
+			},
+		}), ferr
+	}
+	return append(reqs, &tpb.TransactionRequest{
+		Request: &tpb.TransactionRequest_Commit{
+			Commit: &fspb.CommitRequest{Database: db, Writes: writes, Transaction: t.Transaction},
+		},
+	}), nil
+}
+
+// writeOp returns the request that a single write operation would send on
+// its own.
+func writeOp(op *tpb.WriteOp) (*fspb.CommitRequest, error) {
+	switch op := op.Op.(type) {
+	case *tpb.WriteOp_Create:
+		return Create(op.Create)
+	case *tpb.WriteOp_Set:
+		return Set(op.Set)
+	case *tpb.WriteOp_Update:
+		return Update(op.Update)
+	case *tpb.WriteOp_UpdatePaths:
+		return UpdatePaths(op.UpdatePaths)
+	case *tpb.WriteOp_Delete:
+		return Delete(op.Delete)
+	default:
+		return nil, fmt.Errorf("unknown write op %T", op)
+	}
+}
+
+// writeOpPath returns the path of the document that a write operation writes.
+func writeOpPath(op *tpb.WriteOp) string {
+	switch op := op.Op.(type) {
+	case *tpb.WriteOp_Create:
+		return op.Create.DocRefPath
+	case *tpb.WriteOp_Set:
+		return op.Set.DocRefPath
+	case *tpb.WriteOp_Update:
+		return op.Update.DocRefPath
+	case *tpb.WriteOp_UpdatePaths:
+		return op.UpdatePaths.DocRefPath
+	case *tpb.WriteOp_Delete:
+		return op.Delete.DocRefPath
+	default:
+		return ""
+	}
+}
+
+// An encoder collects the field transforms of a write.
+type encoder struct {
+	transforms []*fspb.DocumentTransform_FieldTransform
+}
+
+// addField adds the value v at path p to a write that replaces the fields in
+// mask with those in fields. A Delete sentinel is allowed only as v itself.
+func (e *encoder) addField(fields map[string]interface{}, mask *[]string, p []string, v interface{}) error {
+	if isDelete(v) {
+		*mask = append(*mask, encodeFieldPath(p))
+		return nil
+	}
+	sv, ok, err := e.strip(p, v, false)
+	if err != nil {
+		return err
+	}
+	if isTransform(v) {
+		return nil
+	}
+	*mask = append(*mask, encodeFieldPath(p))
+	if ok {
+		set(fields, p, sv)
+	}
+	return nil
+}
+
+// strip returns v, the value at path p, without its sentinels, recording a
+// field transform for each transform sentinel. It reports false if nothing is
+// left: if v is a sentinel, or a map that is left empty by removing them.
+// Delete sentinels are removed if allowDelete is true, and are an error
+// otherwise. Sentinels in arrays are always an error.
+func (e *encoder) strip(p []string, v interface{}, allowDelete bool) (interface{}, bool, error) {
+	switch {
+	case isDelete(v) && allowDelete:
+		return nil, false, nil
+	case isDelete(v):
+		return nil, false, fmt.Errorf("Delete cannot appear at %s", encodeFieldPath(p))
+	case isTransform(v):
+		ft, err := fieldTransform(p, v)
+		if err != nil {
+			return nil, false, err
+		}
+		e.transforms = append(e.transforms, ft)
+		return nil, false, nil
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, x := range v {
+			sx, ok, err := e.strip(appendPath(p, k), x, allowDelete)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				m[k] = sx
+			}
+		}
+		return m, len(m) > 0 || len(v) == 0, nil
+	case []interface{}:
+		if err := checkArray(v); err != nil {
+			return nil, false, err
+		}
+	}
+	return v, true, nil
+}
+
+// stripFields is strip for a document's data.
+func (e *encoder) stripFields(data map[string]interface{}, allowDelete bool) (map[string]interface{}, error) {
+	v, _, err := e.strip(nil, data, allowDelete)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+// request assembles the CommitRequest for a write to docPath: an update
+// write that also applies the field transforms, if there are any. If write is
+// false, the write only applies the transforms, with an empty mask so that no
+// field is overwritten.
+func (e *encoder) request(docPath string, write bool, fields map[string]interface{}, mask []string, precond *fspb.Precondition) (*fspb.CommitRequest, error) {
+	req := &fspb.CommitRequest{Database: database(docPath)}
+	if !write && len(e.transforms) == 0 {
+		return req, nil
+	}
+	sort.Slice(e.transforms, func(i, j int) bool {
+		return e.transforms[i].FieldPath < e.transforms[j].FieldPath
+	})
+	doc := &fspb.Document{Name: docPath}
+	w := &fspb.Write{
+		Operation:        &fspb.Write_Update{Update: doc},
+		CurrentDocument:  precond,
+		UpdateTransforms: e.transforms,
+	}
+	if write {
+		v, err := toValue(fields)
+		if err != nil {
+			return nil, err
+		}
+		doc.Fields = v.GetMapValue().Fields
+		if mask != nil {
+			w.UpdateMask = &fspb.DocumentMask{FieldPaths: mask}
+		}
+	} else {
+		w.UpdateMask = &fspb.DocumentMask{}
+	}
+	req.Writes = append(req.Writes, w)
+	return req, nil
+}
+
+func isDelete(v interface{}) bool {
+	return v == "Delete"
+}
+
+// isTransform reports whether v is a sentinel that becomes a field transform:
+// the string "ServerTimestamp", or an array whose first element is the name
+// of an array transform or "Increment".
+func isTransform(v interface{}) bool {
+	if a, ok := v.([]interface{}); ok && len(a) > 0 {
+		return a[0] == "ArrayUnion" || a[0] == "ArrayRemove" || a[0] == "Increment"
+	}
+	return v == "ServerTimestamp"
+}
+
+// fieldTransform returns the field transform at path p for the sentinel v.
+func fieldTransform(p []string, v interface{}) (*fspb.DocumentTransform_FieldTransform, error) {
+	ft := &fspb.DocumentTransform_FieldTransform{FieldPath: encodeFieldPath(p)}
+	if v == "ServerTimestamp" {
+		ft.TransformType = &fspb.DocumentTransform_FieldTransform_SetToServerValue{
+			SetToServerValue: fspb.DocumentTransform_FieldTransform_REQUEST_TIME,
+		}
+		return ft, nil
+	}
+	a := v.([]interface{})
+	if a[0] == "Increment" {
+		if len(a) != 2 {
+			return nil, fmt.Errorf("Increment takes one operand, got %d", len(a)-1)
+		}
+		if _, ok := a[1].(json.Number); !ok {
+			return nil, fmt.Errorf("Increment operand %v is not a number", a[1])
+		}
+		n, err := toValue(a[1])
+		if err != nil {
+			return nil, err
+		}
+		ft.TransformType = &fspb.DocumentTransform_FieldTransform_Increment{Increment: n}
+		return ft, nil
+	}
+	if err := checkArray(a[1:]); err != nil {
+		return nil, err
+	}
+	av, err := toValue(a[1:])
+	if err != nil {
+		return nil, err
+	}
+	elems := av.GetArrayValue()
+	switch a[0] {
+	case "ArrayUnion":
+		ft.TransformType = &fspb.DocumentTransform_FieldTransform_AppendMissingElements{AppendMissingElements: elems}
+	case "ArrayRemove":
+		ft.TransformType = &fspb.DocumentTransform_FieldTransform_RemoveAllFromArray{RemoveAllFromArray: elems}
+	}
+	return ft, nil
+}
+
+// checkArray reports an error if a sentinel appears anywhere in an array.
+func checkArray(vs []interface{}) error {
+	for _, v := range vs {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, x := range v {
+				if err := checkArray([]interface{}{x}); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			if isTransform(v) {
+				return fmt.Errorf("%v cannot appear in an array", v[0])
+			}
+			if err := checkArray(v); err != nil {
+				return err
+			}
+		default:
+			if isDelete(v) || isTransform(v) {
+				return fmt.Errorf("%v cannot appear in an array", v)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPaths reports an error if a path is empty or has an empty component,
+// or if one path is a prefix of another.
+func checkPaths(paths [][]string) error {
+	for _, p := range paths {
+		if len(p) == 0 {
+			return errors.New("empty field path")
+		}
+		for _, c := range p {
+			if c == "" {
+				return fmt.Errorf("empty component in field path %q", p)
+			}
+		}
+	}
+	for i, p := range paths {
+		for j, q := range paths {
+			if i != j && isPrefix(p, q) {
+				return fmt.Errorf("field path %s is a prefix of %s", encodeFieldPath(p), encodeFieldPath(q))
+			}
+		}
+	}
+	return nil
+}
+
+func isPrefix(p, q []string) bool {
+	if len(p) > len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPath(paths [][]string, p []string) bool {
+	for _, q := range paths {
+		if len(q) == len(p) && isPrefix(q, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// leafPaths returns the paths of the leaf values in m, not counting
+// transform sentinels. An empty map is a leaf.
+func leafPaths(p []string, m map[string]interface{}) [][]string {
+	var paths [][]string
+	for k, v := range m {
+		kp := appendPath(p, k)
+		if vm, ok := v.(map[string]interface{}); ok && len(vm) > 0 {
+			paths = append(paths, leafPaths(kp, vm)...)
+		} else if !isTransform(v) {
+			paths = append(paths, kp)
+		}
+	}
+	return paths
+}
+
+// deletePaths returns the paths of the Delete sentinels in m, outside arrays.
+func deletePaths(p []string, m map[string]interface{}) [][]string {
+	var paths [][]string
+	for k, v := range m {
+		kp := appendPath(p, k)
+		if vm, ok := v.(map[string]interface{}); ok {
+			paths = append(paths, deletePaths(kp, vm)...)
+		} else if isDelete(v) {
+			paths = append(paths, kp)
+		}
+	}
+	return paths
+}
+
+// lookup returns the value at path p in m.
+func lookup(m map[string]interface{}, p []string) (interface{}, bool) {
+	var v interface{} = m
+	for _, k := range p {
+		vm, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = vm[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// set stores v at path p in m, creating intermediate maps as needed.
+func set(m map[string]interface{}, p []string, v interface{}) {
+	for _, k := range p[:len(p)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[p[len(p)-1]] = v
+}
+
+func appendPath(p []string, k string) []string {
+	return append(p[:len(p):len(p)], k)
+}
+
+var simpleFieldName = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+
+// encodeFieldPath returns the string form of a field path. Components that are
+// not simple names are quoted with backquotes.
+func encodeFieldPath(p []string) string {
+	var parts []string
+	for _, c := range p {
+		if !simpleFieldName.MatchString(c) {
+			c = strings.Replace(c, `\`, `\\`, -1)
+			c = "`" + strings.Replace(c, "`", "\\`", -1) + "`"
+		}
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, ".")
+}
+
+// database returns the database of the document at docPath.
+func database(docPath string) string {
+	if i := strings.Index(docPath, "/documents/"); i >= 0 {
+		return docPath[:i]
+	}
+	return docPath
+}
+
+// FieldPath returns the components of the field path that t.Path denotes,
+// and its encoding in requests.
+func FieldPath(t *tpb.FieldPathTest) ([]string, string, error) {
+	p, err := parseFieldPath(t.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, encodeFieldPath(p), nil
+}
+
+// parseFieldPath parses a field path written as a string, with components
+// separated by dots and optionally quoted with backquotes.
+func parseFieldPath(s string) ([]string, error) {
+	var p []string
+	for rest := s; ; {
+		var c string
+		if strings.HasPrefix(rest, "`") {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '`'; i++ {
+				if rest[i] == '\\' {
+					i++
+					if i == len(rest) {
+						break
+					}
+				}
+				b.WriteByte(rest[i])
+			}
+			if i >= len(rest) {
+				return nil, fmt.Errorf("unterminated quote in field path %q", s)
+			}
+			c, rest = b.String(), rest[i+1:]
+		} else {
+			i := strings.IndexByte(rest, '.')
+			if i < 0 {
+				i = len(rest)
+			}
+			c, rest = rest[:i], rest[i:]
+			if strings.ContainsAny(c, "~*/[]`") {
+				return nil, fmt.Errorf("invalid character in field path %q", s)
+			}
+		}
+		if c == "" {
+			return nil, fmt.Errorf("empty component in field path %q", s)
+		}
+		p = append(p, c)
+		if rest == "" {
+			return p, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("quoted component not followed by a dot in field path %q", s)
+		}
+		rest = rest[1:]
+	}
+}
+
+// ParseValue parses s, JSON in the encoding of the tests, as a Firestore value.
+// Sentinels are not interpreted: "Delete" is just a string.
+func ParseValue(s string) (*fspb.Value, error) {
+	v, err := parseJSON(s)
+	if err != nil {
+		return nil, err
+	}
+	return toValue(v)
+}
+
+// parseData parses the JSON for a document's data, which must be an object.
+func parseData(s string) (map[string]interface{}, error) {
+	v, err := parseJSON(s)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("data %s is not a JSON object", s)
+	}
+	return m, nil
+}
+
+// parseJSON parses s, keeping numbers as json.Numbers so that integers and
+// doubles can be told apart. Objects of the extended encoding become the
+// *fspb.Values they denote.
+func parseJSON(s string) (interface{}, error) {
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", s, err)
+	}
+	v, err := decodeExtended(v)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", s, err)
+	}
+	return v, nil
+}
+
+// decodeExtended replaces each object in v that has a single key starting
+// with "$" by the value it denotes.
+func decodeExtended(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for k, x := range v {
+				if strings.HasPrefix(k, "$") {
+					return extendedValue(k, x)
+				}
+			}
+		}
+		for k, x := range v {
+			dx, err := decodeExtended(x)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = dx
+		}
+	case []interface{}:
+		for i, x := range v {
+			dx, err := decodeExtended(x)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = dx
+		}
+	}
+	return v, nil
+}
+
+// extendedValue returns the value denoted by the object {key: x}.
+func extendedValue(key string, x interface{}) (*fspb.Value, error) {
+	if key == "$geopoint" {
+		m, ok := x.(map[string]interface{})
+		lat, latOK := m["latitude"].(json.Number)
+		lng, lngOK := m["longitude"].(json.Number)
+		if !ok || len(m) != 2 || !latOK || !lngOK {
+			return nil, fmt.Errorf("bad $geopoint %v", x)
+		}
+		latf, latErr := lat.Float64()
+		lngf, lngErr := lng.Float64()
+		if latErr != nil || lngErr != nil {
+			return nil, fmt.Errorf("bad $geopoint %v", x)
+		}
+		ll := &latlng.LatLng{Latitude: latf, Longitude: lngf}
+		return &fspb.Value{ValueType: &fspb.Value_GeoPointValue{GeoPointValue: ll}}, nil
+	}
+	s, ok := x.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s takes a string, got %v", key, x)
+	}
+	switch key {
+	case "$timestamp":
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		ts := &tspb.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+		return &fspb.Value{ValueType: &fspb.Value_TimestampValue{TimestampValue: ts}}, nil
+	case "$bytes":
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return &fspb.Value{ValueType: &fspb.Value_BytesValue{BytesValue: b}}, nil
+	case "$reference":
+		return &fspb.Value{ValueType: &fspb.Value_ReferenceValue{ReferenceValue: s}}, nil
+	case "$double":
+		var f float64
+		switch s {
+		case "NaN":
+			f = math.NaN()
+		case "Infinity":
+			f = math.Inf(1)
+		case "-Infinity":
+			f = math.Inf(-1)
+		default:
+			return nil, fmt.Errorf("bad $double %q", s)
+		}
+		return &fspb.Value{ValueType: &fspb.Value_DoubleValue{DoubleValue: f}}, nil
+	default:
+		return nil, fmt.Errorf("unknown extended JSON type %s", key)
+	}
+}
+
+// toValue converts a value produced by parseJSON to a Firestore value. A
+// number is an integer unless it has a fraction or an exponent, and it is an
+// error for a number not to fit in its type.
+func toValue(v interface{}) (*fspb.Value, error) {
+	switch v := v.(type) {
+	case *fspb.Value:
+		return v, nil
+	case nil:
+		return &fspb.Value{ValueType: &fspb.Value_NullValue{}}, nil
+	case bool:
+		return &fspb.Value{ValueType: &fspb.Value_BooleanValue{BooleanValue: v}}, nil
+	case string:
+		return &fspb.Value{ValueType: &fspb.Value_StringValue{StringValue: v}}, nil
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			i, err := v.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("integer %s out of range", v)
+			}
+			return &fspb.Value{ValueType: &fspb.Value_IntegerValue{IntegerValue: i}}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("double %s out of range", v)
+		}
+		return &fspb.Value{ValueType: &fspb.Value_DoubleValue{DoubleValue: f}}, nil
+	case []interface{}:
+		var vs []*fspb.Value
+		for _, x := range v {
+			xv, err := toValue(x)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, xv)
+		}
+		return &fspb.Value{ValueType: &fspb.Value_ArrayValue{ArrayValue: &fspb.ArrayValue{Values: vs}}}, nil
+	case map[string]interface{}:
+		fields := map[string]*fspb.Value{}
+		for k, x := range v {
+			xv, err := toValue(x)
+			if err != nil {
+				return nil, err
+			}
+			fields[k] = xv
+		}
+		return &fspb.Value{ValueType: &fspb.Value_MapValue{MapValue: &fspb.MapValue{Fields: fields}}}, nil
+	default:
+		return nil, fmt.Errorf("unknown JSON value %T", v)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writes
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	fspb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	tpb "github.com/GoogleCloudPlatform/google-cloud-common/testing/firestore/genproto/v1"
+	"github.com/golang/protobuf/proto"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+const docPath = "projects/projectID/databases/(default)/documents/C/d"
+
+func TestFieldPath(t *testing.T) {
+	for _, test := range []struct {
+		path    string
+		want    []string
+		encoded string
+	}{
+		{"a", []string{"a"}, "a"},
+		{"a.b_1", []string{"a", "b_1"}, "a.b_1"},
+		{"`a.b`.c", []string{"a.b", "c"}, "`a.b`.c"},
+		{"a b.1", []string{"a b", "1"}, "`a b`.`1`"},
+		{"`a\\`b`", []string{"a`b"}, "`a\\`b`"},
+		{"`a\\\\b`", []string{`a\b`}, "`a\\\\b`"},
+		{"`*`", []string{"*"}, "`*`"},
+	} {
+		got, encoded, err := FieldPath(&tpb.FieldPathTest{Path: test.path})
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) || encoded != test.encoded {
+			t.Errorf("%s: got %q and %s, want %q and %s", test.path, got, encoded, test.want, test.encoded)
+		}
+		// The encoding parses back to the same components.
+		if back, err := parseFieldPath(encoded); err != nil || !reflect.DeepEqual(back, got) {
+			t.Errorf("%s: encoding %s parses to %q, %v", test.path, encoded, back, err)
+		}
+	}
+	for _, path := range []string{"", "a..b", "a.", ".a", "`a", "`a`b", "`a`.", "a~b", "a*", "a/b", "a[0]", "a`b"} {
+		if _, _, err := FieldPath(&tpb.FieldPathTest{Path: path}); err == nil {
+			t.Errorf("%q: got nil, want error", path)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	integer := func(i int64) *fspb.Value { return &fspb.Value{ValueType: &fspb.Value_IntegerValue{IntegerValue: i}} }
+	double := func(f float64) *fspb.Value { return &fspb.Value{ValueType: &fspb.Value_DoubleValue{DoubleValue: f}} }
+	str := func(s string) *fspb.Value { return &fspb.Value{ValueType: &fspb.Value_StringValue{StringValue: s}} }
+	mapValue := func(fields map[string]*fspb.Value) *fspb.Value {
+		return &fspb.Value{ValueType: &fspb.Value_MapValue{MapValue: &fspb.MapValue{Fields: fields}}}
+	}
+	for _, test := range []struct {
+		json string
+		want *fspb.Value
+	}{
+		{`null`, &fspb.Value{ValueType: &fspb.Value_NullValue{}}},
+		{`true`, &fspb.Value{ValueType: &fspb.Value_BooleanValue{BooleanValue: true}}},
+		{`"Delete"`, str("Delete")}, // sentinels are not interpreted
+		{`1`, integer(1)},
+		{`-9223372036854775808`, integer(math.MinInt64)},
+		{`1.0`, double(1)},
+		{`1e3`, double(1000)},
+		{`1E-1`, double(0.1)},
+		{`{"$timestamp": "2016-01-02T03:04:05.123456789Z"}`, &fspb.Value{ValueType: &fspb.Value_TimestampValue{
+			TimestampValue: &tspb.Timestamp{Seconds: 1451703845, Nanos: 123456789},
+		}}},
+		{`{"$timestamp": "2016-01-02T04:04:05+01:00"}`, &fspb.Value{ValueType: &fspb.Value_TimestampValue{
+			TimestampValue: &tspb.Timestamp{Seconds: 1451703845},
+		}}},
+		{`{"$bytes": "AQID"}`, &fspb.Value{ValueType: &fspb.Value_BytesValue{BytesValue: []byte{1, 2, 3}}}},
+		{`{"$geopoint": {"latitude": 1.5, "longitude": -2}}`, &fspb.Value{ValueType: &fspb.Value_GeoPointValue{
+			GeoPointValue: &latlng.LatLng{Latitude: 1.5, Longitude: -2},
+		}}},
+		{`{"$reference": "` + docPath + `"}`, &fspb.Value{ValueType: &fspb.Value_ReferenceValue{ReferenceValue: docPath}}},
+		{`{"$double": "Infinity"}`, double(math.Inf(1))},
+		{`{"$double": "-Infinity"}`, double(math.Inf(-1))},
+		// Only an object with a single key is extended.
+		{`{"$bytes": "AQID", "a": 1}`, mapValue(map[string]*fspb.Value{"$bytes": str("AQID"), "a": integer(1)})},
+		{`{"a": {"$bytes": ""}}`, mapValue(map[string]*fspb.Value{
+			"a": {ValueType: &fspb.Value_BytesValue{BytesValue: []byte{}}},
+		})},
+		{`[1, {"$double": "Infinity"}]`, &fspb.Value{ValueType: &fspb.Value_ArrayValue{ArrayValue: &fspb.ArrayValue{
+			Values: []*fspb.Value{integer(1), double(math.Inf(1))},
+		}}}},
+	} {
+		got, err := ParseValue(test.json)
+		if err != nil {
+			t.Errorf("%s: %v", test.json, err)
+			continue
+		}
+		if !proto.Equal(got, test.want) {
+			t.Errorf("%s: got %s, want %s", test.json, got, test.want)
+		}
+	}
+
+	got, err := ParseValue(`{"$double": "NaN"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := got.ValueType.(*fspb.Value_DoubleValue); !ok || !math.IsNaN(d.DoubleValue) {
+		t.Errorf("$double NaN: got %s, want NaN", got)
+	}
+
+	for _, json := range []string{
+		`9223372036854775808`,
+		`1e400`,
+		`{"$timestamp": "2016-01-02"}`,
+		`{"$timestamp": "2016-01-02T03:04:05"}`,
+		`{"$timestamp": 1}`,
+		`{"$bytes": "AQI"}`,
+		`{"$bytes": "A-_B"}`,
+		`{"$geopoint": {"latitude": 1}}`,
+		`{"$geopoint": {"latitude": 1, "longitude": "2"}}`,
+		`{"$geopoint": {"latitude": 1, "longitude": 1e400}}`,
+		`{"$double": "nan"}`,
+		`{"$double": "1.5"}`,
+		`{"$set": "x"}`,
+		`[{"$bytes": "!"}]`,
+		`{"a": `,
+	} {
+		if got, err := ParseValue(json); err == nil {
+			t.Errorf("%s: got %s, want error", json, got)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	serverTime := &fspb.DocumentTransform_FieldTransform{
+		FieldPath: "b",
+		TransformType: &fspb.DocumentTransform_FieldTransform_SetToServerValue{
+			SetToServerValue: fspb.DocumentTransform_FieldTransform_REQUEST_TIME,
+		},
+	}
+	integer := func(i int64) *fspb.Value { return &fspb.Value{ValueType: &fspb.Value_IntegerValue{IntegerValue: i}} }
+	database := "projects/projectID/databases/(default)"
+
+	// Sentinels become transforms, ordered by field path, in the same write as
+	// the remaining fields.
+	got, err := Set(&tpb.SetTest{
+		DocRefPath: docPath,
+		JsonData:   `{"d": {"e": ["Increment", 2], "f": 3}, "c": ["ArrayUnion", 1], "b": "ServerTimestamp", "a": 1}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &fspb.CommitRequest{
+		Database: database,
+		Writes: []*fspb.Write{{
+			Operation: &fspb.Write_Update{Update: &fspb.Document{
+				Name: docPath,
+				Fields: map[string]*fspb.Value{
+					"a": integer(1),
+					"d": {ValueType: &fspb.Value_MapValue{MapValue: &fspb.MapValue{
+						Fields: map[string]*fspb.Value{"f": integer(3)},
+					}}},
+				},
+			}},
+			UpdateTransforms: []*fspb.DocumentTransform_FieldTransform{
+				serverTime,
+				{
+					FieldPath: "c",
+					TransformType: &fspb.DocumentTransform_FieldTransform_AppendMissingElements{
+						AppendMissingElements: &fspb.ArrayValue{Values: []*fspb.Value{integer(1)}},
+					},
+				},
+				{
+					FieldPath:     "d.e",
+					TransformType: &fspb.DocumentTransform_FieldTransform_Increment{Increment: integer(2)},
+				},
+			},
+		}},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("Set:\ngot  %s\nwant %s", got, want)
+	}
+
+	// A Create of only transforms applies them to an empty mask.
+	got, err = Create(&tpb.CreateTest{DocRefPath: docPath, JsonData: `{"b": "ServerTimestamp"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &fspb.CommitRequest{
+		Database: database,
+		Writes: []*fspb.Write{{
+			Operation:        &fspb.Write_Update{Update: &fspb.Document{Name: docPath}},
+			UpdateMask:       &fspb.DocumentMask{},
+			UpdateTransforms: []*fspb.DocumentTransform_FieldTransform{serverTime},
+			CurrentDocument:  &fspb.Precondition{ConditionType: &fspb.Precondition_Exists{Exists: false}},
+		}},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("Create:\ngot  %s\nwant %s", got, want)
+	}
+
+	for _, data := range []string{
+		`{"a": [1, "ServerTimestamp"]}`,
+		`{"a": [["ArrayRemove", 1]]}`,
+		`{"a": ["ArrayUnion", "ServerTimestamp"]}`,
+		`{"a": ["ArrayUnion", {"b": ["Increment", 1]}]}`,
+		`{"a": ["Increment", "1"]}`,
+		`{"a": ["Increment", 1, 2]}`,
+		`{"a": "Delete"}`,
+	} {
+		if got, err := Set(&tpb.SetTest{DocRefPath: docPath, JsonData: data}); err == nil {
+			t.Errorf("%s: got %s, want error", data, got)
+		}
+	}
+}